@@ -0,0 +1,200 @@
+package chordpro
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"backing-tracks/display"
+	"backing-tracks/parser"
+)
+
+// barsPerLine is how many bars go on one line when there are no lyric lines to follow
+const barsPerLine = 4
+
+// chordAt is a chord to insert into a lyric line at a rune position
+type chordAt struct {
+	symbol string
+	pos    int
+}
+
+// Generate converts a BTML track to a ChordPro song sheet: title/key/tempo
+// directives, a comment per section, and lyric lines with inline [Chord]
+// markers at each chord change (bare chord lines where there are no lyrics)
+func Generate(track *parser.Track) string {
+	var sb strings.Builder
+
+	timeSig := track.GetTimeSignature()
+	sb.WriteString(fmt.Sprintf("{title: %s}\n", track.Info.Title))
+	if track.Info.Key != "" {
+		sb.WriteString(fmt.Sprintf("{key: %s}\n", track.Info.Key))
+	}
+	if track.Info.Tempo > 0 {
+		sb.WriteString(fmt.Sprintf("{tempo: %d}\n", track.Info.Tempo))
+	}
+	sb.WriteString(fmt.Sprintf("{time: %s}\n", timeSig))
+	if track.Info.Capo > 0 {
+		sb.WriteString(fmt.Sprintf("{capo: %d}\n", track.Info.Capo))
+	}
+	sb.WriteString("\n")
+
+	bars := display.ProcessChordsIntoBars(track)
+	beatsPerBar := timeSig.Beats
+
+	sections := track.Progression.GetSections()
+	if len(sections) == 0 {
+		sections = []parser.SectionInfo{{StartBar: 0, EndBar: len(bars)}}
+	}
+	lyricLines := sectionLyricLines(track, sections)
+
+	for _, section := range sections {
+		if section.Name != "" {
+			sb.WriteString(fmt.Sprintf("{comment: %s}\n", section.Name))
+		}
+		end := min(section.EndBar, len(bars))
+
+		for bar := section.StartBar; bar < end; {
+			// Section lyrics: one ChordPro line per lyric line
+			if line, ok := lyricLines[bar]; ok && line.EndBar > bar {
+				lineEnd := min(line.EndBar, end)
+				sb.WriteString(lyricLineWithChords(line, bars[bar:lineEnd], beatsPerBar) + "\n")
+				bar = lineEnd
+				continue
+			}
+
+			// Per-bar lyrics (track.Lyrics) or a bare chord line
+			lineEnd := min(bar+barsPerLine, end)
+			if next := nextLyricLine(lyricLines, bar, lineEnd); next < lineEnd {
+				lineEnd = next
+			}
+			sb.WriteString(barsLine(bars[bar:lineEnd], beatsPerBar) + "\n")
+			bar = lineEnd
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// sectionLyricLines indexes the section lyric lines by their first bar
+func sectionLyricLines(track *parser.Track, sections []parser.SectionInfo) map[int]*parser.LyricLine {
+	lines := make(map[int]*parser.LyricLine)
+	for _, block := range parser.BuildLyricsBlocks(track.Sections, sections) {
+		for i := range block.Lines {
+			line := &block.Lines[i]
+			if strings.TrimSpace(line.Text) != "" {
+				lines[line.StartBar] = line
+			}
+		}
+	}
+	return lines
+}
+
+// nextLyricLine returns the first bar in [from+1, to) where a section lyric line starts, or to
+func nextLyricLine(lines map[int]*parser.LyricLine, from, to int) int {
+	for bar := from + 1; bar < to; bar++ {
+		if _, ok := lines[bar]; ok {
+			return bar
+		}
+	}
+	return to
+}
+
+// lyricLineWithChords places chords into a section lyric line: at the line's
+// own chord marks if it has them, otherwise spread by beat position over the text
+func lyricLineWithChords(line *parser.LyricLine, bars []display.Bar, beatsPerBar int) string {
+	var chords []chordAt
+	if len(line.ChordMarks) > 0 {
+		for _, mark := range line.ChordMarks {
+			chords = append(chords, chordAt{mark.Chord, mark.Position})
+		}
+		return insertChords(line.Text, chords, false)
+	}
+
+	textLen := len([]rune(line.Text))
+	totalBeats := len(bars) * beatsPerBar
+	for i, bar := range bars {
+		for _, chord := range bar.Chords {
+			beat := i*beatsPerBar + chord.StartBeat
+			chords = append(chords, chordAt{chord.Symbol, beat * textLen / totalBeats})
+		}
+	}
+	return insertChords(line.Text, chords, true)
+}
+
+// barsLine writes bars with per-bar lyrics, or a bare chord line if none of them have lyrics
+func barsLine(bars []display.Bar, beatsPerBar int) string {
+	hasLyrics := false
+	for _, bar := range bars {
+		if strings.TrimSpace(bar.Lyrics) != "" {
+			hasLyrics = true
+		}
+	}
+
+	parts := make([]string, len(bars))
+	for i, bar := range bars {
+		text := strings.TrimSpace(bar.Lyrics)
+		if text == "" {
+			// Chords only, e.g. "[A7]" or "[D7] [G]"
+			symbols := make([]string, len(bar.Chords))
+			for j, chord := range bar.Chords {
+				symbols[j] = "[" + chord.Symbol + "]"
+			}
+			parts[i] = strings.Join(symbols, " ")
+			continue
+		}
+
+		var chords []chordAt
+		textLen := len([]rune(text))
+		for _, chord := range bar.Chords {
+			chords = append(chords, chordAt{chord.Symbol, chord.StartBeat * textLen / beatsPerBar})
+		}
+		parts[i] = insertChords(text, chords, true)
+	}
+
+	if hasLyrics {
+		return strings.Join(parts, " ")
+	}
+	return strings.Join(parts, " | ")
+}
+
+// insertChords inserts [Chord] markers into text at rune positions. With snap,
+// each marker moves to the start of the nearest word so it sits before a syllable.
+func insertChords(text string, chords []chordAt, snap bool) string {
+	runes := []rune(text)
+	if snap {
+		for i := range chords {
+			chords[i].pos = nearestWordStart(runes, chords[i].pos)
+		}
+	}
+	sort.SliceStable(chords, func(i, j int) bool { return chords[i].pos < chords[j].pos })
+
+	var sb strings.Builder
+	next := 0
+	for _, chord := range chords {
+		pos := min(max(chord.pos, 0), len(runes))
+		sb.WriteString(string(runes[next:pos]))
+		sb.WriteString("[" + chord.symbol + "]")
+		next = pos
+	}
+	sb.WriteString(string(runes[next:]))
+	return sb.String()
+}
+
+// nearestWordStart returns the word start in runes closest to pos
+func nearestWordStart(runes []rune, pos int) int {
+	best, bestDist := 0, -1
+	for i := range runes {
+		if runes[i] == ' ' || (i > 0 && runes[i-1] != ' ') {
+			continue
+		}
+		dist := i - pos
+		if dist < 0 {
+			dist = -dist
+		}
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return best
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"backing-tracks/display"
+	"backing-tracks/parser"
+	"backing-tracks/player"
+	"backing-tracks/theory"
+)
+
+// userConfig holds defaults for options that would otherwise be passed on every
+// run. Flags win over environment variables, which win over the config file.
+type userConfig struct {
+	SoundFont   string `json:"soundfont"`    // Like --soundfont; "~/" is the home directory
+	AudioDriver string `json:"audio_driver"` // Like --audio-driver
+	Theme       string `json:"theme"`        // Like --theme
+	Tuning      string `json:"tuning"`       // Like --tuning (a name or notes low string first)
+}
+
+// configPath returns the config file location, ~/.config/backing-tracks/config.json
+// on Linux ("" when there is no config directory)
+func configPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "backing-tracks", "config.json")
+}
+
+// loadConfig reads and checks the config file. A missing file is an empty config;
+// a malformed file or unknown value is an error, like a bad flag.
+func loadConfig() userConfig {
+	var config userConfig
+	path := configPath()
+	if path == "" {
+		return config
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config
+	}
+	if err != nil {
+		fmt.Printf("Error: reading config: %v\n", err)
+		os.Exit(1)
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		fmt.Printf("Error: %s: %v\n", path, err)
+		os.Exit(1)
+	}
+
+	if config.AudioDriver != "" {
+		config.AudioDriver = strings.ToLower(strings.TrimSpace(config.AudioDriver))
+		if !player.IsAudioDriver(config.AudioDriver) {
+			fmt.Printf("Error: %s: unknown audio_driver %q (use %s)\n", path, config.AudioDriver, strings.Join(player.AudioDrivers, ", "))
+			os.Exit(1)
+		}
+	}
+	if config.Theme != "" {
+		config.Theme = strings.ToLower(strings.TrimSpace(config.Theme))
+		if !slices.Contains(display.ThemeNames(), config.Theme) {
+			fmt.Printf("Error: %s: unknown theme %q (use %s)\n", path, config.Theme, strings.Join(display.ThemeNames(), ", "))
+			os.Exit(1)
+		}
+	}
+	if config.Tuning != "" && isCustomTuning(config.Tuning) {
+		if _, err := parser.ParseTuning(config.Tuning); err != nil {
+			fmt.Printf("Error: %s: %v\n", path, err)
+			os.Exit(1)
+		}
+	} else if config.Tuning != "" {
+		config.Tuning = strings.ToLower(strings.TrimSpace(config.Tuning))
+		if _, ok := theory.Tunings[config.Tuning]; !ok {
+			fmt.Printf("Error: %s: unknown tuning %q (use %s)\n", path, config.Tuning, strings.Join(theory.TuningNames, ", "))
+			os.Exit(1)
+		}
+	}
+	if rest, ok := strings.CutPrefix(config.SoundFont, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			config.SoundFont = filepath.Join(home, rest)
+		}
+	}
+	return config
+}
+
+// configDisplayPath returns configPath with the home directory shown as "~"
+func configDisplayPath() string {
+	path := configPath()
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		if rest, ok := strings.CutPrefix(path, home+string(filepath.Separator)); ok {
+			return filepath.Join("~", rest)
+		}
+	}
+	return path
+}
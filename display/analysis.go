@@ -0,0 +1,124 @@
+package display
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"backing-tracks/parser"
+	"backing-tracks/theory"
+)
+
+// RenderAnalysis renders a harmonic analysis of the progression as plain text: how
+// well the chords fit the track's key (the share of bars on diatonic chords and on
+// chords the key explains, whether the song starts or ends on the tonic, and a
+// better-fitting key when there is one), then each chord in order of first
+// appearance with its numeral, what it does in the key and how many bars it gets,
+// and a summary of the chords outside the key. A track without a key is analyzed
+// in the key that fits best.
+func RenderAnalysis(track *parser.Track) string {
+	var symbols, progression []string // Chords in order of first appearance, every chord
+	var lengths, progressionBars []float64
+	seen := make(map[string]int) // Index in symbols
+	var totalBars float64
+	for _, chord := range track.Progression.GetChords() {
+		progression = append(progression, chord.Symbol)
+		progressionBars = append(progressionBars, chord.Bars)
+		totalBars += chord.Bars
+		if i, ok := seen[chord.Symbol]; ok {
+			lengths[i] += chord.Bars
+			continue
+		}
+		seen[chord.Symbol] = len(symbols)
+		symbols = append(symbols, chord.Symbol)
+		lengths = append(lengths, chord.Bars)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s — analysis\n\n", track.Info.Title)
+	if len(symbols) == 0 {
+		sb.WriteString("No chords to analyze\n")
+		return sb.String()
+	}
+
+	fits := theory.FitKeys(progression, progressionBars)
+	key, fit := track.Info.Key, fits[0]
+	if key == "" {
+		key = fit.Key
+	} else {
+		fit = theory.KeyFit{Key: key}
+		for _, candidate := range fits {
+			if sameKey(candidate.Key, key) {
+				fit = candidate
+			}
+		}
+	}
+
+	functions := make([]theory.ChordFunction, len(symbols))
+	var explained float64
+	for i, symbol := range symbols {
+		functions[i] = theory.AnalyzeChord(symbol, key)
+		if functions[i].Explained() {
+			explained += lengths[i]
+		}
+	}
+
+	fmt.Fprintf(&sb, "Key: %s", key)
+	if track.Info.Key == "" {
+		sb.WriteString(" (no key set; the best fit)")
+	}
+	fmt.Fprintf(&sb, " — %d%% of bars diatonic, %d%% explained by the key", percent(fit.Diatonic), percent(explained/totalBars))
+	if fit.Tonic {
+		sb.WriteString(", starts or ends on the tonic")
+	}
+	sb.WriteString("\n")
+	// Another key only fits better if it also has the song's tonic where this one does
+	if best := fits[0]; !sameKey(best.Key, key) && best.Diatonic > fit.Diatonic && (best.Tonic || !fit.Tonic) {
+		fmt.Fprintf(&sb, "     %s fits better: %d%% of bars diatonic\n", best.Key, percent(best.Diatonic))
+	}
+	sb.WriteString("\n")
+
+	width := 0
+	for _, symbol := range symbols {
+		width = max(width, len(symbol))
+	}
+	var outside []string
+	var outsideBars float64
+	for i, symbol := range symbols {
+		function := functions[i]
+		fmt.Fprintf(&sb, "  %-*s  %-7s  %-34s %s\n", width, symbol, function.Numeral, function.Label, formatBars(lengths[i]))
+		if !function.Diatonic {
+			outside = append(outside, symbol)
+			outsideBars += lengths[i]
+		}
+	}
+
+	sb.WriteString("\n")
+	if len(outside) == 0 {
+		fmt.Fprintf(&sb, "%d chords, all diatonic to %s\n", len(symbols), key)
+	} else {
+		fmt.Fprintf(&sb, "%d chords, %d outside %s (%s): %s of %d\n", len(symbols), len(outside), key,
+			strings.Join(outside, " "), formatBars(outsideBars), int(math.Ceil(totalBars)))
+	}
+	return sb.String()
+}
+
+// sameKey reports whether two key names are the same key ("A#" and "Bb")
+func sameKey(a, b string) bool {
+	rootA, minorA := theory.ParseKey(a)
+	rootB, minorB := theory.ParseKey(b)
+	return rootA == rootB && minorA == minorB
+}
+
+// percent returns a 0-1 share as a whole percentage
+func percent(share float64) int {
+	return int(math.Round(share * 100))
+}
+
+// formatBars returns a length in bars, e.g. "1 bar" or "2.5 bars"
+func formatBars(bars float64) string {
+	if bars == 1 {
+		return "1 bar"
+	}
+	return fmt.Sprintf("%s bars", strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", bars), "0"), "."))
+}
@@ -0,0 +1,60 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+
+	"backing-tracks/parser"
+)
+
+// arrangementChordWidth is the width of the chord column in RenderArrangement
+const arrangementChordWidth = 24
+
+// RenderArrangement renders the song bar by bar as plain text, for proofreading a
+// chart without playing it: a heading at each section, then one line per bar with
+// its number (from 1), chords (a chord held for extra beats is followed by a dot
+// per beat, e.g. "C . G .") and lyrics. Section lyrics are shown on the bar where
+// their line starts.
+func RenderArrangement(track *parser.Track) string {
+	bars := ProcessChordsIntoBars(track)
+	sections := track.Progression.GetSections()
+	lyrics := parser.BuildLyricsBlocks(track.Sections, sections)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s — %d bars, %s, %d BPM, key %s\n",
+		track.Info.Title, len(bars), track.GetTimeSignature(), track.Info.Tempo, track.Info.Key)
+
+	if len(sections) == 0 || sections[0].StartBar > 0 {
+		sb.WriteString("\n")
+	}
+	for i, bar := range bars {
+		for _, info := range sections {
+			if info.StartBar == i {
+				fmt.Fprintf(&sb, "\n[%s]\n", info.Name)
+			}
+		}
+
+		var chords []string
+		for _, chord := range bar.Chords {
+			chords = append(chords, chord.Symbol)
+			for beat := 1; beat < chord.Beats; beat++ {
+				chords = append(chords, ".")
+			}
+		}
+		if len(bar.Chords) == 1 {
+			chords = chords[:1] // A whole-bar chord needs no dots
+		}
+
+		text := bar.Lyrics
+		if line := parser.GetLyricsAtBar(lyrics, i); line != nil && line.StartBar == i && line.Text != "" {
+			text = strings.TrimSpace(strings.Join([]string{text, line.Text}, " "))
+		}
+
+		row := fmt.Sprintf("%4d  %-*s", i+1, arrangementChordWidth, strings.Join(chords, " "))
+		if text != "" {
+			row += "  " + text
+		}
+		sb.WriteString(strings.TrimRight(row, " ") + "\n")
+	}
+	return sb.String()
+}
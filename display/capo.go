@@ -0,0 +1,99 @@
+package display
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"backing-tracks/midi"
+	"backing-tracks/parser"
+	"backing-tracks/theory"
+)
+
+// maxAdvisedCapo is the highest capo position RenderCapoAdvice considers
+const maxAdvisedCapo = 7
+
+// capoOption is the song's chords played as shapes with the capo at one fret
+type capoOption struct {
+	capo     int
+	shapes   []string // Shape of each chord, in order of first appearance
+	open     int      // Shapes that are easy open chords
+	openBars float64  // Bars on those shapes
+}
+
+// RenderCapoAdvice renders the capo positions 0-7 for the song as plain text, best
+// first: at each, the shapes the chords become (the sounding chord moved down by
+// the capo, as in the chord diagrams) and how many are easy open chords (see
+// midi.IsOpenChordShape). Positions with more open shapes rank higher, then those
+// with more bars on them, then lower capos. Shapes that aren't open are starred.
+func RenderCapoAdvice(track *parser.Track) string {
+	var chords []string
+	barsByChord := make(map[string]float64)
+	for _, chord := range track.Progression.GetChords() {
+		symbol := chord.Symbol
+		if idx := strings.Index(symbol, "/"); idx > 0 {
+			symbol = symbol[:idx] // The bass note doesn't change the shape
+		}
+		if _, seen := barsByChord[symbol]; !seen {
+			chords = append(chords, symbol)
+		}
+		barsByChord[symbol] += chord.Bars
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s — capo positions for key %s (%s)\n", track.Info.Title, track.Info.Key, strings.Join(chords, " "))
+	if track.TuningName() != "standard" {
+		fmt.Fprintf(&sb, "Shapes are for standard tuning, not %s\n", track.TuningName())
+	}
+	sb.WriteString("\n")
+	if len(chords) == 0 {
+		sb.WriteString("No chords to play\n")
+		return sb.String()
+	}
+
+	var options []capoOption
+	for capo := 0; capo <= maxAdvisedCapo; capo++ {
+		option := capoOption{capo: capo}
+		shapeKey := theory.TransposeKey(track.Info.Key, -capo)
+		for _, chord := range chords {
+			shape := theory.TransposeChord(chord, -capo)
+			if shapeKey != "" {
+				shape = theory.TransposeChordInKey(chord, -capo, shapeKey)
+			}
+			if midi.IsOpenChordShape(shape) {
+				option.open++
+				option.openBars += barsByChord[chord]
+			} else {
+				shape += "*"
+			}
+			option.shapes = append(option.shapes, shape)
+		}
+		options = append(options, option)
+	}
+	sort.SliceStable(options, func(i, j int) bool {
+		if options[i].open != options[j].open {
+			return options[i].open > options[j].open
+		}
+		return options[i].openBars > options[j].openBars
+	})
+
+	width := 0
+	for _, option := range options {
+		width = max(width, len(strings.Join(option.shapes, " ")))
+	}
+	sb.WriteString("  Capo  Open   Shapes\n")
+	for _, option := range options {
+		row := fmt.Sprintf("  %-4d  %d/%-3d  %-*s", option.capo, option.open, len(chords), width, strings.Join(option.shapes, " "))
+		shapeKey := ""
+		if option.capo > 0 && track.Info.Key != "" {
+			shapeKey = fmt.Sprintf("(%s shapes)", theory.TransposeKey(track.Info.Key, -option.capo))
+		}
+		row += fmt.Sprintf("   %-13s", shapeKey)
+		if option.capo == track.Info.Capo {
+			row += "  ← current"
+		}
+		sb.WriteString(strings.TrimRight(row, " ") + "\n")
+	}
+	sb.WriteString("\n* not an open shape (barre or up the neck)\n")
+	return sb.String()
+}
@@ -2,8 +2,10 @@ package display
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
+	"backing-tracks/midi"
 	"backing-tracks/theory"
 )
 
@@ -13,12 +15,13 @@ type ChordVoicing struct {
 	Name     string
 	Frets    [6]int // -1 = x (muted), 0 = open, 1+ = fret number
 	BaseFret int    // For barre chords, the starting fret (0 for open position)
-	Fingers  string // Optional finger positions
+	Fingers  string // Finger per string like Frets: "1"-"4", "T" thumb, "-" none ("" = unknown)
 }
 
 // ChordChart manages chord diagram display
 type ChordChart struct {
-	voicings map[string][]ChordVoicing // Standard tuning voicings
+	voicings   map[string][]ChordVoicing // Standard tuning voicings
+	preferEasy bool                      // List voicings easiest first (see VoicingDifficulty)
 }
 
 // NewChordChart creates a new chord chart with common voicings
@@ -231,6 +234,56 @@ func (cc *ChordChart) loadVoicings() {
 	cc.voicings["Eb"] = cc.voicings["D#"]
 	cc.voicings["Gb"] = cc.voicings["F#"]
 	cc.voicings["Ab"] = cc.voicings["G#"]
+
+	cc.addFingerings()
+}
+
+// addFingerings gives chart voicings the fingering of the tablature voicing
+// (midi.GuitarVoicings) with the same shape, where there is one
+func (cc *ChordChart) addFingerings() {
+	fingerings := make(map[[6]int]string)
+	for _, gv := range midi.GuitarVoicings {
+		var fingers strings.Builder
+		complete := true
+		for str, finger := range gv.Fingers {
+			switch {
+			case gv.Frets[str] <= 0:
+				fingers.WriteString("-")
+			case finger >= 1 && finger <= 4:
+				fmt.Fprintf(&fingers, "%d", finger)
+			case finger == 5:
+				fingers.WriteString("T")
+			default:
+				complete = false // A fretted string without a finger
+			}
+		}
+		if complete {
+			fingerings[gv.Frets] = fingers.String()
+		}
+	}
+
+	for _, voicings := range cc.voicings {
+		for i := range voicings {
+			if fingers, ok := fingerings[voicings[i].Frets]; ok {
+				voicings[i].Fingers = fingers
+			}
+		}
+	}
+}
+
+// fretMark returns the mark for a fretted string in a diagram: the finger to
+// use when the voicing has a fingering, otherwise a dot
+func (v ChordVoicing) fretMark(str int) string {
+	if str < len(v.Fingers) && v.Fingers[str] != '-' {
+		return v.Fingers[str : str+1]
+	}
+	return "●"
+}
+
+// SetPreferEasy makes the chart list each chord's voicings easiest first, so the
+// diagrams show open shapes rather than barre chords where there is a choice
+func (cc *ChordChart) SetPreferEasy(prefer bool) {
+	cc.preferEasy = prefer
 }
 
 // GetVoicings returns all voicings for a chord symbol (standard tuning)
@@ -238,10 +291,26 @@ func (cc *ChordChart) GetVoicings(symbol string) []ChordVoicing {
 	return cc.GetVoicingsForTuning(symbol, "standard")
 }
 
-// GetVoicingsForTuning returns voicings for a chord in a specific tuning
+// GetVoicingsForTuning returns voicings for a chord in a specific tuning,
+// easiest first if the chart prefers easy voicings
 func (cc *ChordChart) GetVoicingsForTuning(symbol, tuningName string) []ChordVoicing {
-	// For standard tuning, use predefined voicings if available
-	if tuningName == "" || tuningName == "standard" {
+	voicings := cc.voicingsForTuning(symbol, tuningName)
+	if !cc.preferEasy || len(voicings) < 2 {
+		return voicings
+	}
+	sorted := slices.Clone(voicings)
+	slices.SortStableFunc(sorted, func(a, b ChordVoicing) int {
+		return VoicingDifficulty(a) - VoicingDifficulty(b)
+	})
+	return sorted
+}
+
+// voicingsForTuning returns voicings for a chord in a specific tuning
+func (cc *ChordChart) voicingsForTuning(symbol, tuningName string) []ChordVoicing {
+	// For standard tuning (or a 7-string, whose top six strings are standard), use
+	// predefined voicings if available
+	if tuningName == "" || tuningName == "standard" ||
+		slices.Equal(theory.ChordStrings(theory.GetTuning(tuningName)).Notes, theory.GuitarTuning) {
 		if voicings, ok := cc.voicings[symbol]; ok {
 			return voicings
 		}
@@ -284,6 +353,121 @@ func (cc *ChordChart) GetVoicingsForTuning(symbol, tuningName string) []ChordVoi
 	return nil
 }
 
+// GetVoicingsForSkill returns voicings for a chord chosen for a player skill level.
+// Beginners get the simplified chord and only low-fret, small-span shapes;
+// advanced players get movable (no open strings) shapes first.
+func (cc *ChordChart) GetVoicingsForSkill(symbol, tuningName, skill string) []ChordVoicing {
+	switch theory.ParseSkill(skill) {
+	case theory.SkillBeginner:
+		voicings := cc.GetVoicingsForTuning(theory.SimplifyChordForSkill(symbol, skill), tuningName)
+		var easy []ChordVoicing
+		for _, v := range voicings {
+			if isEasyVoicing(v) {
+				easy = append(easy, v)
+			}
+		}
+		if len(easy) > 0 {
+			return easy
+		}
+		return voicings
+	case theory.SkillAdvanced:
+		voicings := cc.GetVoicingsForTuning(symbol, tuningName)
+		var movable, open []ChordVoicing
+		for _, v := range voicings {
+			hasOpen := false
+			for _, f := range v.Frets {
+				if f == 0 {
+					hasOpen = true
+					break
+				}
+			}
+			if hasOpen {
+				open = append(open, v)
+			} else {
+				movable = append(movable, v)
+			}
+		}
+		return append(movable, open...)
+	default:
+		return cc.GetVoicingsForTuning(symbol, tuningName)
+	}
+}
+
+// isEasyVoicing checks if a voicing stays within the first 4 frets with a small stretch
+func isEasyVoicing(v ChordVoicing) bool {
+	minFret, maxFret := 99, 0
+	for _, f := range v.Frets {
+		if f > 0 {
+			if f < minFret {
+				minFret = f
+			}
+			if f > maxFret {
+				maxFret = f
+			}
+		}
+	}
+	if maxFret == 0 {
+		return true // All open strings
+	}
+	return maxFret <= 4 && maxFret-minFret <= 3
+}
+
+// VoicingDifficulty scores how hard a voicing is to play: one point per fretted
+// string, two per fret of stretch beyond three frets and four for a barre.
+// Open chords score about 3, full barre chords 10 or more.
+func VoicingDifficulty(v ChordVoicing) int {
+	fretted, minFret, maxFret := 0, 99, 0
+	for _, f := range v.Frets {
+		if f > 0 {
+			fretted++
+			minFret = min(minFret, f)
+			maxFret = max(maxFret, f)
+		}
+	}
+	score := fretted
+	if fretted > 0 && maxFret-minFret > 2 {
+		score += 2 * (maxFret - minFret - 2)
+	}
+	if needsBarre(v) {
+		score += 4
+	}
+	return score
+}
+
+// needsBarre reports whether a voicing is played with a barre: one finger across
+// strings with another fretted string between them, or more fretted strings than
+// there are fingers when the voicing has no fingering
+func needsBarre(v ChordVoicing) bool {
+	if v.Fingers == "" {
+		fretted := 0
+		for _, f := range v.Frets {
+			if f > 0 {
+				fretted++
+			}
+		}
+		return fretted > 4
+	}
+	for lo := 0; lo < len(v.Fingers); lo++ {
+		for hi := lo + 2; hi < len(v.Fingers); hi++ {
+			if v.Fingers[lo] != '-' && v.Fingers[lo] == v.Fingers[hi] && v.Frets[lo] == v.Frets[hi] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unavoidableBarre reports whether every voicing of a chord needs a barre, for the
+// "(hard)" tag on its diagram
+func unavoidableBarre(voicings []ChordVoicing) bool {
+	for _, v := range voicings {
+		if !needsBarre(v) {
+			return false
+		}
+	}
+	return len(voicings) > 0
+}
+
 // normalizeChordSymbol converts chord variations to standard form
 func normalizeChordSymbol(symbol string) string {
 	// Replace common variations
@@ -383,7 +567,7 @@ func (cc *ChordChart) RenderSingleChord(v ChordVoicing) []string {
 		for str := 0; str < 6; str++ {
 			f := v.Frets[str]
 			if f == fret {
-				line += "●  "
+				line += v.fretMark(str) + "  "
 			} else {
 				line += "│  "
 			}
@@ -0,0 +1,61 @@
+package display
+
+import "testing"
+
+func TestIsEasyVoicing(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		frets [6]int
+		want  bool
+	}{
+		{"open G", [6]int{3, 2, 0, 0, 0, 3}, true},
+		{"open strings only", [6]int{0, 0, 0, 0, 0, 0}, true},
+		{"easy F", [6]int{-1, -1, 3, 2, 1, 1}, true},
+		{"barre at 7", [6]int{7, 9, 9, 7, 7, 7}, false},
+		{"fifth fret", [6]int{-1, 0, 2, 2, 5, 0}, false},
+		{"four-fret stretch", [6]int{1, -1, -1, -1, -1, 5}, false},
+	} {
+		if got := isEasyVoicing(ChordVoicing{Name: tc.name, Frets: tc.frets}); got != tc.want {
+			t.Errorf("isEasyVoicing(%s %v) = %v, want %v", tc.name, tc.frets, got, tc.want)
+		}
+	}
+}
+
+func TestGetVoicingsForSkill(t *testing.T) {
+	cc := NewChordChart()
+
+	// Beginners get the simplified chord, in easy shapes only
+	for symbol, simplified := range map[string]string{
+		"Cmaj9": "Cmaj7", "Am9": "Am7", "G13": "G7", "D/F#": "D", "F": "Fmaj7", "Bm": "Bm7", "G": "G",
+	} {
+		voicings := cc.GetVoicingsForSkill(symbol, "standard", "beginner")
+		if len(voicings) == 0 {
+			t.Errorf("%s: no beginner voicings", symbol)
+		}
+		for _, v := range voicings {
+			if !isEasyVoicing(v) {
+				t.Errorf("%s: beginner voicing %s %v is not low-fret and small-span", symbol, v.Name, v.Frets)
+			}
+			if v.Name != simplified && v.Name != simplified+" (bar)" {
+				t.Errorf("%s: beginner voicing %s, want a %s shape", symbol, v.Name, simplified)
+			}
+		}
+	}
+
+	// Advanced players keep extended chords, and movable shapes come first
+	for _, symbol := range []string{"Cmaj9", "Am9", "G13"} {
+		voicings := cc.GetVoicingsForSkill(symbol, "standard", "advanced")
+		if len(voicings) == 0 || voicings[0].Name != symbol {
+			t.Errorf("%s: advanced voicings %v, want the extended chord", symbol, voicings)
+		}
+	}
+	voicings := cc.GetVoicingsForSkill("G", "standard", "advanced")
+	if len(voicings) != 2 || voicings[0].Name != "G (bar)" {
+		t.Errorf("G: advanced voicings %v, want the barre shape before the open one", voicings)
+	}
+	for _, v := range cc.GetVoicingsForSkill("Bm", "standard", "advanced") {
+		if v.Name == "Bm7" {
+			t.Errorf("Bm: advanced voicings include the beginner substitute %s", v.Name)
+		}
+	}
+}
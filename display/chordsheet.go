@@ -0,0 +1,91 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+
+	"backing-tracks/parser"
+	"backing-tracks/theory"
+)
+
+// Chord sheet grid layout (plain-text export of the TUI's chord diagrams)
+const (
+	chordSheetPerRow = 4
+	chordSheetWidth  = 22
+)
+
+// RenderChordSheet renders a diagram of every unique chord in the song, in order
+// of first appearance and arranged in a grid. Shapes follow the track's tuning,
+// capo (a G with capo 2 is drawn as "G→F", the shape to play) and skill level.
+// With color off the ANSI bold on chord names is left out (for text files).
+func RenderChordSheet(track *parser.Track, color bool) string {
+	tuningName := track.TuningName()
+	tuning := theory.GetTuning(tuningName)
+	// Chord diagrams are drawn for 6 strings
+	if len(tuning.Notes) < 6 {
+		return fmt.Sprintf(" No chord diagrams for %d-string tunings\n", len(tuning.Notes))
+	}
+
+	chart := NewChordChart()
+	chart.SetPreferEasy(track.Info.EasyVoicings)
+	capo := track.Info.Capo
+	skill := track.Info.Skill
+
+	var diagrams [][]string
+	for _, chord := range uniqueChordSymbols(ProcessChordsIntoBars(track)) {
+		displayChord := chord
+		shapeChord := chord
+		if capo > 0 {
+			shapeChord = theory.TransposeChord(chord, -capo)
+			displayChord = fmt.Sprintf("%s→%s", chord, shapeChord)
+		}
+
+		easy := theory.SimplifyChordForSkill(shapeChord, skill)
+		voicings := chart.GetVoicingsForSkill(shapeChord, tuningName, skill)
+		if len(voicings) == 0 {
+			// Sparse tables (open tunings, DADGAD) miss many shapes; generate one
+			generated := theory.GenerateChordVoicing(easy, tuning)
+			voicings = []ChordVoicing{{Frets: generated.Frets, BaseFret: generated.BaseFret}}
+		}
+		if easy != shapeChord {
+			displayChord = fmt.Sprintf("%s→%s", displayChord, easy)
+		}
+		if unavoidableBarre(voicings) {
+			displayChord += " (hard)"
+		}
+
+		voicing := voicings[0]
+		voicing.Name = displayChord
+		diagram := chart.RenderSingleChord(voicing)
+		if !color {
+			diagram[0] = strings.NewReplacer(colorBold, "", colorReset, "").Replace(diagram[0])
+		}
+		diagrams = append(diagrams, diagram)
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(diagrams); i += chordSheetPerRow {
+		row := diagrams[i:min(i+chordSheetPerRow, len(diagrams))]
+
+		height := 0
+		for _, diagram := range row {
+			height = max(height, len(diagram))
+		}
+		for lineIdx := 0; lineIdx < height; lineIdx++ {
+			var line string
+			for _, diagram := range row {
+				cell := ""
+				if lineIdx < len(diagram) {
+					cell = diagram[lineIdx]
+				}
+				if pad := chordSheetWidth - visibleLength(cell); pad > 0 {
+					cell += strings.Repeat(" ", pad)
+				}
+				line += cell
+			}
+			sb.WriteString(strings.TrimRight(line, " ") + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
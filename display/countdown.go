@@ -0,0 +1,63 @@
+package display
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The countdown is a visual pre-roll ("3… 2… 1…") shown before the grid starts
+// moving. Any key skips it.
+
+// SetCountdown shows a countdown of the given number of bars when the TUI starts.
+// start is called when it ends (or is skipped) to begin playback; pass nil when
+// playback is already running, e.g. over an audible count-in.
+func (m *TUIModel) SetCountdown(bars int, start func()) {
+	if bars <= 0 {
+		return
+	}
+	m.countdown = true
+	m.countdownLength = m.timePerBeat * time.Duration(m.beatsPerBar*bars)
+	m.countdownStart = start
+}
+
+// countdownBeats returns the beats left in the countdown (at least 1)
+func (m *TUIModel) countdownBeats() int {
+	remaining := m.countdownLength - time.Since(m.countdownBegan)
+	beats := int((remaining + m.timePerBeat - 1) / m.timePerBeat)
+	if beats < 1 {
+		beats = 1
+	}
+	return beats
+}
+
+// endCountdown leaves the pre-roll and starts playback
+func (m *TUIModel) endCountdown() {
+	m.countdown = false
+	if m.countdownStart != nil {
+		m.countdownStart()
+		m.countdownStart = nil
+	}
+	m.startTime = time.Now()
+}
+
+// renderCountdown renders the remaining beats large and centered
+func (m *TUIModel) renderCountdown() string {
+	number := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(primaryColor).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Border).
+		Padding(1, 6).
+		Render(fmt.Sprintf("%d…", m.countdownBeats()))
+
+	box := lipgloss.JoinVertical(lipgloss.Center,
+		titleStyle.Render(m.track.Info.Title),
+		"",
+		number,
+		"",
+		headerStyle.Render("Get ready — press any key to start now"),
+	)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -16,6 +16,7 @@ type FretboardDisplay struct {
 	roots        [][]bool // [string][fret] = is root
 	highlighted  []int    // Currently playing MIDI notes
 	compactMode  bool     // Use compact display for narrow terminals
+	degreeLabels bool     // Label scale notes with their degree (R, b3, 5...) instead of dots
 }
 
 // NewFretboardDisplay creates a new fretboard display with standard tuning
@@ -60,6 +61,11 @@ func (fd *FretboardDisplay) SetCompactMode(compact bool) {
 	fd.compactMode = compact
 }
 
+// SetDegreeLabels enables/disables scale degree labels on the full fretboard
+func (fd *FretboardDisplay) SetDegreeLabels(labels bool) {
+	fd.degreeLabels = labels
+}
+
 // HighlightNote marks a note as currently playing
 func (fd *FretboardDisplay) HighlightNote(midiNote int) {
 	fd.highlighted = append(fd.highlighted, midiNote)
@@ -105,7 +111,7 @@ func (fd *FretboardDisplay) renderFull() []string {
 	lines = append(lines, "")
 
 	// Fret numbers header
-	fretHeader := "   "
+	fretHeader := "    "
 	for fret := 0; fret <= fd.numFrets; fret++ {
 		fretHeader += fmt.Sprintf("%2d ", fret)
 	}
@@ -174,7 +180,7 @@ func (fd *FretboardDisplay) renderFull() []string {
 	lines = append(lines, bottomLine)
 
 	// Fret markers
-	markerLine := "      "
+	markerLine := "    "
 	for fret := 0; fret <= fd.numFrets; fret++ {
 		if fret == 3 || fret == 5 || fret == 7 || fret == 9 || fret == 15 {
 			markerLine += " ● "
@@ -188,7 +194,11 @@ func (fd *FretboardDisplay) renderFull() []string {
 
 	// Legend
 	lines = append(lines, "")
-	lines = append(lines, " ◆ Root  ● Scale  ○ Playing")
+	if fd.degreeLabels {
+		lines = append(lines, " R Root  2-7 Scale degrees  ○ Playing")
+	} else {
+		lines = append(lines, " ◆ Root  ● Scale  ○ Playing")
+	}
 
 	return lines
 }
@@ -252,18 +262,25 @@ func (fd *FretboardDisplay) renderCompact() []string {
 	return lines
 }
 
-// getFretSymbol returns the display symbol for a fret position
+// getFretSymbol returns the display symbol for a fret position (2 columns wide)
 func (fd *FretboardDisplay) getFretSymbol(stringIdx, fret int) string {
 	if fd.isHighlighted(stringIdx, fret) {
-		return "\033[33m○\033[0m" // Yellow circle for playing
+		return "\033[33m○\033[0m─" // Yellow circle for playing
+	}
+	if fd.degreeLabels && fd.positions[stringIdx][fret] {
+		label := fmt.Sprintf("%2s", fd.scale.DegreeName(fd.tuning.Notes[stringIdx]+fret))
+		if fd.roots[stringIdx][fret] {
+			return "\033[1;31m" + label + "\033[0m" // Bold red R for root
+		}
+		return "\033[32m" + label + "\033[0m" // Green degree for scale note
 	}
 	if fd.roots[stringIdx][fret] {
-		return "\033[31m◆\033[0m" // Red diamond for root
+		return "\033[31m◆\033[0m─" // Red diamond for root
 	}
 	if fd.positions[stringIdx][fret] {
-		return "\033[32m●\033[0m" // Green dot for scale note
+		return "\033[32m●\033[0m─" // Green dot for scale note
 	}
-	return "─" // Empty fret
+	return "──" // Empty fret
 }
 
 // getCompactSymbol returns the compact display symbol for a fret position
@@ -285,7 +302,7 @@ func (fd *FretboardDisplay) GetWidth() int {
 	if fd.compactMode {
 		return 30 // Compact mode width
 	}
-	return (fd.numFrets + 1) * 3 + 6 // Full mode width
+	return (fd.numFrets+1)*3 + 6 // Full mode width
 }
 
 // RenderSimple returns a simplified one-line scale indicator
@@ -0,0 +1,99 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// helpBinding is one key (or key group) and what it does
+type helpBinding struct {
+	keys   string
+	action string
+}
+
+// helpSection is a category of bindings in the help overlay
+type helpSection struct {
+	title    string
+	bindings []helpBinding
+}
+
+// helpSections lists every TUI keybinding for the ? overlay; keep it in step
+// with Update and handleVolumeKey
+var helpSections = []helpSection{
+	{"Transport", []helpBinding{
+		{"Space", "Pause / resume"},
+		{"← / →", "Previous / next bar"},
+		{"Shift+↑ / Shift+↓", "Tempo up / down 5 BPM"},
+		{"T (tap 3+ times)", "Tap tempo"},
+		{"s", "Swing on / off (straight tracks get a triplet feel)"},
+		{"q / Esc / Ctrl+C", "Quit"},
+	}},
+	{"Pitch", []helpBinding{
+		{"↑ / ↓", "Transpose audio + display by a semitone"},
+		{"- / =", "Transpose display only"},
+		{"K", "Transpose to a key: type it (G, Bb, F#m), Enter"},
+		{"[ / ]", "Capo down / up (audio + display)"},
+		{"{ / }", "Visual capo down / up (display only)"},
+		{"< / >", "Previous / next guitar tuning"},
+	}},
+	{"Mix", []helpBinding{
+		{"1-6", "Mute drums / bass / chords / melody / fingerstyle / pad"},
+		{"Alt+1-6", "Solo a track"},
+		{"v", "Volume mode: 1-6 select, -/+ adjust, v or Esc exits"},
+	}},
+	{"Loop", []helpBinding{
+		{"Shift+1-9", "Loop the current bar and the next N-1 (again to stop)"},
+		{"Shift+0", "Loop the current section (again to stop)"},
+		{"i then o", "Loop from the bar at i through the bar at o (o alone moves the loop's end)"},
+		{"r then 0-9", "Play the active loop N times in all, then continue (0 = forever)"},
+	}},
+	{"Display", []helpBinding{
+		{"c", "Next voicing of the current chord's diagram"},
+		{"d", "Scale degree labels on the fretboard"},
+		{"n / N", "Nashville numbers / Roman numerals in the chord chart"},
+		{"l", "Lyrics"},
+		{"t", "Tablature"},
+		{"; / '", "Previous / next fingerstyle pattern"},
+		{"?", "This help"},
+	}},
+}
+
+// renderHelp renders the keybinding overlay, centered in the terminal
+func (m *TUIModel) renderHelp() string {
+	keyWidth := 0
+	for _, section := range helpSections {
+		for _, binding := range section.bindings {
+			if w := lipgloss.Width(binding.keys); w > keyWidth {
+				keyWidth = w
+			}
+		}
+	}
+
+	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(primaryColor)
+	keyStyle := lipgloss.NewStyle().Foreground(secondaryColor)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Keyboard Controls"))
+	b.WriteString("\n")
+	for _, section := range helpSections {
+		b.WriteString("\n")
+		b.WriteString(sectionStyle.Render(section.title))
+		b.WriteString("\n")
+		for _, binding := range section.bindings {
+			pad := strings.Repeat(" ", keyWidth-lipgloss.Width(binding.keys))
+			b.WriteString(fmt.Sprintf("  %s%s  %s\n", keyStyle.Render(binding.keys), pad, binding.action))
+		}
+	}
+	b.WriteString("\n")
+	b.WriteString(headerStyle.Render("Press any key to close"))
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Border).
+		Padding(1, 3).
+		Render(b.String())
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
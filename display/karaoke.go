@@ -0,0 +1,114 @@
+package display
+
+import (
+	"strings"
+
+	"backing-tracks/parser"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Karaoke view of the current bar's lyrics: a cursor bounces through the text in
+// time with the music, the part already sung is bold and the syllable being sung
+// (timed lyrics only) is underlined.
+
+// lyricCursor returns the character (rune) of text the singer is on at beat (beats
+// from the start of the bar), or -1 before the first syllable. Timed lyrics move the
+// cursor through each syllable until the next one starts; untimed lyrics are spread
+// evenly over the bar.
+func lyricCursor(text string, syllables []parser.LyricSyllable, beat float64, beatsPerBar int) int {
+	length := len([]rune(text))
+	if length == 0 {
+		return -1
+	}
+	if syllables == nil {
+		return min(length-1, int(beat/float64(beatsPerBar)*float64(length)))
+	}
+
+	current := parser.CurrentSyllable(syllables, beat)
+	if current < 0 {
+		return -1
+	}
+	start := 0
+	for _, syllable := range syllables[:current] {
+		start += len([]rune(syllable.Text))
+	}
+	word := len([]rune(strings.TrimRight(syllables[current].Text, " ")))
+	end := float64(beatsPerBar)
+	if current+1 < len(syllables) {
+		end = syllables[current+1].Beat
+	}
+	from := syllables[current].Beat
+	if end <= from || word == 0 {
+		return start
+	}
+	return start + min(word-1, int((beat-from)/(end-from)*float64(word)))
+}
+
+// karaokeWindow returns the first rune of text to show in room columns so the
+// cursor stays in view, a third of the way in once the text has to scroll
+func karaokeWindow(length, cursor, room int) int {
+	if length <= room || cursor < room*2/3 {
+		return 0
+	}
+	return min(cursor-room/3, length-room)
+}
+
+// renderKaraoke renders the current bar's lyrics in a column of width, with the
+// cursor at the current position (see lyricCursor)
+func (m *TUIModel) renderKaraoke(text string, syllables []parser.LyricSyllable, width int) string {
+	// The position within the bar, to the strum step
+	beat := float64(m.currentBeat)
+	if steps := len(m.strumSteps); steps > 0 {
+		beat = max(beat, float64(m.currentStrum*m.beatsPerBar)/float64(steps))
+	}
+	cursor := lyricCursor(text, syllables, beat, m.beatsPerBar)
+
+	// The rune range of the syllable being sung, to underline
+	wordStart, wordEnd := -1, -1
+	if current := parser.CurrentSyllable(syllables, beat); current >= 0 {
+		for _, syllable := range syllables[:current] {
+			wordStart += len([]rune(syllable.Text))
+		}
+		wordStart++
+		wordEnd = wordStart + len([]rune(strings.TrimRight(syllables[current].Text, " ")))
+	}
+
+	runes := []rune(text)
+	room := width - 2
+	first := karaokeWindow(len(runes), cursor, room)
+	visible := runes[first:min(len(runes), first+room)]
+
+	base := lyricsStyle.UnsetWidth()
+	styleAt := func(i int) lipgloss.Style {
+		style := base
+		switch {
+		case i == cursor:
+			return style.Bold(true).Reverse(true)
+		case i < cursor:
+			style = style.Bold(true)
+		}
+		if i >= wordStart && i < wordEnd && runes[i] != ' ' {
+			style = style.Underline(true)
+		}
+		return style
+	}
+
+	// Render runs of equally styled characters together
+	var sb strings.Builder
+	for i := 0; i < len(visible); {
+		style := styleAt(first + i)
+		j := i + 1
+		for j < len(visible) && sameKaraokeStyle(styleAt(first+j), style) {
+			j++
+		}
+		sb.WriteString(style.Render(string(visible[i:j])))
+		i = j
+	}
+	return lyricsStyle.Width(width).Render(sb.String())
+}
+
+// sameKaraokeStyle reports whether two karaoke character styles render alike
+func sameKaraokeStyle(a, b lipgloss.Style) bool {
+	return a.GetBold() == b.GetBold() && a.GetUnderline() == b.GetUnderline() && a.GetReverse() == b.GetReverse()
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"backing-tracks/midi"
 	"backing-tracks/parser"
 	"backing-tracks/theory"
 )
@@ -17,6 +18,7 @@ type LiveDisplay struct {
 	bars          []Bar // Processed bars with chords and lyrics
 	tempo         int
 	timePerBeat   time.Duration
+	beatsPerBar   int
 	startTime     time.Time
 	stopChan      chan bool
 	strumPattern  string
@@ -31,31 +33,31 @@ type LiveDisplay struct {
 
 // Bar represents a single bar with its chords and lyrics
 type Bar struct {
-	Chords []BarChord // Chords in this bar (can be multiple for half-bar chords)
-	Lyrics string     // Lyrics for this bar
+	Chords    []BarChord             // Chords in this bar (can be multiple for half-bar chords)
+	Lyrics    string                 // Lyrics for this bar
+	Syllables []parser.LyricSyllable // Timed syllables of Lyrics (nil when untimed)
 }
 
 // BarChord represents a chord within a bar
 type BarChord struct {
-	Symbol   string
-	Beats    int     // Number of beats this chord occupies (1-4)
-	StartBeat int    // Starting beat within the bar (0-3)
+	Symbol    string
+	Beats     int // Number of beats this chord occupies (1-beats per bar)
+	StartBeat int // Starting beat within the bar (0-based)
 }
 
 // NewLiveDisplay creates a new live display
 func NewLiveDisplay(track *parser.Track) *LiveDisplay {
-	// Calculate time per beat based on tempo
-	beatsPerSecond := float64(track.Info.Tempo) / 60.0
-	timePerBeat := time.Duration(float64(time.Second) / beatsPerSecond)
+	// Calculate time per beat based on tempo and time signature
+	timePerBeat := beatDuration(track)
 
 	// Get strum pattern
-	strumPattern := getStrumPattern(track.Rhythm)
+	strumPattern := getStrumPattern(track.Rhythm, track.GetTimeSignature())
 
 	// Process chords into bars
-	bars := processChordsIntoBars(track)
+	bars := ProcessChordsIntoBars(track)
 
-	// Initialize scale based on track style
-	scale := theory.GetScaleForStyle(track.Info.Key, track.Info.Style, "")
+	// Initialize scale from the track's scale override or style
+	scale := theory.GetScaleWithOverride(track.Info.Key, track.Info.Style, "", track.ScaleName())
 
 	// Create fretboard display (15 frets, compact mode for now)
 	fretboard := NewFretboardDisplay(scale, 15)
@@ -76,6 +78,7 @@ func NewLiveDisplay(track *parser.Track) *LiveDisplay {
 		bars:          bars,
 		tempo:         track.Info.Tempo,
 		timePerBeat:   timePerBeat,
+		beatsPerBar:   track.GetTimeSignature().Beats,
 		stopChan:      make(chan bool),
 		strumPattern:  strumPattern,
 		barsPerLine:   2, // 2 bars per line for karaoke style (more readable)
@@ -88,20 +91,29 @@ func NewLiveDisplay(track *parser.Track) *LiveDisplay {
 	}
 }
 
-// processChordsIntoBars converts chord progression into bar structure
-func processChordsIntoBars(track *parser.Track) []Bar {
+// beatDuration returns the length of one beat of the track's time signature
+// (tempo is in quarter notes, so a 6/8 beat is half a quarter)
+func beatDuration(track *parser.Track) time.Duration {
+	beatsPerSecond := float64(track.Info.Tempo) / 60.0
+	quarter := time.Duration(float64(time.Second) / beatsPerSecond)
+	return quarter * 4 / time.Duration(track.GetTimeSignature().BeatUnit)
+}
+
+// ProcessChordsIntoBars converts the chord progression into bars (also used by the exporters)
+func ProcessChordsIntoBars(track *parser.Track) []Bar {
 	chords := track.Progression.GetChords()
+	beatsPerBar := track.GetTimeSignature().Beats
 	var bars []Bar
 
 	currentBar := Bar{Chords: []BarChord{}, Lyrics: ""}
 	currentBeatInBar := 0
 
 	for _, chord := range chords {
-		beatsForChord := int(chord.Bars * 4)
+		beatsForChord := int(chord.Bars * float64(beatsPerBar))
 
 		// Handle chord that fits in current bar
 		for beatsForChord > 0 {
-			beatsAvailable := 4 - currentBeatInBar
+			beatsAvailable := beatsPerBar - currentBeatInBar
 			beatsToUse := beatsForChord
 			if beatsToUse > beatsAvailable {
 				beatsToUse = beatsAvailable
@@ -117,7 +129,7 @@ func processChordsIntoBars(track *parser.Track) []Bar {
 			beatsForChord -= beatsToUse
 
 			// If bar is full, start a new one
-			if currentBeatInBar >= 4 {
+			if currentBeatInBar >= beatsPerBar {
 				bars = append(bars, currentBar)
 				currentBar = Bar{Chords: []BarChord{}, Lyrics: ""}
 				currentBeatInBar = 0
@@ -134,7 +146,11 @@ func processChordsIntoBars(track *parser.Track) []Bar {
 	if track.Lyrics != nil {
 		for i, lyric := range track.Lyrics {
 			if i < len(bars) {
-				bars[i].Lyrics = lyric
+				var chordBeats []float64
+				for _, chord := range bars[i].Chords {
+					chordBeats = append(chordBeats, float64(chord.StartBeat))
+				}
+				bars[i].Lyrics, bars[i].Syllables = parser.ParseBarLyrics(lyric, beatsPerBar, chordBeats)
 			}
 		}
 	}
@@ -142,86 +158,37 @@ func processChordsIntoBars(track *parser.Track) []Bar {
 	return bars
 }
 
-// getStrumPattern returns the strum pattern string
-func getStrumPattern(rhythm *parser.Rhythm) string {
-	if rhythm == nil {
-		return "↓ . ↓ . ↓ . ↓ ." // Default quarter notes
-	}
-
-	if rhythm.Pattern != "" {
+// getStrumPattern returns the strum pattern string, as the chord part plays it
+// (see midi.StrumSteps); 16th-note bars are folded onto the display's eight steps
+func getStrumPattern(rhythm *parser.Rhythm, ts parser.TimeSignature) string {
+	if rhythm != nil && rhythm.Pattern != "" && ts.IsCommonTime() {
 		return convertPatternToDisplay(rhythm.Pattern)
 	}
-
-	switch rhythm.Style {
-	case "whole":
-		return "↓ - - - - - - -"
-	case "half":
-		return "↓ - - - ↓ - - -"
-	case "quarter":
-		return "↓ - ↓ - ↓ - ↓ -"
-	case "eighth":
-		return "↓ . ↑ . ↓ . ↑ ."
-	case "strum_down":
-		return "↓ . . ↓ . . ↓ ."
-	case "strum_up_down":
-		return "↓ . ↑ . ↓ . ↑ ."
-	case "folk":
-		return "↓ . ↓ ↑ ↓ . ↓ ↑"
-	case "shuffle_strum":
-		return ". ↓ . ↓ . ↓ . ↑"
-	case "travis", "fingerpick":
-		return "↓ . ↑ . ↓ . ↑ ."
-	case "fingerpick_slow":
-		return "↓ . . . ↓ . . ."
-	case "arpeggio_up", "arpeggio_down":
-		return "↓ ↓ ↓ ↓ ↓ ↓ ↓ ↓"
-	case "stride":
-		return ". . ↓ . . . ↓ ."
-	case "ragtime":
-		return ". ↓ ↓ . . ↓ ↓ ."
-	case "sixteenth":
-		return "↓.↑.↓.↑.↓.↑.↓.↑."
-	case "funk_16th":
-		return "↓.x.↑x↓.x.↑.↓x↑."
-	case "funk_muted":
-		return "x.↓.x.↑.x.↓.x.↑."
-	case "ska", "skank":
-		return ". ↓ . ↓ . ↓ . ↓"
-	case "reggae", "one_drop":
-		return ". . . . ↓ . . ."
-	case "country", "train":
-		return "↓ . ↓ . ↓ . ↓ ."
-	case "disco":
-		return "↓ . ↓ . ↓ . ↓ ."
-	case "motown", "soul":
-		return "↓ . ↓ ↑ ↓ . ↓ ↑"
-	case "flamenco", "rumba":
-		return "↓..↓..↓.↓.↓.↓..."
-	default:
-		return "↓ . ↑ . ↓ . ↑ ."
-	}
+	return strumStepsToDisplay(midi.StrumSteps(rhythm, ts))
 }
 
-// convertPatternToDisplay converts D/U/x/. pattern to display symbols
+// convertPatternToDisplay converts a D/U/x/. pattern to display symbols
 func convertPatternToDisplay(pattern string) string {
-	var result []string
-	for _, c := range pattern {
-		switch c {
-		case 'D':
-			result = append(result, "↓")
-		case 'd':
-			result = append(result, "↓")
-		case 'U':
-			result = append(result, "↑")
-		case 'u':
-			result = append(result, "↑")
-		case 'x':
-			result = append(result, "x")
-		case '.':
-			result = append(result, ".")
-		case '-':
-			result = append(result, "-")
+	return strumStepsToDisplay(midi.PatternStrumSteps(pattern))
+}
+
+// strumStepsToDisplay joins strum step symbols, at least eight of them. Each pair
+// of 16 steps shows its louder strum.
+func strumStepsToDisplay(steps []midi.StrumStep) string {
+	if len(steps) == 16 {
+		folded := make([]midi.StrumStep, 8)
+		for i := range folded {
+			folded[i] = steps[2*i]
+			if steps[2*i+1].Velocity > folded[i].Velocity {
+				folded[i] = steps[2*i+1]
+			}
 		}
+		steps = folded
+	}
+
+	var result []string
+	for _, step := range steps {
+		result = append(result, step.Symbol)
 	}
 	// Pad to 8 if needed
 	for len(result) < 8 {
@@ -264,9 +231,15 @@ const visibleRows = 4
 
 // getUniqueChords returns all unique chord symbols from the song
 func (ld *LiveDisplay) getUniqueChords() []string {
+	return uniqueChordSymbols(ld.bars)
+}
+
+// uniqueChordSymbols returns the chord symbols of the bars in order of first
+// appearance, without slash chord bass notes (charts are looked up by the chord)
+func uniqueChordSymbols(bars []Bar) []string {
 	seen := make(map[string]bool)
 	var unique []string
-	for _, bar := range ld.bars {
+	for _, bar := range bars {
 		for _, bc := range bar.Chords {
 			// Strip slash chord bass note for chart lookup
 			symbol := bc.Symbol
@@ -414,8 +387,8 @@ func (ld *LiveDisplay) render() {
 
 	// Calculate current position
 	totalBeats := int(elapsed / ld.timePerBeat)
-	currentBeat := totalBeats % 4          // 0-3
-	currentBar := totalBeats / 4           // Which bar we're in
+	currentBeat := totalBeats % ld.beatsPerBar // 0-based beat in the bar
+	currentBar := totalBeats / ld.beatsPerBar  // Which bar we're in
 
 	// Calculate strum position (8 strums per bar for 8th notes)
 	timePerStrum := ld.timePerBeat / 2
@@ -432,7 +405,7 @@ func (ld *LiveDisplay) render() {
 	}
 	// Don't scroll past the end
 	totalRows := (len(ld.bars) + ld.barsPerLine - 1) / ld.barsPerLine
-	if startRow + visibleRows > totalRows {
+	if startRow+visibleRows > totalRows {
 		startRow = totalRows - visibleRows
 		if startRow < 0 {
 			startRow = 0
@@ -445,7 +418,7 @@ func (ld *LiveDisplay) render() {
 		if newChord != ld.currentChord {
 			ld.currentChord = newChord
 		}
-		if strings.Contains(strings.ToLower(ld.track.Info.Style), "jazz") {
+		if strings.Contains(strings.ToLower(ld.track.Info.Style), "jazz") && ld.track.ScaleName() == "" {
 			newScale := theory.GetScaleForStyle(ld.track.Info.Key, ld.track.Info.Style, newChord)
 			if newScale.Name != ld.currentScale.Name {
 				ld.currentScale = newScale
@@ -13,37 +13,22 @@ import (
 
 // TablatureDisplay manages the tablature visualization
 type TablatureDisplay struct {
-	tablature     *midi.Tablature
-	config        midi.TablatureConfig
-	enabled       bool
-	currentBar    int
-	currentBeat   float64
-	width         int
+	tablature   *midi.Tablature
+	config      midi.TablatureConfig
+	enabled     bool
+	currentBar  int
+	currentBeat float64
+	width       int
 }
 
-// Tablature styles
+// Tablature styles, built from the active theme (see theme.go)
 var (
-	tabHeaderStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#00FFFF")).
-		Bold(true)
-
-	tabStringStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#888888"))
-
-	tabFretStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FFFFFF"))
-
-	tabCurrentFretStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#00FF00")).
-		Bold(true)
-
-	tabPlayheadStyle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FFFF00"))
-
-	tabBorderStyle = lipgloss.NewStyle().
-		Border(lipgloss.NormalBorder(), true, true, true, true).
-		BorderForeground(lipgloss.Color("#444444")).
-		Padding(0, 1)
+	tabHeaderStyle      lipgloss.Style
+	tabStringStyle      lipgloss.Style
+	tabFretStyle        lipgloss.Style
+	tabCurrentFretStyle lipgloss.Style
+	tabPlayheadStyle    lipgloss.Style
+	tabBorderStyle      lipgloss.Style
 )
 
 // NewTablatureDisplay creates a new tablature display
@@ -231,8 +216,7 @@ func (td *TablatureDisplay) Render() string {
 	b.WriteString("\n")
 
 	// Controls hint
-	hint := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#666666")).
+	hint := theme.dimStyle(theme.Dim).
 		Render("  [t] toggle tab  [;/'] change pattern  [p] complexity")
 	b.WriteString(hint)
 
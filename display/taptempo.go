@@ -0,0 +1,61 @@
+package display
+
+import (
+	"sort"
+	"time"
+)
+
+// Tap tempo: pressing T repeatedly sets the playback tempo to the tapped rate
+const (
+	tapTempoReset     = 2 * time.Second // A longer pause starts a new series of taps
+	tapTempoMaxTaps   = 4               // Only the most recent taps are averaged
+	tapTempoTolerance = 0.25            // Intervals this far from the median are outliers
+)
+
+// tapTempo collects tap times and turns them into a BPM
+type tapTempo struct {
+	taps []time.Time
+}
+
+// tap records a tap and returns the tapped tempo once there are at least three
+// taps. The intervals between the last four taps are averaged, ignoring any that
+// differ from the median by more than tapTempoTolerance (a missed or double tap).
+func (t *tapTempo) tap(now time.Time) (bpm int, ok bool) {
+	if n := len(t.taps); n > 0 && now.Sub(t.taps[n-1]) > tapTempoReset {
+		t.taps = nil
+	}
+	t.taps = append(t.taps, now)
+	if len(t.taps) > tapTempoMaxTaps {
+		t.taps = t.taps[len(t.taps)-tapTempoMaxTaps:]
+	}
+	if len(t.taps) < 3 {
+		return 0, false
+	}
+
+	var intervals []time.Duration
+	for i := 1; i < len(t.taps); i++ {
+		intervals = append(intervals, t.taps[i].Sub(t.taps[i-1]))
+	}
+	sorted := append([]time.Duration(nil), intervals...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	median := sorted[len(sorted)/2]
+
+	var total time.Duration
+	count := 0
+	for _, interval := range intervals {
+		diff := interval - median
+		if diff < 0 {
+			diff = -diff
+		}
+		if float64(diff) <= float64(median)*tapTempoTolerance {
+			total += interval
+			count++
+		}
+	}
+	if count == 0 || total <= 0 {
+		return 0, false
+	}
+
+	average := total / time.Duration(count)
+	return int(float64(time.Minute)/float64(average) + 0.5), true
+}
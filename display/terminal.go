@@ -103,6 +103,18 @@ func ShowTrack(track *parser.Track) {
 			if track.Drums.Ride != nil {
 				parts = append(parts, "ride")
 			}
+			if track.Drums.Tom != nil {
+				parts = append(parts, "toms")
+			}
+			if track.Drums.Clap != nil {
+				parts = append(parts, "clap")
+			}
+			if track.Drums.Tambourine != nil {
+				parts = append(parts, "tambourine")
+			}
+			if track.Drums.Cowbell != nil {
+				parts = append(parts, "cowbell")
+			}
 			drumsInfo += strings.Join(parts, "+")
 		}
 
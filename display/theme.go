@@ -0,0 +1,204 @@
+package display
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme holds the colors the TUI draws with. SetTheme swaps the active theme
+// before the TUI starts; the package styles are rebuilt from it.
+type Theme struct {
+	Name      string
+	Primary   lipgloss.TerminalColor // Current chord, section headings
+	Secondary lipgloss.TerminalColor // Lyrics, playhead
+	Accent    lipgloss.TerminalColor // Current beat, progress bar
+	Dim       lipgloss.TerminalColor // Inactive beats, hints
+	Root      lipgloss.TerminalColor // Root notes on the fretboard
+	Text      lipgloss.TerminalColor // Title, tab frets
+	Muted     lipgloss.TerminalColor // Header info, control hints
+	Border    lipgloss.TerminalColor // Column and box borders
+	Info      lipgloss.TerminalColor // Capo, fret numbers, number mode
+	Highlight lipgloss.TerminalColor // Transpose and loop indicators, active diagram
+	Positive  lipgloss.TerminalColor // Solo and tuning indicators
+	Attention lipgloss.TerminalColor // Section, volume and pause indicators
+	Warning   lipgloss.TerminalColor // Mutes and reload errors
+	ChordTone lipgloss.TerminalColor // Chord tones on the fretboard
+	Selected  lipgloss.TerminalColor // Background of the active chord diagram name
+	Mono      bool                   // No colors: dim text is faint and highlights are bold
+}
+
+// Built-in themes for --theme
+var (
+	DarkTheme = Theme{
+		Name:      "dark",
+		Primary:   lipgloss.Color("#00FFFF"), // Cyan
+		Secondary: lipgloss.Color("#FFFF00"), // Yellow
+		Accent:    lipgloss.Color("#00FF00"), // Green
+		Dim:       lipgloss.Color("#666666"), // Gray
+		Root:      lipgloss.Color("#FF6666"), // Red for root notes
+		Text:      lipgloss.Color("#FFFFFF"),
+		Muted:     lipgloss.Color("#888888"),
+		Border:    lipgloss.Color("#444444"),
+		Info:      lipgloss.Color("#00CCCC"),
+		Highlight: lipgloss.Color("#FF00FF"),
+		Positive:  lipgloss.Color("#66FF66"),
+		Attention: lipgloss.Color("#FFAA00"),
+		Warning:   lipgloss.Color("#FF6666"),
+		ChordTone: lipgloss.Color("214"), // Orange
+		Selected:  lipgloss.Color("236"),
+	}
+
+	// LightTheme uses darker shades that stay readable on a white background
+	LightTheme = Theme{
+		Name:      "light",
+		Primary:   lipgloss.Color("#005F87"), // Dark blue
+		Secondary: lipgloss.Color("#875F00"), // Brown
+		Accent:    lipgloss.Color("#008700"), // Dark green
+		Dim:       lipgloss.Color("#9E9E9E"),
+		Root:      lipgloss.Color("#D70000"),
+		Text:      lipgloss.Color("#000000"),
+		Muted:     lipgloss.Color("#585858"),
+		Border:    lipgloss.Color("#BCBCBC"),
+		Info:      lipgloss.Color("#00808C"),
+		Highlight: lipgloss.Color("#AF00AF"),
+		Positive:  lipgloss.Color("#008700"),
+		Attention: lipgloss.Color("#AF5F00"),
+		Warning:   lipgloss.Color("#D70000"),
+		ChordTone: lipgloss.Color("166"), // Dark orange
+		Selected:  lipgloss.Color("254"),
+	}
+
+	// MonoTheme uses only bold and faint text, for accessibility and terminals
+	// that mangle truecolor
+	MonoTheme = Theme{
+		Name:      "mono",
+		Primary:   lipgloss.NoColor{},
+		Secondary: lipgloss.NoColor{},
+		Accent:    lipgloss.NoColor{},
+		Dim:       lipgloss.NoColor{},
+		Root:      lipgloss.NoColor{},
+		Text:      lipgloss.NoColor{},
+		Muted:     lipgloss.NoColor{},
+		Border:    lipgloss.NoColor{},
+		Info:      lipgloss.NoColor{},
+		Highlight: lipgloss.NoColor{},
+		Positive:  lipgloss.NoColor{},
+		Attention: lipgloss.NoColor{},
+		Warning:   lipgloss.NoColor{},
+		ChordTone: lipgloss.NoColor{},
+		Selected:  lipgloss.NoColor{},
+		Mono:      true,
+	}
+)
+
+// Themes lists the built-in themes by name, in --theme order
+var Themes = []Theme{DarkTheme, LightTheme, MonoTheme}
+
+// ThemeNames returns the names of the built-in themes
+func ThemeNames() []string {
+	var names []string
+	for _, t := range Themes {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// theme is the active theme
+var theme = DarkTheme
+
+func init() {
+	applyTheme(DarkTheme)
+}
+
+// SetTheme selects a built-in theme by name
+func SetTheme(name string) error {
+	for _, t := range Themes {
+		if t.Name == name {
+			applyTheme(t)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown theme %q", name)
+}
+
+// applyTheme makes t the active theme and rebuilds the package styles from it
+func applyTheme(t Theme) {
+	theme = t
+
+	primaryColor = t.Primary
+	secondaryColor = t.Secondary
+	accentColor = t.Accent
+	dimColor = t.Dim
+	rootColor = t.Root
+
+	titleStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(t.Text)
+
+	headerStyle = t.dimStyle(t.Muted)
+
+	chordStyle = lipgloss.NewStyle().
+		Width(20).
+		Align(lipgloss.Center)
+
+	currentChordStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(primaryColor).
+		Width(20).
+		Align(lipgloss.Center)
+
+	lyricsStyle = lipgloss.NewStyle().
+		Foreground(secondaryColor).
+		Width(20)
+
+	beatStyle = t.dimStyle(dimColor)
+
+	currentBeatStyle = t.style(accentColor)
+
+	columnStyle = lipgloss.NewStyle().
+		Padding(0, 1)
+
+	borderStyle = lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder(), false, true, false, false).
+		BorderForeground(t.Border)
+
+	progressStyle = t.style(accentColor)
+
+	tabHeaderStyle = lipgloss.NewStyle().
+		Foreground(t.Primary).
+		Bold(true)
+
+	tabStringStyle = t.dimStyle(t.Muted)
+
+	tabFretStyle = lipgloss.NewStyle().
+		Foreground(t.Text)
+
+	tabCurrentFretStyle = lipgloss.NewStyle().
+		Foreground(t.Accent).
+		Bold(true)
+
+	tabPlayheadStyle = t.style(t.Secondary)
+
+	tabBorderStyle = lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder(), true, true, true, true).
+		BorderForeground(t.Border).
+		Padding(0, 1)
+}
+
+// style returns a style in color c; in the mono theme it is bold instead, so
+// highlights still stand out
+func (t Theme) style(c lipgloss.TerminalColor) lipgloss.Style {
+	if t.Mono {
+		return lipgloss.NewStyle().Bold(true)
+	}
+	return lipgloss.NewStyle().Foreground(c)
+}
+
+// dimStyle returns a style in the de-emphasized color c (faint in the mono theme)
+func (t Theme) dimStyle(c lipgloss.TerminalColor) lipgloss.Style {
+	if t.Mono {
+		return lipgloss.NewStyle().Faint(true)
+	}
+	return lipgloss.NewStyle().Foreground(c)
+}
@@ -13,57 +13,36 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-// Styles for the TUI
+// Styles for the TUI, built from the active theme (see theme.go)
 var (
 	// Colors
-	primaryColor   = lipgloss.Color("#00FFFF") // Cyan
-	secondaryColor = lipgloss.Color("#FFFF00") // Yellow
-	accentColor    = lipgloss.Color("#00FF00") // Green
-	dimColor       = lipgloss.Color("#666666") // Gray
-	rootColor      = lipgloss.Color("#FF6666") // Red for root notes
+	primaryColor   lipgloss.TerminalColor
+	secondaryColor lipgloss.TerminalColor
+	accentColor    lipgloss.TerminalColor
+	dimColor       lipgloss.TerminalColor
+	rootColor      lipgloss.TerminalColor
 
 	// Base styles
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#FFFFFF"))
-
-	headerStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#888888"))
-
-	chordStyle = lipgloss.NewStyle().
-			Width(20).
-			Align(lipgloss.Center)
-
-	currentChordStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(primaryColor).
-				Width(20).
-				Align(lipgloss.Center)
-
-	lyricsStyle = lipgloss.NewStyle().
-			Foreground(secondaryColor).
-			Width(20)
-
-	beatStyle = lipgloss.NewStyle().
-			Foreground(dimColor)
-
-	currentBeatStyle = lipgloss.NewStyle().
-				Foreground(accentColor)
-
-	columnStyle = lipgloss.NewStyle().
-			Padding(0, 1)
-
-	borderStyle = lipgloss.NewStyle().
-			Border(lipgloss.NormalBorder(), false, true, false, false).
-			BorderForeground(lipgloss.Color("#444444"))
-
-	progressStyle = lipgloss.NewStyle().
-			Foreground(accentColor)
+	titleStyle        lipgloss.Style
+	headerStyle       lipgloss.Style
+	chordStyle        lipgloss.Style
+	currentChordStyle lipgloss.Style
+	lyricsStyle       lipgloss.Style
+	beatStyle         lipgloss.Style
+	currentBeatStyle  lipgloss.Style
+	columnStyle       lipgloss.Style
+	borderStyle       lipgloss.Style
+	progressStyle     lipgloss.Style
 )
 
 // TickMsg is sent on each tick for time updates
 type TickMsg time.Time
 
+// WatchErrorMsg is sent in watch mode when the edited BTML file fails to reload
+type WatchErrorMsg struct {
+	Err error
+}
+
 // PlayerController interface for controlling audio playback
 type PlayerController interface {
 	TogglePause()
@@ -74,14 +53,26 @@ type PlayerController interface {
 	GetTranspose() int
 	SetCapo(fret int)
 	GetCapo() int
-	ToggleTrackMute(track int) // 0=drums, 1=bass, 2=chords, 3=melody, 4=fingerstyle
+	ToggleTrackMute(track int) // 0=drums, 1=bass, 2=chords, 3=melody, 4=fingerstyle, 5=pad
 	IsTrackMuted(track int) bool
+	ToggleTrackSolo(track int) // Same indices as ToggleTrackMute
+	IsTrackSoloed(track int) bool
+	SetTrackVolume(track int, vol int) // CC7 volume 0-127, same indices as ToggleTrackMute
+	GetTrackVolume(track int) int
 	SetFingerstylePattern(pattern midi.PatternType)
 	GetFingerstylePattern() midi.PatternType
-	ToggleLoop(length int)                                 // Toggle loop of N bars from current position
-	GetLoop() (enabled bool, startBar, endBar, length int) // Get loop state
-	AdjustTempo(deltaBPM int)                              // Adjust playback tempo by delta BPM
-	GetTempo() (effectiveBPM int, offset int)              // Get current effective tempo and offset
+	ToggleLoop(length int)                                  // Toggle loop of N bars from current position
+	SetLoopPoints(startBar, endBar int)                     // Loop bars [startBar, endBar), e.g. from in/out points
+	GetLoop() (enabled bool, startBar, endBar, length int)  // Get loop state
+	SetLoopRepeats(repeats int)                             // Passes left in the active loop, then continue (0 = forever)
+	GetLoopRepeats() int                                    // Passes left in the active loop (0 = forever)
+	AdjustTempo(deltaBPM int)                               // Adjust playback tempo by delta BPM
+	SetEffectiveTempo(bpm int)                              // Set playback tempo (tap tempo)
+	ToggleSwing()                                           // Switch every part between straight and swung
+	GetSwing() (swung, toggled bool)                        // Whether parts swing, and whether toggled from the track
+	GetTempo() (effectiveBPM int, offset int)               // Get current effective tempo and offset
+	GetTempoAt(bar, beat int) int                           // Effective tempo at a position, following tempo changes
+	GetTrainer() (step, maxBPM int)                         // Speed trainer step and target tempo (step 0 = off)
 	GetCurrentSection() (name string, startBar, endBar int) // Get current section info
 	LoopCurrentSection()                                    // Toggle loop for current section
 	GetCurrentLyrics() (text string, chords []string)       // Get lyrics at current position
@@ -96,10 +87,12 @@ type TUIModel struct {
 	chords       []parser.Chord
 	tempo        int
 	timePerBeat  time.Duration
+	beatsPerBar  int // From the time signature (4 for 4/4, 3 for 3/4, 6 for 6/8)
 	startTime    time.Time
 	currentBar   int
 	currentBeat  int
 	currentStrum int
+	strumSteps   []midi.StrumStep // One bar of the chord part (see midi.StrumSteps)
 
 	// Display components
 	fretboard    *FretboardDisplay
@@ -119,32 +112,55 @@ type TUIModel struct {
 	paused          bool
 	pausedAt        time.Time
 	pausedTotal     time.Duration
-	seekOffset      time.Duration // For seeking forward/backward
-	transposeOffset int           // Semitones to transpose (+/-)
-	capoPosition    int           // Capo fret position (0 = no capo)
-	lyricsEnabled   bool          // Show lyrics display
+	seekOffset      time.Duration  // For seeking forward/backward
+	transposeOffset int            // Semitones to transpose (+/-)
+	visualTranspose int            // Display-only transpose (chords/fretboard, no audio change)
+	capoPosition    int            // Capo fret position (0 = no capo)
+	lyricsEnabled   bool           // Show lyrics display
+	showDegrees     bool           // Show scale degrees instead of dots on the fretboard
+	chordNames      chordNameMode  // Chord chart as symbols, Nashville numbers or Roman numerals
+	voicingIndex    map[string]int // Diagram voicing shown per song chord symbol (c cycles the current chord's)
+	showHelp        bool           // Keybinding overlay (? to open, any key to close)
+	volumeMode      bool           // Volume submode: 1-6 select a track, -/+ change its volume
+	volumeTrack     int            // Track selected in volume mode (same indices as mute keys)
+	keyPrompt       *string        // Typed key while the K "transpose to key" prompt is open
+	spelledKey      string         // Key chosen at the prompt; chords are spelled to suit it
+	repeatMode      bool           // Repeat submode: 1-9 set how many times the loop plays, 0 = forever
+	loopIn          int            // Bar marked with i as the next loop's start (-1 = none)
+	watchError      string         // Last reload error in watch mode (shown until the next reload)
+	tapTempo        tapTempo       // Recent T presses for tap tempo
+	countdown       bool           // Visual pre-roll before the grid starts (see countdown.go)
+	countdownBegan  time.Time      // When the countdown started
+	countdownLength time.Duration  // How long the countdown lasts
+	countdownStart  func()         // Starts playback when the countdown ends (nil if already playing)
 	quitting        bool
 
 	// Audio player (optional - for synced playback)
 	player PlayerController
 }
 
+// chordNameMode selects how the chord chart names chords
+type chordNameMode int
+
+const (
+	chordSymbols   chordNameMode = iota // As written (with transpose applied)
+	chordNashville                      // Nashville numbers relative to the key (1, 4, 5, 6m)
+	chordRoman                          // Roman numerals relative to the key (I, IV, V, vi)
+)
+
 // NewTUIModel creates a new TUI model
 func NewTUIModel(track *parser.Track) *TUIModel {
-	beatsPerSecond := float64(track.Info.Tempo) / 60.0
-	timePerBeat := time.Duration(float64(time.Second) / beatsPerSecond)
+	timePerBeat := beatDuration(track)
 
-	bars := processChordsIntoBars(track)
-	scale := theory.GetScaleForStyle(track.Info.Key, track.Info.Style, "")
-	tuningName := track.Info.Tuning
-	if tuningName == "" {
-		tuningName = "standard"
-	}
+	bars := ProcessChordsIntoBars(track)
+	scale := theory.GetScaleWithOverride(track.Info.Key, track.Info.Style, "", track.ScaleName())
+	tuningName := track.TuningName()
 	tuning := theory.GetTuning(tuningName)
 	tuningIndex := theory.GetTuningIndex(tuningName)
 	fretboard := NewFretboardDisplayWithTuning(scale, 15, tuning)
 	fretboard.SetCompactMode(true)
 	chordChart := NewChordChart()
+	chordChart.SetPreferEasy(track.Info.EasyVoicings)
 	tablature := NewTablatureDisplay(track, tuning, track.Info.Capo)
 
 	// Check if track has lyrics (in sections or per-bar)
@@ -156,25 +172,42 @@ func NewTUIModel(track *parser.Track) *TUIModel {
 		}
 	}
 
-	return &TUIModel{
-		track:         track,
-		bars:          bars,
-		chords:        track.Progression.GetChords(),
-		tempo:         track.Info.Tempo,
-		timePerBeat:   timePerBeat,
-		fretboard:     fretboard,
-		chordChart:    chordChart,
-		tablature:     tablature,
-		currentScale:  scale,
-		tuning:        tuning,
-		tuningIndex:   tuningIndex,
-		tuningName:    tuningName,
-		capoPosition:  track.Info.Capo, // Initialize from track
-		lyricsEnabled: hasLyrics,       // Enable by default if track has lyrics
-		playing:       true,
-		width:         120,
-		height:        30,
-	}
+	m := &TUIModel{
+		track:           track,
+		bars:            bars,
+		chords:          track.Progression.GetChords(),
+		tempo:           track.Info.Tempo,
+		timePerBeat:     timePerBeat,
+		beatsPerBar:     track.GetTimeSignature().Beats,
+		strumSteps:      midi.StrumSteps(track.Rhythm, track.GetTimeSignature()),
+		fretboard:       fretboard,
+		chordChart:      chordChart,
+		tablature:       tablature,
+		currentScale:    scale,
+		tuning:          tuning,
+		tuningIndex:     tuningIndex,
+		tuningName:      tuningName,
+		capoPosition:    max(0, min(12, track.Info.Capo)), // Initialize from track (or --capo)
+		lyricsEnabled:   hasLyrics,                        // Enable by default if track has lyrics
+		visualTranspose: startVisualTranspose,
+		loopIn:          -1,
+		playing:         true,
+		width:           120,
+		height:          30,
+	}
+	if m.visualTranspose != 0 {
+		m.updateTransposedScale()
+	}
+	return m
+}
+
+// startVisualTranspose is the display-only transpose new TUIs start with
+var startVisualTranspose int
+
+// SetDisplayTranspose makes new TUIs start with their chords and fretboard shifted
+// by semitones without changing the sound, as if -/= had been pressed
+func SetDisplayTranspose(semitones int) {
+	startVisualTranspose = semitones
 }
 
 // SetPlayer sets the audio player controller for synced playback
@@ -185,6 +218,7 @@ func (m *TUIModel) SetPlayer(p PlayerController) {
 // Init initializes the model
 func (m *TUIModel) Init() tea.Cmd {
 	m.startTime = time.Now()
+	m.countdownBegan = m.startTime
 	return tea.Batch(
 		tickCmd(),
 		tea.EnterAltScreen,
@@ -202,6 +236,24 @@ func tickCmd() tea.Cmd {
 func (m *TUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.countdown && msg.String() != "ctrl+c" {
+			m.endCountdown()
+			return m, nil
+		}
+		if m.showHelp && msg.String() != "ctrl+c" {
+			m.showHelp = false
+			return m, nil
+		}
+		if m.volumeMode && m.handleVolumeKey(msg.String()) {
+			return m, nil
+		}
+		if m.keyPrompt != nil && msg.String() != "ctrl+c" {
+			m.handleKeyPromptKey(msg)
+			return m, nil
+		}
+		if m.repeatMode && m.handleRepeatKey(msg.String()) {
+			return m, nil
+		}
 		switch msg.String() {
 		case "q", "ctrl+c", "esc":
 			m.quitting = true
@@ -224,7 +276,7 @@ func (m *TUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.player != nil {
 				m.player.SeekRelative(-1)
 			} else {
-				timePerBar := m.timePerBeat * 4
+				timePerBar := m.timePerBeat * time.Duration(m.beatsPerBar)
 				if m.currentBar > 0 {
 					m.seekOffset -= timePerBar
 				}
@@ -234,13 +286,14 @@ func (m *TUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.player != nil {
 				m.player.SeekRelative(1)
 			} else {
-				timePerBar := m.timePerBeat * 4
+				timePerBar := m.timePerBeat * time.Duration(m.beatsPerBar)
 				if m.currentBar < len(m.bars)-1 {
 					m.seekOffset += timePerBar
 				}
 			}
 		case "up":
 			// Transpose up one semitone
+			m.spelledKey = ""
 			if m.player != nil {
 				m.player.Transpose(1)
 				m.transposeOffset = m.player.GetTranspose()
@@ -250,6 +303,7 @@ func (m *TUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.updateTransposedScale()
 		case "down":
 			// Transpose down one semitone
+			m.spelledKey = ""
 			if m.player != nil {
 				m.player.Transpose(-1)
 				m.transposeOffset = m.player.GetTranspose()
@@ -257,6 +311,16 @@ func (m *TUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.transposeOffset--
 			}
 			m.updateTransposedScale()
+		case "=", "+":
+			// Transpose display up one semitone (visual only, no audio transpose)
+			m.spelledKey = ""
+			m.visualTranspose++
+			m.updateTransposedScale()
+		case "-", "_":
+			// Transpose display down one semitone (visual only, no audio transpose)
+			m.spelledKey = ""
+			m.visualTranspose--
+			m.updateTransposedScale()
 		case "1":
 			// Toggle drums
 			if m.player != nil {
@@ -282,6 +346,16 @@ func (m *TUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.player != nil {
 				m.player.ToggleTrackMute(4)
 			}
+		case "6":
+			// Toggle pad
+			if m.player != nil {
+				m.player.ToggleTrackMute(5)
+			}
+		case "alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6":
+			// Solo drums/bass/chords/melody/fingerstyle/pad (same order as the mute keys)
+			if m.player != nil {
+				m.player.ToggleTrackSolo(int(msg.String()[4] - '1'))
+			}
 		case "[":
 			// Move capo down (with audio transpose)
 			if m.capoPosition > 0 {
@@ -373,6 +447,32 @@ func (m *TUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.player != nil {
 				m.player.AdjustTempo(-5)
 			}
+		case "T":
+			// Tap tempo (Shift+T): set the tempo from the last few taps
+			if m.player != nil {
+				if bpm, ok := m.tapTempo.tap(time.Now()); ok {
+					m.player.SetEffectiveTempo(bpm)
+				}
+			}
+		case "s":
+			// Toggle swing (regenerates the parts from the current bar)
+			if m.player != nil {
+				m.player.ToggleSwing()
+			}
+		case "i":
+			// Mark the current bar as the loop's in point (again to unmark)
+			if m.player != nil {
+				if m.loopIn == m.currentBar {
+					m.loopIn = -1
+				} else {
+					m.loopIn = m.currentBar
+				}
+			}
+		case "o":
+			// Mark the current bar as the loop's out point and loop from the in point
+			if m.player != nil {
+				m.setLoopOut()
+			}
 		case ")":
 			// Loop current section (Shift+0)
 			if m.player != nil {
@@ -383,6 +483,37 @@ func (m *TUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.player != nil && m.player.HasLyrics() {
 				m.lyricsEnabled = !m.lyricsEnabled
 			}
+		case "c":
+			// Show the current chord's next voicing in the chord chart
+			m.nextVoicing()
+		case "d":
+			// Toggle scale degree labels on the fretboard
+			m.showDegrees = !m.showDegrees
+		case "n":
+			// Toggle Nashville numbers in the chord chart
+			m.toggleChordNames(chordNashville)
+		case "N":
+			// Toggle Roman numerals in the chord chart
+			m.toggleChordNames(chordRoman)
+		case "K":
+			// Prompt for a key to transpose to
+			prompt := ""
+			m.keyPrompt = &prompt
+		case "?":
+			// Show the keybinding overlay
+			m.showHelp = true
+		case "r":
+			// Enter repeat mode for the active loop
+			if m.player != nil {
+				if enabled, _, _, _ := m.player.GetLoop(); enabled {
+					m.repeatMode = true
+				}
+			}
+		case "v":
+			// Enter volume mode (mixer)
+			if m.player != nil {
+				m.volumeMode = true
+			}
 		case "t":
 			// Toggle tablature display
 			if m.tablature != nil {
@@ -414,7 +545,16 @@ func (m *TUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 
+	case WatchErrorMsg:
+		m.watchError = msg.Err.Error()
+
 	case TickMsg:
+		if m.countdown {
+			if time.Since(m.countdownBegan) >= m.countdownLength {
+				m.endCountdown()
+			}
+			return m, tickCmd()
+		}
 		if m.playing {
 			// Always update when we have a player (it controls pause state)
 			// Otherwise check local pause state
@@ -428,6 +568,58 @@ func (m *TUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// volumeStep is how much one -/+ press changes a track's volume
+const volumeStep = 8
+
+// handleVolumeKey handles a key press in volume mode, returning false for keys
+// that should fall through to the normal bindings
+func (m *TUIModel) handleVolumeKey(key string) bool {
+	switch key {
+	case "v", "esc":
+		m.volumeMode = false
+	case "1", "2", "3", "4", "5", "6":
+		m.volumeTrack = int(key[0] - '1')
+	case "=", "+":
+		m.player.SetTrackVolume(m.volumeTrack, m.player.GetTrackVolume(m.volumeTrack)+volumeStep)
+	case "-", "_":
+		m.player.SetTrackVolume(m.volumeTrack, m.player.GetTrackVolume(m.volumeTrack)-volumeStep)
+	default:
+		return false
+	}
+	return true
+}
+
+// handleRepeatKey handles a key press in repeat mode: 1-9 plays the active loop
+// that many times in all (counting this pass) before the song continues, 0 loops
+// forever. Keys other than digits, r and Esc fall through to the normal bindings.
+func (m *TUIModel) handleRepeatKey(key string) bool {
+	switch key {
+	case "r", "esc":
+	case "0", "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		m.player.SetLoopRepeats(int(key[0] - '0'))
+	default:
+		return false
+	}
+	m.repeatMode = false
+	return true
+}
+
+// setLoopOut loops from the in point (set with i) through the current bar; in
+// and out can be marked in either order. Without an in point it moves the end of
+// the active loop to the current bar.
+func (m *TUIModel) setLoopOut() {
+	in := m.loopIn
+	if in < 0 {
+		enabled, startBar, _, _ := m.player.GetLoop()
+		if !enabled {
+			return
+		}
+		in = startBar
+	}
+	m.player.SetLoopPoints(min(in, m.currentBar), max(in, m.currentBar)+1)
+	m.loopIn = -1
+}
+
 // updatePosition calculates current bar/beat from elapsed time
 func (m *TUIModel) updatePosition() {
 	// If we have a player, sync from it
@@ -448,15 +640,12 @@ func (m *TUIModel) updatePosition() {
 		m.seekOffset = m.pausedTotal - time.Since(m.startTime)
 	}
 	totalBeats := int(elapsed / m.timePerBeat)
-	m.currentBeat = totalBeats % 4
-	m.currentBar = totalBeats / 4
+	m.currentBeat = totalBeats % m.beatsPerBar
+	m.currentBar = totalBeats / m.beatsPerBar
 
-	// Calculate strum position (8 or 16 strums per bar)
-	strumsPerBar := 8
-	if m.isSixteenthNoteStyle() {
-		strumsPerBar = 16
-	}
-	timePerStrum := m.timePerBeat * 4 / time.Duration(strumsPerBar)
+	// Calculate strum position (8 or 16 strums per bar, one per beat in odd meters)
+	strumsPerBar := len(m.strumSteps)
+	timePerStrum := m.timePerBeat * time.Duration(m.beatsPerBar) / time.Duration(strumsPerBar)
 	totalStrums := int(elapsed / timePerStrum)
 	m.currentStrum = totalStrums % strumsPerBar
 
@@ -471,11 +660,19 @@ func (m *TUIModel) View() string {
 	if m.quitting {
 		return ""
 	}
+	if m.countdown {
+		return m.renderCountdown()
+	}
+	if m.showHelp {
+		return m.renderHelp()
+	}
 
 	var b strings.Builder
 
 	// Header
 	b.WriteString(m.renderHeader())
+	b.WriteString("\n")
+	b.WriteString(m.renderNextChord())
 	b.WriteString("\n\n")
 
 	// Three-column layout
@@ -500,6 +697,15 @@ func (m *TUIModel) View() string {
 		b.WriteString("\n\n")
 	}
 
+	// Reload error in watch mode (playback continues with the last good file)
+	if m.watchError != "" {
+		b.WriteString(lipgloss.NewStyle().
+			Bold(true).
+			Foreground(theme.Warning).
+			Render("  ⚠ Reload failed: " + m.watchError))
+		b.WriteString("\n\n")
+	}
+
 	// Progress bar
 	b.WriteString(m.renderProgressBar())
 
@@ -511,16 +717,17 @@ func (m *TUIModel) renderHeader() string {
 	title := titleStyle.Render(m.track.Info.Title)
 
 	// Show transposed key if transpose is active
-	displayKey := m.track.Info.Key
-	if m.transposeOffset != 0 {
-		displayKey = transposeChord(m.track.Info.Key, m.transposeOffset)
+	displayKey := m.transposeForDisplay(m.track.Info.Key)
+	if m.spelledKey != "" {
+		displayKey = m.spelledKey
 	}
 
 	// Get effective tempo (may differ from original if speed adjusted)
 	displayTempo := m.track.Info.Tempo
 	tempoOffset := 0
 	if m.player != nil {
-		displayTempo, tempoOffset = m.player.GetTempo()
+		_, tempoOffset = m.player.GetTempo()
+		displayTempo = m.player.GetTempoAt(m.currentBar, m.currentBeat)
 	}
 
 	// Format BPM display - show offset if tempo was changed
@@ -533,6 +740,24 @@ func (m *TUIModel) renderHeader() string {
 		bpmDisplay = fmt.Sprintf("%d BPM (%s%d)", displayTempo, sign, tempoOffset)
 	}
 
+	// Speed trainer: show the target tempo the loop is working up to
+	if m.player != nil {
+		if step, maxBPM := m.player.GetTrainer(); step > 0 && maxBPM > 0 {
+			bpmDisplay += fmt.Sprintf(" → %d", maxBPM)
+		} else if step > 0 {
+			bpmDisplay += fmt.Sprintf(" (+%d/loop)", step)
+		}
+	}
+
+	// Swing toggled live: show which way
+	if m.player != nil {
+		if swung, toggled := m.player.GetSwing(); toggled && swung {
+			bpmDisplay += " swing"
+		} else if toggled {
+			bpmDisplay += " straight"
+		}
+	}
+
 	info := headerStyle.Render(fmt.Sprintf("%s | %s | %s",
 		displayKey, bpmDisplay, m.track.Info.Style))
 
@@ -541,7 +766,7 @@ func (m *TUIModel) renderHeader() string {
 	if m.capoPosition > 0 {
 		capoIndicator = lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("#00CCCC")).
+			Foreground(theme.Info).
 			Render(fmt.Sprintf("  [Capo %d]", m.capoPosition))
 	}
 
@@ -554,16 +779,30 @@ func (m *TUIModel) renderHeader() string {
 		}
 		transposeIndicator = lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("#FF00FF")).
+			Foreground(theme.Highlight).
 			Render(fmt.Sprintf("  [%s%d]", sign, m.transposeOffset))
 	}
+	if m.visualTranspose != 0 {
+		sign := "+"
+		if m.visualTranspose < 0 {
+			sign = ""
+		}
+		transposeIndicator += theme.style(theme.Highlight).
+			Render(fmt.Sprintf("  [view %s%d]", sign, m.visualTranspose))
+	}
+	if m.keyPrompt != nil {
+		transposeIndicator += lipgloss.NewStyle().
+			Bold(true).
+			Foreground(theme.Attention).
+			Render(fmt.Sprintf("  [To key: %s_  Enter/Esc]", *m.keyPrompt))
+	}
 
 	// Show track mute status
 	muteIndicator := ""
 	if m.player != nil {
-		trackNames := []string{"Dr", "Ba", "Ch", "Me", "Fi"}
+		trackNames := []string{"Dr", "Ba", "Ch", "Me", "Fi", "Pa"}
 		var mutedTracks []string
-		for i := 0; i < 5; i++ {
+		for i := 0; i < len(trackNames); i++ {
 			if m.player.IsTrackMuted(i) {
 				mutedTracks = append(mutedTracks, trackNames[i])
 			}
@@ -571,9 +810,29 @@ func (m *TUIModel) renderHeader() string {
 		if len(mutedTracks) > 0 {
 			muteIndicator = lipgloss.NewStyle().
 				Bold(true).
-				Foreground(lipgloss.Color("#FF6666")).
+				Foreground(theme.Warning).
 				Render(fmt.Sprintf("  [MUTE: %s]", strings.Join(mutedTracks, ",")))
 		}
+
+		var soloedTracks []string
+		for i := 0; i < len(trackNames); i++ {
+			if m.player.IsTrackSoloed(i) {
+				soloedTracks = append(soloedTracks, trackNames[i])
+			}
+		}
+		if len(soloedTracks) > 0 {
+			muteIndicator += lipgloss.NewStyle().
+				Bold(true).
+				Foreground(theme.Positive).
+				Render(fmt.Sprintf("  [SOLO: %s]", strings.Join(soloedTracks, ",")))
+		}
+
+		if m.volumeMode {
+			muteIndicator += lipgloss.NewStyle().
+				Bold(true).
+				Foreground(theme.Attention).
+				Render(fmt.Sprintf("  [VOL %s: %d]", trackNames[m.volumeTrack], m.player.GetTrackVolume(m.volumeTrack)))
+		}
 	}
 
 	scaleName := ""
@@ -586,7 +845,7 @@ func (m *TUIModel) renderHeader() string {
 	if m.tuningName != "" && m.tuningName != "standard" {
 		tuningIndicator = lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("#66FF66")).
+			Foreground(theme.Positive).
 			Render(fmt.Sprintf("  [%s]", m.tuningName))
 	}
 
@@ -596,7 +855,7 @@ func (m *TUIModel) renderHeader() string {
 		if name, _, _ := m.player.GetCurrentSection(); name != "" {
 			sectionIndicator = lipgloss.NewStyle().
 				Bold(true).
-				Foreground(lipgloss.Color("#FFAA00")).
+				Foreground(theme.Attention).
 				Render(fmt.Sprintf("  § %s", name))
 		}
 	}
@@ -605,21 +864,45 @@ func (m *TUIModel) renderHeader() string {
 	if m.paused || (m.player != nil && m.player.IsPaused()) {
 		pauseIndicator = lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("#FF6600")).
+			Foreground(theme.Attention).
 			Render("  ⏸ PAUSED")
 	}
 
+	numbersIndicator := ""
+	switch m.chordNames {
+	case chordNashville:
+		numbersIndicator = lipgloss.NewStyle().Bold(true).Foreground(theme.Info).Render("  [Nashville]")
+	case chordRoman:
+		numbersIndicator = lipgloss.NewStyle().Bold(true).Foreground(theme.Info).Render("  [Roman]")
+	}
+
 	loopIndicator := ""
 	if m.player != nil {
 		if enabled, startBar, endBar, _ := m.player.GetLoop(); enabled {
+			repeats := ""
+			if passes := m.player.GetLoopRepeats(); passes > 0 {
+				repeats = fmt.Sprintf(" (%dx left)", passes-1)
+			}
 			loopIndicator = lipgloss.NewStyle().
 				Bold(true).
-				Foreground(lipgloss.Color("#FF00FF")).
-				Render(fmt.Sprintf("  🔁 LOOP %d-%d", startBar+1, endBar))
+				Foreground(theme.Highlight).
+				Render(fmt.Sprintf("  🔁 LOOP %d-%d%s", startBar+1, endBar, repeats))
+		}
+		if m.loopIn >= 0 {
+			loopIndicator += lipgloss.NewStyle().
+				Bold(true).
+				Foreground(theme.Attention).
+				Render(fmt.Sprintf("  [IN %d: o at the last bar]", m.loopIn+1))
+		}
+		if m.repeatMode {
+			loopIndicator += lipgloss.NewStyle().
+				Bold(true).
+				Foreground(theme.Attention).
+				Render("  [REPEAT: 1-9 times, 0 = forever]")
 		}
 	}
 
-	return fmt.Sprintf("  %s    %s%s%s%s%s%s%s%s%s", title, info, sectionIndicator, capoIndicator, transposeIndicator, tuningIndicator, muteIndicator, scaleName, loopIndicator, pauseIndicator)
+	return fmt.Sprintf("  %s    %s%s%s%s%s%s%s%s%s%s", title, info, sectionIndicator, capoIndicator, transposeIndicator, tuningIndicator, numbersIndicator, muteIndicator, scaleName, loopIndicator, pauseIndicator)
 }
 
 // renderLeftColumn renders the chord/beat display
@@ -683,14 +966,19 @@ func (m *TUIModel) renderBarRow(startBar int) string {
 				if lyrics != "" {
 					hasAnyLyrics = true
 				}
+				if barIdx == m.currentBar && lyrics != "" {
+					// Karaoke cursor, syllable by syllable when the bar is timed
+					var syllables []parser.LyricSyllable
+					if lyrics == m.bars[barIdx].Lyrics {
+						syllables = m.bars[barIdx].Syllables
+					}
+					lyricsLine += m.renderKaraoke(lyrics, syllables, barWidth)
+					continue
+				}
 				if len(lyrics) > barWidth-2 {
 					lyrics = lyrics[:barWidth-2]
 				}
-				style := lyricsStyle.Width(barWidth)
-				if barIdx == m.currentBar && lyrics != "" {
-					style = style.Bold(true)
-				}
-				lyricsLine += style.Render(lyrics)
+				lyricsLine += lyricsStyle.Width(barWidth).Render(lyrics)
 			}
 		}
 		if hasAnyLyrics {
@@ -726,101 +1014,88 @@ func (m *TUIModel) renderBarRow(startBar int) string {
 	return strings.Join(lines, "\n")
 }
 
-// getBarChordName returns the chord name(s) for a bar (with transpose applied)
+// getBarChordName returns the chord name(s) for a bar (with transpose applied,
+// or as numbers in the key when a number mode is on)
 func (m *TUIModel) getBarChordName(barIdx int) string {
 	if barIdx >= len(m.bars) || len(m.bars[barIdx].Chords) == 0 {
 		return ""
 	}
 	bar := m.bars[barIdx]
-	if len(bar.Chords) == 1 {
-		if m.transposeOffset != 0 {
-			return transposeChord(bar.Chords[0].Symbol, m.transposeOffset)
-		}
-		return bar.Chords[0].Symbol
-	}
-	// Multiple chords in this bar - show all (transposed)
 	var names []string
 	for _, bc := range bar.Chords {
-		name := bc.Symbol
-		if m.transposeOffset != 0 {
-			name = transposeChord(name, m.transposeOffset)
-		}
-		names = append(names, name)
+		names = append(names, m.chordName(bc.Symbol))
 	}
+	// Multiple chords in this bar - show all
 	return strings.Join(names, " → ")
 }
 
-// renderStrumPattern renders the strum pattern for a bar
+// chordName returns a chord as shown in the chart: the transposed symbol, or its
+// Nashville number or Roman numeral (the same in every key, so not transposed)
+func (m *TUIModel) chordName(symbol string) string {
+	switch m.chordNames {
+	case chordNashville:
+		return theory.NashvilleNumber(symbol, m.track.Info.Key)
+	case chordRoman:
+		return theory.RomanNumeral(symbol, m.track.Info.Key)
+	}
+	return m.transposeForDisplay(symbol)
+}
+
+// toggleChordNames switches the chord chart to a number mode, or back to symbols
+// if that mode is already on
+func (m *TUIModel) toggleChordNames(mode chordNameMode) {
+	if m.chordNames == mode {
+		m.chordNames = chordSymbols
+	} else {
+		m.chordNames = mode
+	}
+}
+
+// strumAccentVelocity is the velocity from which a strum is shown accented
+const strumAccentVelocity = 80
+
+// renderStrumPattern renders the strum pattern for a bar, as the chord part plays it
 func (m *TUIModel) renderStrumPattern(isCurrent bool) string {
-	pattern := m.getStrumPatternSymbols()
 	var result []string
 
 	// Use narrower spacing for 16th notes
 	spacing := "   "
-	if len(pattern) > 8 {
+	if len(m.strumSteps) > 8 {
 		spacing = " "
 	}
 
-	for i, p := range pattern {
+	for i, step := range m.strumSteps {
+		// Accented strums stand out in bold
+		style := beatStyle
+		if step.Velocity >= strumAccentVelocity {
+			style = style.Bold(true)
+		}
 		if isCurrent {
 			if i == m.currentStrum {
 				result = append(result, currentBeatStyle.Render("█"))
 			} else if i < m.currentStrum {
-				result = append(result, beatStyle.Render(p))
+				result = append(result, style.Render(step.Symbol))
 			} else {
 				result = append(result, beatStyle.Render("░"))
 			}
 		} else {
-			result = append(result, beatStyle.Render(p))
+			result = append(result, style.Render(step.Symbol))
 		}
 	}
 
 	return " " + strings.Join(result, spacing)
 }
 
-// getStrumPatternSymbols returns the strum pattern as symbols
-func (m *TUIModel) getStrumPatternSymbols() []string {
-	if m.track.Rhythm == nil {
-		return []string{"↓", ".", "↓", ".", "↓", ".", "↓", "."}
-	}
-
-	switch m.track.Rhythm.Style {
-	case "fingerpick_slow":
-		return []string{"↓", ".", ".", ".", "↓", ".", ".", "."}
-	case "fingerpick", "travis":
-		return []string{"↓", ".", "↑", ".", "↓", ".", "↑", "."}
-	case "arpeggio_up", "arpeggio_down":
-		return []string{"↓", "↓", "↓", "↓", "↓", "↓", "↓", "↓"}
-	case "sixteenth":
-		return []string{"↓", ".", "↑", ".", "↓", ".", "↑", ".", "↓", ".", "↑", ".", "↓", ".", "↑", "."}
-	case "funk_16th":
-		return []string{"↓", ".", "x", ".", "↑", "x", "↓", ".", "x", ".", "↑", ".", "↓", "x", "↑", "."}
-	case "funk_muted":
-		return []string{"x", ".", "↓", ".", "x", ".", "↑", ".", "x", ".", "↓", ".", "x", ".", "↑", "."}
-	case "ska", "skank":
-		return []string{".", "↓", ".", "↓", ".", "↓", ".", "↓"}
-	case "reggae", "one_drop":
-		return []string{".", ".", ".", ".", "↓", ".", ".", "."}
-	case "country", "train":
-		return []string{"↓", ".", "↓", ".", "↓", ".", "↓", "."}
-	case "disco":
-		return []string{"↓", ".", "↓", ".", "↓", ".", "↓", "."}
-	case "motown", "soul":
-		return []string{"↓", ".", "↓", "↑", "↓", ".", "↓", "↑"}
-	case "flamenco", "rumba":
-		return []string{"↓", ".", ".", "↓", ".", ".", "↓", ".", "↓", ".", "↓", ".", "↓", ".", ".", "."}
-	default:
-		return []string{"↓", ".", "↑", ".", "↓", ".", "↑", "."}
-	}
-}
-
 // renderBeatNumbers renders the beat numbers
 func (m *TUIModel) renderBeatNumbers(isCurrent bool) string {
-	if m.isSixteenthNoteStyle() {
+	if m.isSixteenthNoteStyle() && m.beatsPerBar == 4 {
 		return m.renderBeatNumbers16th(isCurrent)
 	}
 
-	beats := []string{"1", "2", "3", "4"}
+	beats := make([]string, m.beatsPerBar)
+	for i := range beats {
+		beats[i] = fmt.Sprintf("%d", i+1)
+	}
 	var result []string
 
 	for i, b := range beats {
@@ -833,7 +1108,14 @@ func (m *TUIModel) renderBeatNumbers(isCurrent bool) string {
 		}
 	}
 
-	return " " + strings.Join(result, "       ")
+	// Spread the beats under the strum pattern (7 spaces between beats in 4/4)
+	spacing := 7
+	if m.beatsPerBar > 8 {
+		spacing = 1
+	} else if m.beatsPerBar != 4 {
+		spacing = 3
+	}
+	return " " + strings.Join(result, strings.Repeat(" ", spacing))
 }
 
 // renderBeatNumbers16th renders beat numbers for 16th note patterns
@@ -878,16 +1160,16 @@ func (m *TUIModel) renderMiddleColumn() string {
 
 	// Fret numbers (use 3-char columns for proper alignment with double digits)
 	// Highlight the capo position
-	fretLine := "   "
-	for fret := 0; fret <= 12; fret++ {
+	fretLine := ""
+	for _, fret := range m.fretColumns() {
 		if fret == m.capoPosition && m.capoPosition > 0 {
 			// Highlight capo position
-			fretLine += lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00CCCC")).Render(fmt.Sprintf("%2d ", fret))
+			fretLine += lipgloss.NewStyle().Bold(true).Foreground(theme.Info).Render(fmt.Sprintf("%2d ", fret))
 		} else {
 			fretLine += fmt.Sprintf("%2d ", fret)
 		}
 	}
-	lines = append(lines, fretLine)
+	lines = append(lines, m.fretboardRow("", fretLine))
 
 	// Strings (high to low) - use capo-adjusted tuning for positions
 	tuning := m.getCapoAdjustedTuning()
@@ -900,14 +1182,17 @@ func (m *TUIModel) renderMiddleColumn() string {
 	for idx := 0; idx < numStrings; idx++ {
 		stringIdx := numStrings - 1 - idx // Reverse order (high to low)
 		name := tuning.Names[stringIdx]
-		// Pad name for alignment
-		if len(name) == 1 {
-			name = " " + name
-		}
-		line := fmt.Sprintf("%s ", name)
-
-		for fret := 0; fret <= 12; fret++ {
-			if roots[stringIdx][fret] {
+		line := ""
+		for _, fret := range m.fretColumns() {
+			if m.showDegrees && positions[stringIdx][fret] {
+				// Degree labels use the same 3-char column as the fret numbers
+				label := fmt.Sprintf("%2s ", m.currentScale.DegreeName(tuning.Notes[stringIdx]+fret))
+				if roots[stringIdx][fret] {
+					line += lipgloss.NewStyle().Bold(true).Foreground(rootColor).Render(label)
+				} else {
+					line += lipgloss.NewStyle().Foreground(accentColor).Render(label)
+				}
+			} else if roots[stringIdx][fret] {
 				line += lipgloss.NewStyle().Foreground(rootColor).Render(" ◆ ")
 			} else if positions[stringIdx][fret] {
 				line += lipgloss.NewStyle().Foreground(accentColor).Render(" ● ")
@@ -915,21 +1200,10 @@ func (m *TUIModel) renderMiddleColumn() string {
 				line += " · "
 			}
 		}
-		lines = append(lines, line)
+		lines = append(lines, m.fretboardRow(name, line))
 	}
 
-	// Fret markers
-	markerLine := "   "
-	for fret := 0; fret <= 12; fret++ {
-		if fret == 3 || fret == 5 || fret == 7 || fret == 9 {
-			markerLine += " · "
-		} else if fret == 12 {
-			markerLine += " : "
-		} else {
-			markerLine += "   "
-		}
-	}
-	lines = append(lines, markerLine)
+	lines = append(lines, m.fretMarkerLine())
 
 	// Add chord tones fretboard
 	chordLines := m.renderChordTonesFretboard()
@@ -978,41 +1252,70 @@ func (m *TUIModel) renderChordTonesFretboard() []string {
 	numStrings := len(tuning.Notes)
 
 	// Fret numbers
-	fretLine := "   "
-	for fret := 0; fret <= 12; fret++ {
+	fretLine := ""
+	for _, fret := range m.fretColumns() {
 		fretLine += fmt.Sprintf("%2d ", fret)
 	}
-	lines = append(lines, fretLine)
+	lines = append(lines, m.fretboardRow("", fretLine))
 
 	// Strings (high to low for display)
 	for idx := 0; idx < numStrings; idx++ {
 		stringIdx := numStrings - 1 - idx // Reverse to match display order
 		openNote := tuning.Notes[stringIdx]
 		name := tuning.Names[stringIdx]
-		// Pad name for alignment
-		if len(name) == 1 {
-			name = " " + name
-		}
-		line := fmt.Sprintf("%s ", name)
-
-		for fret := 0; fret <= 12; fret++ {
+		line := ""
+		for _, fret := range m.fretColumns() {
 			noteAtFret := (openNote + fret) % 12
 			if noteAtFret == rootTone {
 				// Root note - highlight in different color
 				line += lipgloss.NewStyle().Foreground(rootColor).Render(" ◆ ")
 			} else if toneMap[noteAtFret] {
 				// Chord tone
-				line += lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(" ● ") // Orange for chord tones
+				line += theme.style(theme.ChordTone).Render(" ● ") // Orange for chord tones
 			} else {
 				line += " · "
 			}
 		}
-		lines = append(lines, line)
+		lines = append(lines, m.fretboardRow(name, line))
 	}
 
-	// Fret markers
-	markerLine := "   "
-	for fret := 0; fret <= 12; fret++ {
+	lines = append(lines, m.fretMarkerLine())
+
+	return lines
+}
+
+// fretColumns returns frets 0-12 in display order: nut on the left, or on the
+// right for a left-handed player
+func (m *TUIModel) fretColumns() []int {
+	frets := make([]int, 13)
+	for i := range frets {
+		frets[i] = i
+		if m.track != nil && m.track.Info.LeftHanded {
+			frets[i] = 12 - i
+		}
+	}
+	return frets
+}
+
+// fretboardRow puts a string name (or blank space, for header rows) beside a row
+// of 3-char fret cells, on the nut side
+func (m *TUIModel) fretboardRow(name, cells string) string {
+	if len(name) == 1 {
+		name = " " + name // Pad name for alignment
+	}
+	if name == "" {
+		name = "  "
+	}
+	if m.track != nil && m.track.Info.LeftHanded {
+		return cells + name
+	}
+	return name + " " + cells
+}
+
+// fretMarkerLine returns the inlay markers (3, 5, 7, 9 and the double dot at 12)
+func (m *TUIModel) fretMarkerLine() string {
+	markerLine := ""
+	for _, fret := range m.fretColumns() {
 		if fret == 3 || fret == 5 || fret == 7 || fret == 9 {
 			markerLine += " · "
 		} else if fret == 12 {
@@ -1021,9 +1324,17 @@ func (m *TUIModel) renderChordTonesFretboard() []string {
 			markerLine += "   "
 		}
 	}
-	lines = append(lines, markerLine)
+	return m.fretboardRow("", markerLine)
+}
 
-	return lines
+// diagramStrings returns the six chord diagram strings in display order: low E
+// on the left, or on the right for a left-handed player
+func (m *TUIModel) diagramStrings() []int {
+	strs := []int{0, 1, 2, 3, 4, 5}
+	if m.track != nil && m.track.Info.LeftHanded {
+		strs = []int{5, 4, 3, 2, 1, 0}
+	}
+	return strs
 }
 
 // getCurrentChordSymbol returns the chord symbol for the current beat position (transposed)
@@ -1050,71 +1361,125 @@ func (m *TUIModel) getCurrentChordSymbol() string {
 	}
 
 	// Apply transpose
-	if m.transposeOffset != 0 {
-		return transposeChord(symbol, m.transposeOffset)
+	return m.transposeForDisplay(symbol)
+}
+
+// nextChordChange returns the next chord that differs from the current one and
+// how many beats away it starts (ok is false when the current chord is the last)
+func (m *TUIModel) nextChordChange() (symbol string, beats int, ok bool) {
+	if m.currentBar >= len(m.bars) || len(m.bars[m.currentBar].Chords) == 0 {
+		return "", 0, false
 	}
-	return symbol
+
+	current := m.bars[m.currentBar].Chords[0].Symbol
+	for _, chord := range m.bars[m.currentBar].Chords {
+		if m.currentBeat >= chord.StartBeat {
+			current = chord.Symbol
+		}
+	}
+
+	now := m.currentBar*m.beatsPerBar + m.currentBeat
+	for barIdx := m.currentBar; barIdx < len(m.bars); barIdx++ {
+		for _, chord := range m.bars[barIdx].Chords {
+			start := barIdx*m.beatsPerBar + chord.StartBeat
+			if start > now && chord.Symbol != current {
+				return chord.Symbol, start - now, true
+			}
+		}
+	}
+	return "", 0, false
 }
 
-// transposeChord transposes a chord symbol by the given number of semitones
-func transposeChord(symbol string, semitones int) string {
-	if symbol == "" {
-		return ""
+// renderNextChord renders the "next change" readout under the header
+func (m *TUIModel) renderNextChord() string {
+	symbol, beats, ok := m.nextChordChange()
+	if !ok {
+		return headerStyle.Render("  Next: —")
 	}
 
-	// Note names in order
-	noteNames := []string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
-	flatNames := []string{"C", "Db", "D", "Eb", "E", "F", "Gb", "G", "Ab", "A", "Bb", "B"}
+	unit := "beats"
+	if beats == 1 {
+		unit = "beat"
+	}
+	next := lipgloss.NewStyle().Bold(true).Foreground(secondaryColor).Render(m.chordName(symbol))
+	countdown := headerStyle.Render(fmt.Sprintf("in %d %s", beats, unit))
+	if beats <= m.beatsPerBar {
+		countdown = currentBeatStyle.Render(fmt.Sprintf("in %d %s", beats, unit))
+	}
+	return fmt.Sprintf("  %s %s %s", headerStyle.Render("Next:"), next, countdown)
+}
 
-	// Parse the root note
-	var root string
-	var remainder string
-	useFlats := false
+// displayTranspose returns the semitone offset used for displayed chords and scales
+// (audio transpose plus display-only transpose)
+func (m *TUIModel) displayTranspose() int {
+	return m.transposeOffset + m.visualTranspose
+}
 
-	if len(symbol) >= 2 && (symbol[1] == '#' || symbol[1] == 'b') {
-		root = symbol[:2]
-		remainder = symbol[2:]
-		useFlats = symbol[1] == 'b'
-	} else {
-		root = symbol[:1]
-		remainder = symbol[1:]
+// transposeForDisplay applies the display transpose to a chord symbol, spelled
+// for the key chosen at the K prompt if there is one
+func (m *TUIModel) transposeForDisplay(symbol string) string {
+	offset := m.displayTranspose()
+	if m.spelledKey != "" {
+		return theory.TransposeChordInKey(symbol, offset, m.spelledKey)
+	}
+	if offset != 0 {
+		return theory.TransposeChord(symbol, offset)
 	}
+	return symbol
+}
 
-	// Find root index
-	rootUpper := strings.ToUpper(root)
-	rootIdx := -1
-	for i, n := range noteNames {
-		if n == rootUpper || flatNames[i] == rootUpper {
-			rootIdx = i
-			break
+// handleKeyPromptKey edits the K prompt: Enter transposes to the typed key, Esc
+// cancels, Backspace deletes
+func (m *TUIModel) handleKeyPromptKey(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.transposeToKey(*m.keyPrompt)
+		m.keyPrompt = nil
+	case tea.KeyEsc:
+		m.keyPrompt = nil
+	case tea.KeyBackspace:
+		if n := len(*m.keyPrompt); n > 0 {
+			*m.keyPrompt = (*m.keyPrompt)[:n-1]
+		}
+	case tea.KeyRunes:
+		if len(*m.keyPrompt) < 4 {
+			*m.keyPrompt += string(msg.Runes)
 		}
 	}
-	if rootIdx == -1 {
-		return symbol // Can't transpose, return as-is
-	}
+}
 
-	// Transpose
-	newIdx := (rootIdx + semitones%12 + 12) % 12
+// transposeToKey transposes playback and display by the shortest shift from the
+// track's key to the typed key (keeping major/minor); unknown keys are ignored
+func (m *TUIModel) transposeToKey(typed string) {
+	name, ok := theory.ParseKeyName(typed)
+	if !ok {
+		return
+	}
+	name = strings.TrimSuffix(name, "m")
+	if _, isMinor := theory.ParseKey(m.track.Info.Key); isMinor {
+		name += "m"
+	}
 
-	// Get new root name
-	var newRoot string
-	if useFlats {
-		newRoot = flatNames[newIdx]
+	offset := theory.SemitonesToKey(m.track.Info.Key, name)
+	if m.player != nil {
+		m.player.Transpose(offset - m.player.GetTranspose())
+		m.transposeOffset = m.player.GetTranspose()
 	} else {
-		newRoot = noteNames[newIdx]
+		m.transposeOffset = offset
 	}
-
-	return newRoot + remainder
+	m.visualTranspose = 0
+	m.spelledKey = name
+	m.updateTransposedScale()
 }
 
 // updateTransposedScale updates the scale display when transpose changes
 func (m *TUIModel) updateTransposedScale() {
 	// Get the transposed key
 	originalKey := m.track.Info.Key
-	transposedKey := transposeChord(originalKey, m.transposeOffset)
+	transposedKey := m.transposeForDisplay(originalKey)
 
 	// Update the scale
-	m.currentScale = theory.GetScaleForStyle(transposedKey, m.track.Info.Style, "")
+	m.currentScale = theory.GetScaleWithOverride(transposedKey, m.track.Info.Style, "", m.track.ScaleName())
 }
 
 // getCapoAdjustedTuning returns the tuning with capo applied
@@ -1187,6 +1552,13 @@ func (m *TUIModel) renderRightColumn() string {
 		lines = append(lines, "")
 	}
 
+	// Chord diagrams are drawn for 6 strings; skip them for ukulele/mandolin/bass tunings
+	if len(m.tuning.Notes) < 6 {
+		lines = append(lines, theme.dimStyle(theme.Dim).
+			Render(fmt.Sprintf(" No chord diagrams for %d-string tunings", len(m.tuning.Notes))))
+		return strings.Join(lines, "\n")
+	}
+
 	// Chord charts for unique chords - 3 per row
 	uniqueChords := m.getUniqueChords()
 	var allDiagrams [][]string
@@ -1199,10 +1571,7 @@ func (m *TUIModel) renderRightColumn() string {
 
 	for _, chord := range uniqueChords {
 		// First apply transpose to get the actual chord being played
-		transposedChord := chord
-		if m.transposeOffset != 0 {
-			transposedChord = transposeChord(chord, m.transposeOffset)
-		}
+		transposedChord := m.transposeForDisplay(chord)
 
 		// Check if this is the active chord
 		isActive := (chord == currentChord)
@@ -1212,16 +1581,30 @@ func (m *TUIModel) renderRightColumn() string {
 		displayChord := transposedChord
 		shapeChord := transposedChord
 		if m.capoPosition > 0 {
-			shapeChord = transposeChord(transposedChord, -m.capoPosition)
+			shapeChord = theory.TransposeChord(transposedChord, -m.capoPosition)
 			displayChord = fmt.Sprintf("%s→%s", transposedChord, shapeChord)
 		}
 
-		voicings := m.chordChart.GetVoicingsForTuning(shapeChord, m.tuningName)
+		voicings := m.chordChart.GetVoicingsForSkill(shapeChord, m.tuningName, m.track.Info.Skill)
 		if len(voicings) == 0 {
-			continue
+			// Sparse tables (open tunings, DADGAD) miss many capo shapes;
+			// generate one for the tuning rather than dropping the chord
+			voicings = m.generatedVoicing(theory.SimplifyChordForSkill(shapeChord, m.track.Info.Skill))
+		}
+		// Show the easier substitute a beginner should play
+		if easy := theory.SimplifyChordForSkill(shapeChord, m.track.Info.Skill); easy != shapeChord {
+			displayChord = fmt.Sprintf("%s→%s", displayChord, easy)
+		}
+		// Show the chosen voicing, numbered when it's not the only one
+		index := m.voicingIndex[chord] % len(voicings)
+		if len(voicings) > 1 && (isActive || index > 0) {
+			displayChord = fmt.Sprintf("%s (%d/%d)", displayChord, index+1, len(voicings))
+		}
+		if unavoidableBarre(voicings) {
+			displayChord += " (hard)"
 		}
 		// Override the name to show both original and shape
-		voicing := voicings[0]
+		voicing := voicings[index]
 		voicing.Name = displayChord
 		allDiagrams = append(allDiagrams, m.renderChordDiagram(voicing, isActive))
 	}
@@ -1268,16 +1651,9 @@ func (m *TUIModel) renderRightColumn() string {
 	return strings.Join(lines, "\n")
 }
 
-// isSixteenthNoteStyle checks if current style uses 16th notes
+// isSixteenthNoteStyle checks if the chord part is strummed on a 16th-note grid
 func (m *TUIModel) isSixteenthNoteStyle() bool {
-	if m.track.Rhythm == nil {
-		return false
-	}
-	switch m.track.Rhythm.Style {
-	case "sixteenth", "funk_16th", "funk_muted", "dust_in_wind", "landslide", "pima", "pima_reverse":
-		return true
-	}
-	return false
+	return len(m.strumSteps) == 16
 }
 
 // isFingerPickingStyle checks if current style is fingerpicking
@@ -1349,23 +1725,36 @@ func (m *TUIModel) getPickingPattern() []string {
 	}
 }
 
+// nextVoicing moves the current chord's diagram on to its next voicing (wrapping
+// around when the chord chart is drawn)
+func (m *TUIModel) nextVoicing() {
+	chord := m.getCurrentChordSymbol()
+	if idx := strings.Index(chord, "/"); idx > 0 {
+		chord = chord[:idx]
+	}
+	if chord == "" {
+		return
+	}
+	if m.voicingIndex == nil {
+		m.voicingIndex = make(map[string]int)
+	}
+	m.voicingIndex[chord]++
+}
+
 // getUniqueChords returns unique chord symbols from the song
 func (m *TUIModel) getUniqueChords() []string {
-	seen := make(map[string]bool)
-	var unique []string
-	for _, bar := range m.bars {
-		for _, bc := range bar.Chords {
-			symbol := bc.Symbol
-			if idx := strings.Index(symbol, "/"); idx > 0 {
-				symbol = symbol[:idx]
-			}
-			if !seen[symbol] {
-				seen[symbol] = true
-				unique = append(unique, symbol)
-			}
-		}
-	}
-	return unique
+	return uniqueChordSymbols(m.bars)
+}
+
+// generatedVoicing builds a diagram for a chord in the current tuning when the
+// chord chart has none (it may use fewer than three strings)
+func (m *TUIModel) generatedVoicing(symbol string) []ChordVoicing {
+	generated := theory.GenerateChordVoicing(symbol, m.tuning)
+	return []ChordVoicing{{
+		Name:     symbol,
+		Frets:    generated.Frets,
+		BaseFret: generated.BaseFret,
+	}}
 }
 
 // renderChordDiagram renders a single chord diagram
@@ -1385,7 +1774,7 @@ func (m *TUIModel) renderChordDiagram(v ChordVoicing, isActive bool) []string {
 	// Highlight active chord with color
 	nameStyle := lipgloss.NewStyle().Bold(true)
 	if isActive {
-		nameStyle = nameStyle.Foreground(lipgloss.Color("212")).Background(lipgloss.Color("236"))
+		nameStyle = nameStyle.Foreground(theme.Highlight).Background(theme.Selected).Reverse(theme.Mono)
 	}
 	lines = append(lines, nameStyle.Render(fmt.Sprintf(" %s [%s] ", v.Name, tabStr)))
 
@@ -1398,7 +1787,7 @@ func (m *TUIModel) renderChordDiagram(v ChordVoicing, isActive bool) []string {
 
 	// Open/muted string indicators (above the nut)
 	indicatorLine := " "
-	for str := 0; str < 6; str++ {
+	for _, str := range m.diagramStrings() {
 		f := v.Frets[str]
 		if f == -1 {
 			indicatorLine += "x  "
@@ -1420,10 +1809,10 @@ func (m *TUIModel) renderChordDiagram(v ChordVoicing, isActive bool) []string {
 	// Frets
 	for fret := startFret; fret <= endFret; fret++ {
 		line := " "
-		for str := 0; str < 6; str++ {
+		for _, str := range m.diagramStrings() {
 			f := v.Frets[str]
 			if f == fret {
-				line += "●  "
+				line += v.fretMark(str) + "  "
 			} else {
 				line += "│  "
 			}
@@ -1448,7 +1837,7 @@ func (m *TUIModel) renderProgressBar() string {
 	filled := int(progress * float64(width))
 	bar := strings.Repeat("▓", filled) + strings.Repeat("░", width-filled)
 
-	controls := headerStyle.Render("  [space] pause  [←/→] seek  [↑/↓] transpose  [Shift+↑/↓] tempo  [[/]] capo  [{/}] visual capo  [</>] tuning  [l] lyrics  [t] tab  [q] quit")
+	controls := headerStyle.Render("  [space] pause  [←/→] seek  [↑/↓] transpose  [K] to key  [-/=] visual transpose  [Shift+↑/↓] tempo  [T] tap tempo  [s] swing  [[/]] capo  [{/}] visual capo  [</>] tuning  [c] voicing  [d] degrees  [n/N] numbers  [i/o] loop in/out  [r] loop repeats  [v] volume  [l] lyrics  [t] tab  [?] help  [q] quit")
 
 	return fmt.Sprintf("  %s  %d%% (bar %d/%d)%s",
 		progressStyle.Render(bar),
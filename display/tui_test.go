@@ -0,0 +1,100 @@
+package display
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"backing-tracks/parser"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakePlayer records Transpose calls; the other PlayerController methods are not
+// expected to be called (the embedded nil interface panics if they are)
+type fakePlayer struct {
+	PlayerController
+	transposeCalls []int
+	transpose      int
+}
+
+func (p *fakePlayer) Transpose(semitones int) {
+	p.transposeCalls = append(p.transposeCalls, semitones)
+	p.transpose += semitones
+}
+
+func (p *fakePlayer) GetTranspose() int {
+	return p.transpose
+}
+
+// newTestTUI returns a TUI for a two-bar C, G song with a fake player
+func newTestTUI(t *testing.T) (*TUIModel, *fakePlayer) {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "track.btml")
+	source := `
+track:
+  title: Transpose
+  key: C
+  tempo: 120
+chord_progression:
+  pattern: "C G"
+`
+	if err := os.WriteFile(filename, []byte(source), 0644); err != nil {
+		t.Fatalf("writing track: %v", err)
+	}
+	track, err := parser.LoadTrack(filename)
+	if err != nil {
+		t.Fatalf("parsing track: %v", err)
+	}
+	m := NewTUIModel(track)
+	player := &fakePlayer{}
+	m.SetPlayer(player)
+	return m, player
+}
+
+// pressKey sends a key to the model as the terminal would
+func pressKey(m *TUIModel, key string) {
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	switch key {
+	case "up":
+		msg = tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		msg = tea.KeyMsg{Type: tea.KeyDown}
+	}
+	m.Update(msg)
+}
+
+func TestVisualTransposeLeavesAudio(t *testing.T) {
+	m, player := newTestTUI(t)
+	if got := m.getBarChordName(0); got != "C" {
+		t.Fatalf("bar 1 shows %q before transposing, want C", got)
+	}
+
+	pressKey(m, "=")
+	pressKey(m, "=")
+	if got := m.getBarChordName(0); got != "D" {
+		t.Errorf("bar 1 shows %q after two display-only steps up, want D", got)
+	}
+	if got := m.getBarChordName(1); got != "A" {
+		t.Errorf("bar 2 shows %q after two display-only steps up, want A", got)
+	}
+
+	pressKey(m, "-")
+	if got := m.getBarChordName(0); got != "C#" {
+		t.Errorf("bar 1 shows %q after a step back down, want C#", got)
+	}
+	if len(player.transposeCalls) != 0 {
+		t.Errorf("display-only transpose called player.Transpose %v, want no calls", player.transposeCalls)
+	}
+}
+
+func TestAudioTransposeCallsPlayer(t *testing.T) {
+	m, player := newTestTUI(t)
+	pressKey(m, "up")
+	if len(player.transposeCalls) != 1 || player.transposeCalls[0] != 1 {
+		t.Errorf("up arrow called player.Transpose %v, want [1]", player.transposeCalls)
+	}
+	if got := m.getBarChordName(0); got != "C#" {
+		t.Errorf("bar 1 shows %q after transposing up, want C#", got)
+	}
+}
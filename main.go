@@ -1,21 +1,109 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"backing-tracks/chordpro"
 	"backing-tracks/display"
 	"backing-tracks/midi"
+	"backing-tracks/musicxml"
 	"backing-tracks/parser"
 	"backing-tracks/player"
 	"backing-tracks/strudel"
+	"backing-tracks/theory"
+	"backing-tracks/validate"
 )
 
-// Global soundfont path (can be set via --soundfont flag)
+// Global soundfont path (can be set via --soundfont flag, SOUNDFONT or the config file)
 var soundFontPath string
 
+// Write a .markers.txt sidecar on export (set via --markers flag)
+var exportMarkers bool
+
+// Voicing skill level override (set via --skill flag)
+var skillLevel string
+
+// Count-in bars override (set via --count-in flag, -1 = use the track's count_in)
+var countInBars = -1
+
+// Disable reverb and chorus (set via --dry flag)
+var dryOutput bool
+
+// Random seed override for humanize and melody (set via --seed flag)
+var randomSeed int64
+var randomSeedSet bool
+
+// Semitones to transpose the key and chords, on top of the track's transpose (set via --transpose flag)
+var transposeSemitones int
+
+// Key to transpose the track to, by the shortest shift (set via --to-key flag)
+var toKey string
+
+// Semitones to transpose the TUI's chords and fretboard without changing the sound
+// (set via --transpose-display-only flag)
+var displayTransposeSemitones int
+
+// Restart playback when the BTML file changes (set via --watch flag)
+var watchMode bool
+
+// Bar range to play or export, 1-based and inclusive (set via --from-bar/--to-bar, 0 = unset)
+var fromBar, toBar int
+
+// Loop playback until stopped, optionally only the first N bars (set via --loop/--loop-bars)
+var loopPlayback bool
+var loopBars int
+
+// Play clicks only, no instruments (set by the metronome command)
+var metronomeOnly bool
+
+// Polyphony limit for real-time playback (set via --max-voices, 0 = unlimited)
+var maxVoices int
+
+// Mirror the fretboards for left-handed players (set via --lefty)
+var leftHanded bool
+
+// Prefer the easiest voicing in chord diagrams (set via --easy)
+var easyVoicings bool
+
+// Write a practice session log here when playback stops (set via --log)
+var practiceLogPath string
+
+// Generate only bass and a kick/snare click (set via --minimal)
+var minimalArrangement bool
+
+// Plain status lines instead of the full-screen player (set via --no-tui)
+var noTUI bool
+
+// Visual "3… 2… 1…" before the player starts (set via --countdown)
+var countdown bool
+
+// Speed trainer: BPM added after each loop pass, up to a target (set via --trainer-step/--trainer-max)
+var trainerStep, trainerMax int
+
+// FluidSynth audio driver for playback (set via --audio-driver, AUDIO_DRIVER or the config file, "" = pulseaudio)
+var audioDriver string
+
+// TUI color theme (set via --theme, TUI_THEME or the config file, "" = dark)
+var themeName string
+
+// Key, style and length of a generated jam (set via --key/--style/--bars)
+var jamKey = "C"
+var jamStyle = "rock"
+var jamBars = 8
+
+// Guitar tuning and capo overrides (set via --tuning/--capo, "" and -1 = use the track's;
+// the config file can set the tuning)
+var tuningName string
+var capoFret = -1
+
 func main() {
 	args := parseArgs(os.Args[1:])
 
@@ -34,6 +122,14 @@ func main() {
 			os.Exit(1)
 		}
 		playTrack(args[1])
+	case "metronome":
+		if len(args) < 2 {
+			fmt.Println("Error: metronome requires a BTML file")
+			printUsage()
+			os.Exit(1)
+		}
+		metronomeOnly = true
+		playTrack(args[1])
 	case "export":
 		if len(args) < 2 {
 			fmt.Println("Error: export requires a BTML file")
@@ -56,8 +152,111 @@ func main() {
 			outputPath = args[2]
 		}
 		exportStrudel(args[1], outputPath)
+	case "musicxml":
+		if len(args) < 2 {
+			fmt.Println("Error: musicxml requires a BTML file")
+			printUsage()
+			os.Exit(1)
+		}
+		outputPath := ""
+		if len(args) >= 3 {
+			outputPath = args[2]
+		}
+		exportMusicXML(args[1], outputPath)
+	case "validate":
+		if len(args) < 2 {
+			fmt.Println("Error: validate requires a BTML file")
+			printUsage()
+			os.Exit(1)
+		}
+		validateTrack(args[1])
+	case "chordpro":
+		if len(args) < 2 {
+			fmt.Println("Error: chordpro requires a BTML file")
+			printUsage()
+			os.Exit(1)
+		}
+		outputPath := ""
+		if len(args) >= 3 {
+			outputPath = args[2]
+		}
+		exportChordPro(args[1], outputPath)
+	case "chords":
+		if len(args) < 2 {
+			fmt.Println("Error: chords requires a BTML file")
+			printUsage()
+			os.Exit(1)
+		}
+		outputPath := ""
+		if len(args) >= 3 {
+			outputPath = args[2]
+		}
+		printChordSheet(args[1], outputPath)
+	case "show":
+		if len(args) < 2 {
+			fmt.Println("Error: show requires a BTML file")
+			printUsage()
+			os.Exit(1)
+		}
+		showArrangement(args[1])
+	case "analyze":
+		if len(args) < 2 {
+			fmt.Println("Error: analyze requires a BTML file")
+			printUsage()
+			os.Exit(1)
+		}
+		analyzeTrack(args[1])
+	case "capo-advisor":
+		if len(args) < 2 {
+			fmt.Println("Error: capo-advisor requires a BTML file")
+			printUsage()
+			os.Exit(1)
+		}
+		adviseCapo(args[1])
+	case "import":
+		if len(args) < 2 {
+			fmt.Println("Error: import requires a MIDI file")
+			printUsage()
+			os.Exit(1)
+		}
+		outputPath := ""
+		if len(args) >= 3 {
+			outputPath = args[2]
+		}
+		importMIDI(args[1], outputPath)
+	case "render":
+		if len(args) < 2 {
+			fmt.Println("Error: render requires a BTML file")
+			printUsage()
+			os.Exit(1)
+		}
+		outputPath := ""
+		if len(args) >= 3 {
+			outputPath = args[2]
+		}
+		renderTrack(args[1], outputPath)
+	case "scale":
+		if len(args) < 3 {
+			fmt.Println("Error: scale requires a key and a scale type (e.g. scale D dorian)")
+			printUsage()
+			os.Exit(1)
+		}
+		printScale(args[1], strings.Join(args[2:], " "))
+	case "key":
+		if len(args) < 2 {
+			fmt.Println("Error: key requires a key (e.g. key G or key Em)")
+			printUsage()
+			os.Exit(1)
+		}
+		printKeyChords(args[1])
+	case "jam":
+		playJam()
 	case "soundfonts":
 		listSoundFonts()
+	case "styles":
+		listStyles()
+	case "instruments":
+		listInstruments()
 	default:
 		printUsage()
 		os.Exit(1)
@@ -83,6 +282,187 @@ func parseArgs(args []string) []string {
 			soundFontPath = strings.TrimPrefix(arg, "--soundfont=")
 		} else if strings.HasPrefix(arg, "-sf=") {
 			soundFontPath = strings.TrimPrefix(arg, "-sf=")
+		} else if arg == "--skill" {
+			if i+1 < len(args) {
+				skillLevel = args[i+1]
+				i++ // Skip next arg
+			} else {
+				fmt.Println("Error: --skill requires a level (beginner, intermediate, advanced)")
+				os.Exit(1)
+			}
+		} else if strings.HasPrefix(arg, "--skill=") {
+			skillLevel = strings.TrimPrefix(arg, "--skill=")
+		} else if arg == "--count-in" {
+			if i+1 < len(args) {
+				countInBars = parseCountIn(args[i+1])
+				i++ // Skip next arg
+			} else {
+				fmt.Println("Error: --count-in requires a number of bars")
+				os.Exit(1)
+			}
+		} else if strings.HasPrefix(arg, "--count-in=") {
+			countInBars = parseCountIn(strings.TrimPrefix(arg, "--count-in="))
+		} else if arg == "--seed" {
+			if i+1 < len(args) {
+				randomSeed = parseSeed(args[i+1])
+				i++ // Skip next arg
+			} else {
+				fmt.Println("Error: --seed requires a number")
+				os.Exit(1)
+			}
+		} else if strings.HasPrefix(arg, "--seed=") {
+			randomSeed = parseSeed(strings.TrimPrefix(arg, "--seed="))
+		} else if arg == "--transpose" {
+			if i+1 < len(args) {
+				transposeSemitones = parseTranspose("--transpose", args[i+1])
+				i++ // Skip next arg
+			} else {
+				fmt.Println("Error: --transpose requires a number of semitones")
+				os.Exit(1)
+			}
+		} else if strings.HasPrefix(arg, "--transpose=") {
+			transposeSemitones = parseTranspose("--transpose", strings.TrimPrefix(arg, "--transpose="))
+		} else if arg == "--transpose-display-only" {
+			if i+1 < len(args) {
+				displayTransposeSemitones = parseTranspose("--transpose-display-only", args[i+1])
+				i++ // Skip next arg
+			} else {
+				fmt.Println("Error: --transpose-display-only requires a number of semitones")
+				os.Exit(1)
+			}
+		} else if strings.HasPrefix(arg, "--transpose-display-only=") {
+			displayTransposeSemitones = parseTranspose("--transpose-display-only", strings.TrimPrefix(arg, "--transpose-display-only="))
+		} else if arg == "--to-key" {
+			if i+1 < len(args) {
+				toKey = parseToKey(args[i+1])
+				i++ // Skip next arg
+			} else {
+				fmt.Println("Error: --to-key requires a key (e.g. G, Bb, F#m)")
+				os.Exit(1)
+			}
+		} else if strings.HasPrefix(arg, "--to-key=") {
+			toKey = parseToKey(strings.TrimPrefix(arg, "--to-key="))
+		} else if arg == "--from-bar" || arg == "--to-bar" {
+			if i+1 < len(args) {
+				setBarRange(arg, args[i+1])
+				i++ // Skip next arg
+			} else {
+				fmt.Printf("Error: %s requires a bar number\n", arg)
+				os.Exit(1)
+			}
+		} else if strings.HasPrefix(arg, "--from-bar=") || strings.HasPrefix(arg, "--to-bar=") {
+			flag, value, _ := strings.Cut(arg, "=")
+			setBarRange(flag, value)
+		} else if arg == "--loop-bars" {
+			if i+1 < len(args) {
+				loopBars = parseLoopBars(args[i+1])
+				i++ // Skip next arg
+			} else {
+				fmt.Println("Error: --loop-bars requires a number of bars")
+				os.Exit(1)
+			}
+		} else if strings.HasPrefix(arg, "--loop-bars=") {
+			loopBars = parseLoopBars(strings.TrimPrefix(arg, "--loop-bars="))
+		} else if arg == "--max-voices" {
+			if i+1 < len(args) {
+				maxVoices = parseMaxVoices(args[i+1])
+				i++ // Skip next arg
+			} else {
+				fmt.Println("Error: --max-voices requires a number of voices")
+				os.Exit(1)
+			}
+		} else if strings.HasPrefix(arg, "--max-voices=") {
+			maxVoices = parseMaxVoices(strings.TrimPrefix(arg, "--max-voices="))
+		} else if arg == "--trainer-step" || arg == "--trainer-max" {
+			if i+1 < len(args) {
+				setTrainer(arg, args[i+1])
+				i++ // Skip next arg
+			} else {
+				fmt.Printf("Error: %s requires a number of BPM\n", arg)
+				os.Exit(1)
+			}
+		} else if strings.HasPrefix(arg, "--trainer-step=") || strings.HasPrefix(arg, "--trainer-max=") {
+			flag, value, _ := strings.Cut(arg, "=")
+			setTrainer(flag, value)
+		} else if arg == "--log" {
+			if i+1 < len(args) {
+				practiceLogPath = args[i+1]
+				i++ // Skip next arg
+			} else {
+				fmt.Println("Error: --log requires a file name")
+				os.Exit(1)
+			}
+		} else if strings.HasPrefix(arg, "--log=") {
+			practiceLogPath = strings.TrimPrefix(arg, "--log=")
+		} else if arg == "--audio-driver" {
+			if i+1 < len(args) {
+				audioDriver = parseAudioDriver(args[i+1])
+				i++ // Skip next arg
+			} else {
+				fmt.Println("Error: --audio-driver requires a driver name")
+				os.Exit(1)
+			}
+		} else if strings.HasPrefix(arg, "--audio-driver=") {
+			audioDriver = parseAudioDriver(strings.TrimPrefix(arg, "--audio-driver="))
+		} else if arg == "--theme" {
+			if i+1 < len(args) {
+				themeName = parseTheme(args[i+1])
+				i++ // Skip next arg
+			} else {
+				fmt.Println("Error: --theme requires a theme name")
+				os.Exit(1)
+			}
+		} else if strings.HasPrefix(arg, "--theme=") {
+			themeName = parseTheme(strings.TrimPrefix(arg, "--theme="))
+		} else if arg == "--key" || arg == "--style" || arg == "--bars" {
+			if i+1 < len(args) {
+				setJam(arg, args[i+1])
+				i++ // Skip next arg
+			} else {
+				fmt.Printf("Error: %s requires a value\n", arg)
+				os.Exit(1)
+			}
+		} else if strings.HasPrefix(arg, "--key=") || strings.HasPrefix(arg, "--style=") || strings.HasPrefix(arg, "--bars=") {
+			flag, value, _ := strings.Cut(arg, "=")
+			setJam(flag, value)
+		} else if arg == "--tuning" {
+			if i+1 < len(args) {
+				tuningName = parseTuning(args[i+1])
+				i++ // Skip next arg
+			} else {
+				fmt.Println("Error: --tuning requires a tuning name or notes (e.g. D,A,D,G,B,E)")
+				os.Exit(1)
+			}
+		} else if strings.HasPrefix(arg, "--tuning=") {
+			tuningName = parseTuning(strings.TrimPrefix(arg, "--tuning="))
+		} else if arg == "--capo" {
+			if i+1 < len(args) {
+				capoFret = parseCapo(args[i+1])
+				i++ // Skip next arg
+			} else {
+				fmt.Println("Error: --capo requires a fret number")
+				os.Exit(1)
+			}
+		} else if strings.HasPrefix(arg, "--capo=") {
+			capoFret = parseCapo(strings.TrimPrefix(arg, "--capo="))
+		} else if arg == "--loop" {
+			loopPlayback = true
+		} else if arg == "--dry" {
+			dryOutput = true
+		} else if arg == "--lefty" {
+			leftHanded = true
+		} else if arg == "--easy" {
+			easyVoicings = true
+		} else if arg == "--minimal" {
+			minimalArrangement = true
+		} else if arg == "--no-tui" {
+			noTUI = true
+		} else if arg == "--countdown" {
+			countdown = true
+		} else if arg == "--watch" {
+			watchMode = true
+		} else if arg == "--markers" {
+			exportMarkers = true
 		} else if arg == "--help" || arg == "-h" {
 			printUsage()
 			os.Exit(0)
@@ -91,42 +471,401 @@ func parseArgs(args []string) []string {
 		}
 	}
 
-	// Also check environment variable
-	if soundFontPath == "" {
-		soundFontPath = os.Getenv("SOUNDFONT")
+	// Options not given as flags come from the environment, then the config file
+	config := loadConfig()
+	if soundFontPath == "" {
+		soundFontPath = os.Getenv("SOUNDFONT")
+	}
+	if soundFontPath == "" {
+		soundFontPath = config.SoundFont
+	}
+	if audioDriver == "" && os.Getenv("AUDIO_DRIVER") != "" {
+		audioDriver = parseAudioDriver(os.Getenv("AUDIO_DRIVER"))
+	}
+	if audioDriver == "" {
+		audioDriver = config.AudioDriver
+	}
+	if themeName == "" && os.Getenv("TUI_THEME") != "" {
+		themeName = parseTheme(os.Getenv("TUI_THEME"))
+	}
+	if themeName == "" {
+		themeName = config.Theme
+	}
+	if tuningName == "" {
+		tuningName = config.Tuning
+	}
+	if themeName != "" {
+		display.SetTheme(themeName)
+	}
+	display.SetDisplayTranspose(displayTransposeSemitones)
+
+	return remaining
+}
+
+// parseCountIn validates the --count-in value
+func parseCountIn(value string) int {
+	bars, err := strconv.Atoi(value)
+	if err != nil || bars < 0 {
+		fmt.Printf("Error: --count-in requires a number of bars, got %q\n", value)
+		os.Exit(1)
+	}
+	return bars
+}
+
+// parseSeed validates the --seed value
+func parseSeed(value string) int64 {
+	seed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		fmt.Printf("Error: --seed requires a number, got %q\n", value)
+		os.Exit(1)
+	}
+	randomSeedSet = true
+	return seed
+}
+
+// parseTranspose validates the value of a transpose flag
+func parseTranspose(flag, value string) int {
+	semitones, err := strconv.Atoi(value)
+	if err != nil {
+		fmt.Printf("Error: %s requires a number of semitones, got %q\n", flag, value)
+		os.Exit(1)
+	}
+	return semitones
+}
+
+// parseToKey validates the --to-key value
+func parseToKey(value string) string {
+	key, ok := theory.ParseKeyName(value)
+	if !ok {
+		fmt.Printf("Error: --to-key requires a key (e.g. G, Bb, F#m), got %q\n", value)
+		os.Exit(1)
+	}
+	return key
+}
+
+// parseLoopBars validates the --loop-bars value (which implies --loop)
+func parseLoopBars(value string) int {
+	bars, err := strconv.Atoi(value)
+	if err != nil || bars < 1 {
+		fmt.Printf("Error: --loop-bars requires a number of bars (1 or more), got %q\n", value)
+		os.Exit(1)
+	}
+	loopPlayback = true
+	return bars
+}
+
+// parseMaxVoices validates the --max-voices value
+func parseMaxVoices(value string) int {
+	voices, err := strconv.Atoi(value)
+	if err != nil || voices < 1 {
+		fmt.Printf("Error: --max-voices requires a number of voices (1 or more), got %q\n", value)
+		os.Exit(1)
+	}
+	return voices
+}
+
+// setTrainer validates a --trainer-step or --trainer-max value
+func setTrainer(flag, value string) {
+	bpm, err := strconv.Atoi(value)
+	if err != nil || bpm < 1 {
+		fmt.Printf("Error: %s requires a number of BPM (1 or more), got %q\n", flag, value)
+		os.Exit(1)
+	}
+	if flag == "--trainer-step" {
+		trainerStep = bpm
+	} else {
+		trainerMax = bpm
+	}
+}
+
+// parseAudioDriver validates the --audio-driver (or AUDIO_DRIVER) value
+func parseAudioDriver(value string) string {
+	driver := strings.ToLower(strings.TrimSpace(value))
+	if !player.IsAudioDriver(driver) {
+		fmt.Printf("Error: unknown audio driver %q (use %s)\n", value, strings.Join(player.AudioDrivers, ", "))
+		os.Exit(1)
+	}
+	return driver
+}
+
+// parseTheme validates the --theme (or TUI_THEME) value
+func parseTheme(value string) string {
+	name := strings.ToLower(strings.TrimSpace(value))
+	for _, t := range display.ThemeNames() {
+		if t == name {
+			return name
+		}
+	}
+	fmt.Printf("Error: unknown theme %q (use %s)\n", value, strings.Join(display.ThemeNames(), ", "))
+	os.Exit(1)
+	return ""
+}
+
+// setJam validates a --key, --style or --bars value
+func setJam(flag, value string) {
+	switch flag {
+	case "--key":
+		key, ok := theory.ParseKeyName(value)
+		if !ok {
+			fmt.Printf("Error: --key requires a key (e.g. G, Bb, F#m), got %q\n", value)
+			os.Exit(1)
+		}
+		jamKey = key
+	case "--style":
+		style := strings.ToLower(strings.TrimSpace(value))
+		if _, ok := jamStyles[style]; !ok {
+			fmt.Printf("Error: unknown jam style %q (use %s)\n", value, strings.Join(jamStyleNames(), ", "))
+			os.Exit(1)
+		}
+		jamStyle = style
+	case "--bars":
+		bars, err := strconv.Atoi(value)
+		if err != nil || bars < 2 || bars > 64 {
+			fmt.Printf("Error: --bars must be from 2 to 64, got %q\n", value)
+			os.Exit(1)
+		}
+		jamBars = bars
+	}
+}
+
+// parseTuning validates the --tuning value
+func parseTuning(value string) string {
+	name := strings.ToLower(strings.TrimSpace(value))
+	if _, ok := theory.Tunings[name]; ok {
+		return name
+	}
+	// A custom tuning: notes low string first, as in custom_tuning
+	if strings.Contains(value, ",") {
+		if _, err := parser.ParseTuning(value); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return strings.TrimSpace(value)
+	}
+	fmt.Printf("Error: unknown tuning %q (use %s, or notes low string first, e.g. D,A,D,G,B,E)\n", value, strings.Join(theory.TuningNames, ", "))
+	os.Exit(1)
+	return ""
+}
+
+// isCustomTuning reports whether a --tuning value is a list of notes rather than a name
+func isCustomTuning(value string) bool {
+	return strings.Contains(value, ",")
+}
+
+// registerTuningOverride returns the name to look up the --tuning value under,
+// registering a custom tuning (validated by parseTuning) as parser.CustomTuningName
+func registerTuningOverride() string {
+	if !isCustomTuning(tuningName) {
+		return tuningName
+	}
+	tuning, _ := parser.ParseTuning(tuningName)
+	theory.RegisterTuning(parser.CustomTuningName, tuning)
+	return parser.CustomTuningName
+}
+
+// parseCapo validates the --capo value, clamped to frets 0-12 like the player's capo keys
+func parseCapo(value string) int {
+	fret, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		fmt.Printf("Error: --capo must be a fret from 0 to 12, got %q\n", value)
+		os.Exit(1)
+	}
+	return max(0, min(12, fret))
+}
+
+// setBarRange validates a --from-bar or --to-bar value
+func setBarRange(flag, value string) {
+	bar, err := strconv.Atoi(value)
+	if err != nil || bar < 1 {
+		fmt.Printf("Error: %s requires a bar number (1 or more), got %q\n", flag, value)
+		os.Exit(1)
+	}
+	if flag == "--from-bar" {
+		fromBar = bar
+	} else {
+		toBar = bar
+	}
+}
+
+// barRange returns the 0-based bar range [start, end) selected with --from-bar/--to-bar,
+// checked against the track's length (end 0 = to the end of the song)
+func barRange(track *parser.Track) (start, end int) {
+	totalBars := track.Progression.TotalBars()
+	if fromBar > totalBars {
+		fmt.Printf("Error: --from-bar %d is past the end of the track (%d bars)\n", fromBar, totalBars)
+		os.Exit(1)
+	}
+	if toBar > 0 && toBar < fromBar {
+		fmt.Printf("Error: --to-bar %d is before --from-bar %d\n", toBar, fromBar)
+		os.Exit(1)
+	}
+	if fromBar > 0 {
+		start = fromBar - 1
+	}
+	if toBar > 0 && toBar < totalBars {
+		end = toBar
+	}
+	return start, end
+}
+
+// hasBarRange reports whether --from-bar or --to-bar was given
+func hasBarRange() bool {
+	return fromBar > 0 || toBar > 0
+}
+
+// trimToBarRange cuts a generated MIDI file down to the --from-bar/--to-bar range,
+// and to the first loopBars bars of it if loopBars > 0
+func trimToBarRange(midiFile string, track *parser.Track, loopBars int) {
+	if !hasBarRange() && loopBars == 0 {
+		return
+	}
+	start, end := barRange(track)
+	if loopBars > 0 && (end == 0 || start+loopBars < end) {
+		end = start + loopBars
+	}
+	if err := midi.TrimMIDIFile(midiFile, track, start, end); err != nil {
+		fmt.Printf("Error trimming MIDI to bars: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// playOptions returns the real-time playback settings from the command line
+func playOptions(track *parser.Track, filename string) player.PlayOptions {
+	opts := player.PlayOptions{Loop: loopPlayback, LoopBars: loopBars, MetronomeOnly: metronomeOnly, MaxVoices: maxVoices, TrainerStep: trainerStep, TrainerMax: trainerMax, AudioDriver: audioDriver, NoTUI: noTUI, Countdown: countdown}
+	if practiceLogPath != "" {
+		opts.Log = player.NewPracticeLog(practiceLogPath, filename, track.Info.Title)
+	}
+	if hasBarRange() {
+		opts.FromBar, opts.ToBar = barRange(track)
 	}
+	return opts
+}
 
-	return remaining
+// applyTrackOverrides applies command-line settings on top of the loaded track
+func applyTrackOverrides(track *parser.Track) {
+	if skillLevel != "" {
+		track.Info.Skill = skillLevel
+	}
+	if countInBars >= 0 {
+		track.Info.CountIn = countInBars
+		if track.Info.CountInSound == "" {
+			track.Info.CountInSound = "sticks" // Side-stick clicks, accented beat 1
+		}
+	}
+	if randomSeedSet {
+		track.Info.Seed = randomSeed
+		if track.Melody != nil {
+			track.Melody.Seed = randomSeed
+		}
+	}
+	if transposeSemitones != 0 {
+		track.Transpose(transposeSemitones)
+	}
+	if toKey != "" {
+		track.TransposeToKey(toKey) // Validated by parseToKey
+	}
+	if dryOutput {
+		off := 0.0
+		track.Info.Reverb = &off
+		track.Info.Chorus = &off
+	}
+	if leftHanded {
+		track.Info.LeftHanded = true
+	}
+	if easyVoicings {
+		track.Info.EasyVoicings = true
+	}
+	if minimalArrangement {
+		track.Info.Arrangement = midi.ArrangementMinimal
+	}
+	if tuningName != "" {
+		if isCustomTuning(tuningName) {
+			// Registered again: loading the track registered its own custom tuning
+			track.Info.Tuning = ""
+			track.Info.CustomTuning = tuningName
+			registerTuningOverride()
+		} else {
+			track.Info.Tuning = tuningName
+			track.Info.CustomTuning = "" // The named tuning replaces a custom one
+		}
+	}
+	if capoFret >= 0 {
+		track.Info.Capo = capoFret
+	}
 }
 
 func playTrack(filename string) {
+	if watchMode {
+		watchTrack(filename)
+		return
+	}
+
 	// Parse BTML file
 	track, err := parser.LoadTrack(filename)
 	if err != nil {
 		fmt.Printf("Error loading track: %v\n", err)
 		os.Exit(1)
 	}
+	applyTrackOverrides(track)
+	playLoadedTrack(track, filename)
+}
 
+// playLoadedTrack plays a parsed track with the live display; filename is the
+// BTML file it came from ("" for a jam)
+func playLoadedTrack(track *parser.Track, filename string) {
 	// Display track info in terminal
 	display.ShowTrack(track)
 
 	// Generate MIDI file from track
-	midiFile, err := midi.GenerateFromTrack(track)
+	generate := midi.GenerateFromTrack
+	if metronomeOnly {
+		generate = midi.GenerateMetronomeFromTrack
+	}
+	midiFile, err := generate(track)
 	if err != nil {
 		fmt.Printf("Error generating MIDI: %v\n", err)
 		os.Exit(1)
 	}
+	// The file is only played when the real-time player is unavailable;
+	// without a terminal it is replayed for --loop
+	trimToBarRange(midiFile, track, loopBars)
 
 	// Play via FluidSynth with live display
-	fmt.Println("♪ Playing... (Press Ctrl+C to stop)\n")
-	if err := player.PlayMIDIWithDisplay(midiFile, track, soundFontPath); err != nil {
-		fmt.Printf("Error playing: %v\n", err)
-		os.Exit(1)
+	fmt.Print("♪ Playing... (Press Ctrl+C to stop)\n\n")
+	if err := player.PlayMIDIWithDisplay(midiFile, track, soundFontPath, playOptions(track, filename)); err != nil {
+		exitWithPlayerError("Error playing", err)
 	}
 
 	fmt.Println("\n\n✓ Playback complete!")
 }
 
+// watchTrack plays a track and restarts playback each time the file is saved
+func watchTrack(filename string) {
+	if filename == parser.Stdin {
+		fmt.Println("Error: --watch needs a BTML file, not standard input")
+		os.Exit(1)
+	}
+	load := func() (*parser.Track, error) {
+		track, err := parser.LoadTrack(filename)
+		if err != nil {
+			return nil, err
+		}
+		applyTrackOverrides(track)
+		return track, nil
+	}
+
+	track, err := load()
+	if err != nil {
+		fmt.Printf("Error loading track: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("♪ Watching %s for changes... (Press q to stop)\n\n", filename)
+	if err := player.PlayWithWatch(filename, load, soundFontPath, playOptions(track, filename)); err != nil {
+		exitWithPlayerError("Error playing", err)
+	}
+}
+
 func exportTrack(filename, outputPath string) {
 	// Parse BTML file
 	track, err := parser.LoadTrack(filename)
@@ -134,6 +873,7 @@ func exportTrack(filename, outputPath string) {
 		fmt.Printf("Error loading track: %v\n", err)
 		os.Exit(1)
 	}
+	applyTrackOverrides(track)
 
 	// Display track info
 	display.ShowTrack(track)
@@ -144,13 +884,12 @@ func exportTrack(filename, outputPath string) {
 		fmt.Printf("Error generating MIDI: %v\n", err)
 		os.Exit(1)
 	}
+	trimToBarRange(tmpFile, track, 0)
 
 	// Determine output path
 	if outputPath == "" {
 		// Default: same name as input with .mid extension
-		base := filepath.Base(filename)
-		ext := filepath.Ext(base)
-		outputPath = strings.TrimSuffix(base, ext) + ".mid"
+		outputPath = defaultOutputPath(filename, ".mid")
 	}
 
 	// Copy from temp to output
@@ -166,6 +905,54 @@ func exportTrack(filename, outputPath string) {
 	}
 
 	fmt.Printf("\n✓ Exported to: %s\n", outputPath)
+
+	// Optional sidecar with section markers (time, name)
+	if exportMarkers {
+		markersPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".markers.txt"
+		start, end := 0, 0
+		if hasBarRange() {
+			start, end = barRange(track) // Match the trimmed MIDI file
+		}
+		if err := midi.WriteMarkerFile(track, markersPath, start, end); err != nil {
+			fmt.Printf("Error writing markers: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Markers written to: %s\n", markersPath)
+	}
+}
+
+func renderTrack(filename, outputPath string) {
+	// Parse BTML file
+	track, err := parser.LoadTrack(filename)
+	if err != nil {
+		fmt.Printf("Error loading track: %v\n", err)
+		os.Exit(1)
+	}
+	applyTrackOverrides(track)
+
+	// Display track info
+	display.ShowTrack(track)
+
+	// Generate MIDI file
+	midiFile, err := midi.GenerateFromTrack(track)
+	if err != nil {
+		fmt.Printf("Error generating MIDI: %v\n", err)
+		os.Exit(1)
+	}
+	trimToBarRange(midiFile, track, 0)
+
+	// Determine output path
+	if outputPath == "" {
+		// Default: same name as input with .wav extension
+		outputPath = defaultOutputPath(filename, ".wav")
+	}
+
+	// Render offline via FluidSynth
+	if err := player.RenderWAV(midiFile, outputPath, soundFontPath); err != nil {
+		exitWithPlayerError("Error rendering audio", err)
+	}
+
+	fmt.Printf("\n✓ Rendered to: %s\n", outputPath)
 }
 
 func exportStrudel(filename, outputPath string) {
@@ -175,6 +962,7 @@ func exportStrudel(filename, outputPath string) {
 		fmt.Printf("Error loading track: %v\n", err)
 		os.Exit(1)
 	}
+	applyTrackOverrides(track)
 
 	// Display track info
 	display.ShowTrack(track)
@@ -185,9 +973,7 @@ func exportStrudel(filename, outputPath string) {
 	// Determine output path
 	if outputPath == "" {
 		// Default: same name as input with .js extension
-		base := filepath.Base(filename)
-		ext := filepath.Ext(base)
-		outputPath = strings.TrimSuffix(base, ext) + ".strudel.js"
+		outputPath = defaultOutputPath(filename, ".strudel.js")
 	}
 
 	// Write to file
@@ -200,6 +986,339 @@ func exportStrudel(filename, outputPath string) {
 	fmt.Println("\nPaste the code into https://strudel.cc to play!")
 }
 
+func exportMusicXML(filename, outputPath string) {
+	// Parse BTML file
+	track, err := parser.LoadTrack(filename)
+	if err != nil {
+		fmt.Printf("Error loading track: %v\n", err)
+		os.Exit(1)
+	}
+	applyTrackOverrides(track)
+
+	// Display track info
+	display.ShowTrack(track)
+
+	// Generate MusicXML lead sheet
+	xml := musicxml.GenerateMusicXML(track)
+
+	// Determine output path
+	if outputPath == "" {
+		// Default: same name as input with .musicxml extension
+		outputPath = defaultOutputPath(filename, ".musicxml")
+	}
+
+	// Write to file
+	if err := os.WriteFile(outputPath, []byte(xml), 0644); err != nil {
+		fmt.Printf("Error writing MusicXML file: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\n✓ Exported to: %s\n", outputPath)
+	fmt.Println("\nOpen it in MuseScore or any notation program that imports MusicXML.")
+}
+
+// validateTrack prints every problem found in a BTML file and exits non-zero on errors
+func validateTrack(filename string) {
+	diags, err := validate.File(filename)
+	if err != nil {
+		fmt.Printf("Error reading track: %v\n", err)
+		os.Exit(1)
+	}
+	if filename == parser.Stdin {
+		filename = "<stdin>"
+	}
+
+	if len(diags) == 0 {
+		fmt.Printf("✓ %s: no problems found\n", filename)
+		return
+	}
+
+	errorCount := 0
+	for _, d := range diags {
+		fmt.Printf("%s:%s\n", filename, d)
+		if d.Severity == validate.SeverityError {
+			errorCount++
+		}
+	}
+	fmt.Printf("\n%d error(s), %d warning(s)\n", errorCount, len(diags)-errorCount)
+
+	if validate.HasErrors(diags) {
+		os.Exit(1)
+	}
+}
+
+func exportChordPro(filename, outputPath string) {
+	// Parse BTML file
+	track, err := parser.LoadTrack(filename)
+	if err != nil {
+		fmt.Printf("Error loading track: %v\n", err)
+		os.Exit(1)
+	}
+	applyTrackOverrides(track)
+
+	// Display track info
+	display.ShowTrack(track)
+
+	// Generate ChordPro song sheet
+	sheet := chordpro.Generate(track)
+
+	// Determine output path
+	if outputPath == "" {
+		// Default: same name as input with .cho extension
+		outputPath = defaultOutputPath(filename, ".cho")
+	}
+
+	// Write to file
+	if err := os.WriteFile(outputPath, []byte(sheet), 0644); err != nil {
+		fmt.Printf("Error writing ChordPro file: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\n✓ Exported to: %s\n", outputPath)
+}
+
+// printChordSheet prints a diagram of each chord in the song, or writes them to outputPath
+func printChordSheet(filename, outputPath string) {
+	track, err := parser.LoadTrack(filename)
+	if err != nil {
+		fmt.Printf("Error loading track: %v\n", err)
+		os.Exit(1)
+	}
+	applyTrackOverrides(track)
+
+	if outputPath == "" {
+		fmt.Print(display.RenderChordSheet(track, true))
+		return
+	}
+
+	if err := os.WriteFile(outputPath, []byte(display.RenderChordSheet(track, false)), 0644); err != nil {
+		fmt.Printf("Error writing chord sheet: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Chord diagrams written to: %s\n", outputPath)
+}
+
+// showArrangement prints the song bar by bar (chords, sections, lyrics) without playing it
+func showArrangement(filename string) {
+	track, err := parser.LoadTrack(filename)
+	if err != nil {
+		fmt.Printf("Error loading track: %v\n", err)
+		os.Exit(1)
+	}
+	applyTrackOverrides(track)
+	fmt.Print(display.RenderArrangement(track))
+}
+
+// analyzeTrack prints what each chord does in the track's key and flags the ones
+// outside it (see display.RenderAnalysis)
+func analyzeTrack(filename string) {
+	track, err := parser.LoadTrack(filename)
+	if err != nil {
+		fmt.Printf("Error loading track: %v\n", err)
+		os.Exit(1)
+	}
+	applyTrackOverrides(track)
+	fmt.Print(display.RenderAnalysis(track))
+}
+
+// adviseCapo prints the capo positions 0-7 for the song, ranked by how many of its
+// chords become open shapes (see display.RenderCapoAdvice)
+func adviseCapo(filename string) {
+	track, err := parser.LoadTrack(filename)
+	if err != nil {
+		fmt.Printf("Error loading track: %v\n", err)
+		os.Exit(1)
+	}
+	applyTrackOverrides(track)
+	fmt.Print(display.RenderCapoAdvice(track))
+}
+
+// importMIDI converts a MIDI file to a BTML sketch to edit (see midi.ImportMIDI).
+// It won't overwrite an existing file unless that is given as the output path.
+func importMIDI(filename, outputPath string) {
+	source, err := midi.ImportMIDI(filename)
+	if err != nil {
+		fmt.Printf("Error importing MIDI: %v\n", err)
+		os.Exit(1)
+	}
+
+	if outputPath == "" {
+		outputPath = defaultOutputPath(filename, ".btml")
+		if _, err := os.Stat(outputPath); err == nil {
+			fmt.Printf("Error: %s already exists (give an output path to replace it)\n", outputPath)
+			os.Exit(1)
+		}
+	}
+	if err := os.WriteFile(outputPath, []byte(source), 0644); err != nil {
+		fmt.Printf("Error writing BTML: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Print(source)
+	fmt.Printf("\n✓ Imported to: %s\n", outputPath)
+}
+
+// printScale prints the fretboard for a scale, with each note labeled by its degree
+func printScale(key, scaleName string) {
+	keyName, ok := theory.ParseKeyName(key)
+	if !ok {
+		fmt.Printf("Error: invalid key %q (e.g. G, Bb, F#)\n", key)
+		os.Exit(1)
+	}
+	scaleType, ok := theory.ParseScaleType(scaleName)
+	if !ok {
+		var names []string
+		for _, t := range theory.ScaleTypes {
+			names = append(names, string(t))
+		}
+		fmt.Printf("Error: unknown scale type %q (use %s)\n", scaleName, strings.Join(names, ", "))
+		os.Exit(1)
+	}
+
+	root, _ := theory.ParseKey(keyName)
+	scale := theory.NewScale(root, scaleType)
+	// Spell the root as typed (Bb, not A#)
+	scale.RootName = strings.TrimSuffix(keyName, "m")
+	scale.Name = scale.RootName + " " + theory.ScaleNames[scaleType]
+
+	name := registerTuningOverride()
+	if name == "" {
+		name = "standard"
+	}
+	fretboard := display.NewFretboardDisplayWithTuning(scale, 15, theory.GetTuning(name))
+	fretboard.SetDegreeLabels(true)
+	for _, line := range fretboard.Render() {
+		fmt.Println(line)
+	}
+	fmt.Println()
+	fmt.Printf(" Notes: %s  (%s tuning)\n", scaleNotes(scale, keyName), name)
+}
+
+// scaleNotes lists a scale's notes from the root, spelled with flats in flat keys
+func scaleNotes(scale *theory.Scale, key string) string {
+	names := theory.NoteNames
+	if strings.Contains(scale.RootName, "b") || theory.KeyUsesFlats(key) {
+		names = theory.NoteNamesFlat
+	}
+	var notes []string
+	for _, interval := range scale.Intervals {
+		notes = append(notes, names[(scale.Root+interval)%12])
+	}
+	return strings.Join(notes, " ")
+}
+
+// printKeyChords prints the diatonic chords of a key and some common progressions
+func printKeyChords(key string) {
+	keyName, ok := theory.ParseKeyName(key)
+	if !ok {
+		fmt.Printf("Error: invalid key %q (e.g. G, Bb, F#m)\n", key)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Diatonic chords in %s:\n", keyName)
+	fmt.Println()
+	for _, chord := range theory.DiatonicChords(keyName) {
+		fmt.Printf("  %-6s %-8s %s\n", chord.Numeral, chord.Triad, chord.Seventh)
+	}
+	fmt.Println()
+
+	fmt.Println("Common progressions:")
+	fmt.Println()
+	for _, progression := range theory.KeyProgressions(keyName) {
+		fmt.Printf("  %-14s %s\n", progression.Name, strings.Join(progression.Chords(keyName), " - "))
+	}
+}
+
+// jamArrangement is the arrangement a generated jam is played with
+type jamArrangement struct {
+	tempo    int
+	rhythm   string // rhythm.style
+	bass     string // bass.style
+	drums    string // drums.style ("" = no drums)
+	sevenths bool   // Seventh chords (jazz, funk)
+	dominant bool   // Major chords as dominant 7ths (blues)
+}
+
+// jamStyles are the styles the jam command can play
+var jamStyles = map[string]jamArrangement{
+	"rock":    {tempo: 110, rhythm: "eighth", bass: "root_fifth", drums: "rock_beat"},
+	"pop":     {tempo: 100, rhythm: "strum_up_down", bass: "root", drums: "rock_beat"},
+	"blues":   {tempo: 90, rhythm: "shuffle_strum", bass: "boogie", drums: "blues_shuffle", dominant: true},
+	"jazz":    {tempo: 130, rhythm: "quarter", bass: "swing_walking", drums: "jazz_swing", sevenths: true},
+	"funk":    {tempo: 100, rhythm: "funk", bass: "funk", drums: "disco", sevenths: true},
+	"country": {tempo: 110, rhythm: "country", bass: "country", drums: "country"},
+	"reggae":  {tempo: 80, rhythm: "reggae", bass: "reggae", drums: "one_drop"},
+	"folk":    {tempo: 95, rhythm: "folk", bass: "root"},
+	"ballad":  {tempo: 70, rhythm: "arpeggio_up", bass: "root"},
+}
+
+// jamStyleNames returns the jam styles in alphabetical order
+func jamStyleNames() []string {
+	var names []string
+	for name := range jamStyles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// jamSource generates a random diatonic progression for the jam settings and
+// returns it as BTML source, one chord per bar
+func jamSource(seed int64) string {
+	arrangement := jamStyles[jamStyle]
+	rng := rand.New(rand.NewSource(seed))
+	chords := theory.GenerateProgression(jamKey, jamBars, arrangement.sevenths, rng)
+	if arrangement.dominant {
+		for i, chord := range chords {
+			if !strings.Contains(chord[1:], "m") {
+				chords[i] = chord + "7"
+			}
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Generated by: backing-tracks jam --key %s --style %s --bars %d --seed %d\n", jamKey, jamStyle, jamBars, seed)
+	fmt.Fprintf(&sb, "track:\n  title: \"Jam in %s\"\n  key: %s\n  tempo: %d\n  time_signature: 4/4\n  style: %s\n\n", jamKey, jamKey, arrangement.tempo, jamStyle)
+	fmt.Fprintf(&sb, "chord_progression:\n  pattern: \"%s\"\n  bars_per_chord: 1\n\n", strings.Join(chords, " "))
+	fmt.Fprintf(&sb, "rhythm:\n  style: %s\n\nbass:\n  style: %s\n", arrangement.rhythm, arrangement.bass)
+	if arrangement.drums != "" {
+		fmt.Fprintf(&sb, "\ndrums:\n  style: %s\n", arrangement.drums)
+	}
+	return sb.String()
+}
+
+// playJam plays a generated progression on a loop and prints it as BTML to keep
+func playJam() {
+	seed := randomSeed
+	if !randomSeedSet {
+		seed = time.Now().UnixNano() % 1000000 // Short enough to retype
+	}
+	source := jamSource(seed)
+
+	track, err := parser.ParseTrack([]byte(source))
+	if err != nil {
+		fmt.Printf("Error generating jam: %v\n", err)
+		os.Exit(1)
+	}
+	applyTrackOverrides(track)
+
+	fmt.Println(source)
+	fmt.Println("(Save the lines above as a .btml file to play this jam again)")
+	fmt.Println()
+	loopPlayback = true
+	playLoadedTrack(track, "")
+}
+
+// defaultOutputPath returns the input file's name with its extension replaced by
+// ext, or "out" plus ext when the track was read from standard input
+func defaultOutputPath(filename, ext string) string {
+	if filename == parser.Stdin {
+		return "out" + ext
+	}
+	base := filepath.Base(filename)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + ext
+}
+
 func listSoundFonts() {
 	fmt.Println("Available SoundFonts:")
 	fmt.Println()
@@ -209,15 +1328,7 @@ func listSoundFonts() {
 	if len(found) == 0 {
 		fmt.Println("  No SoundFonts found!")
 		fmt.Println()
-		fmt.Println("Install the default SoundFont:")
-		fmt.Println("  sudo apt install fluid-soundfont-gm")
-		fmt.Println()
-		fmt.Println("Or download better SoundFonts:")
-		fmt.Println("  - FluidR3 GM (140MB): https://member.keymusician.com/Member/FluidR3_GM/")
-		fmt.Println("  - SGM-V2.01 (235MB):  https://musical-artifacts.com/artifacts/855")
-		fmt.Println("  - Timbres of Heaven: https://midkar.com/soundfonts/")
-		fmt.Println()
-		fmt.Println("Place .sf2 files in ./soundfonts/ or specify with --soundfont flag")
+		printSoundFontHelp()
 	} else {
 		for _, sf := range found {
 			fmt.Printf("  %s\n", sf)
@@ -227,27 +1338,160 @@ func listSoundFonts() {
 	}
 }
 
+// printSoundFontHelp explains how to install or download a SoundFont
+func printSoundFontHelp() {
+	fmt.Println("Install the default SoundFont:")
+	fmt.Println("  sudo apt install fluid-soundfont-gm")
+	fmt.Println()
+	fmt.Println("Or download better SoundFonts:")
+	fmt.Println("  - FluidR3 GM (140MB): https://member.keymusician.com/Member/FluidR3_GM/")
+	fmt.Println("  - SGM-V2.01 (235MB):  https://musical-artifacts.com/artifacts/855")
+	fmt.Println("  - Timbres of Heaven: https://midkar.com/soundfonts/")
+	fmt.Println()
+	fmt.Println("Place .sf2 files in ./soundfonts/ or specify with --soundfont flag")
+}
+
+// exitWithPlayerError prints an error from playback or rendering, with SoundFont
+// guidance when none was found, and exits
+func exitWithPlayerError(prefix string, err error) {
+	fmt.Printf("%s: %v\n", prefix, err)
+	if errors.Is(err, player.ErrNoSoundFont) {
+		fmt.Println()
+		printSoundFontHelp()
+	}
+	os.Exit(1)
+}
+
+// listStyles prints the style names compiled into the generators, by category
+func listStyles() {
+	for i, group := range midi.StyleGroups() {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s (%s):\n", group.Name, group.Field)
+
+		// Wrap the names to fit an 80-column terminal
+		line := " "
+		for _, style := range group.Styles {
+			if len(line)+len(style)+1 > 78 {
+				fmt.Println(line)
+				line = " "
+			}
+			line += " " + style
+		}
+		fmt.Println(line)
+	}
+}
+
+// listInstruments prints the instrument and drum kit names with their GM program
+// numbers, wrapped like listStyles
+func listInstruments() {
+	for _, group := range midi.InstrumentGroups() {
+		programs := midi.GMInstruments
+		if group.Field == "drums.kit" {
+			programs = midi.GMDrumKits
+		}
+		fmt.Printf("%s (%s):\n", group.Name, group.Field)
+
+		line := " "
+		for _, name := range group.Styles {
+			entry := fmt.Sprintf("%s=%d", name, programs[name])
+			if len(line)+len(entry)+1 > 78 {
+				fmt.Println(line)
+				line = " "
+			}
+			line += " " + entry
+		}
+		fmt.Println(line)
+		fmt.Println()
+	}
+	fmt.Println("Any other GM program: prog:N with N from 0 to 127 (e.g. instrument: prog:105)")
+}
+
 func printUsage() {
 	fmt.Println("Backing Tracks Player v0.5")
 	fmt.Println()
 	fmt.Println("Usage:")
 	fmt.Println("  backing-tracks play <file.btml>              Play backing track")
+	fmt.Println("  backing-tracks metronome <file.btml>         Play only a click at the track's tempo and meter")
 	fmt.Println("  backing-tracks export <file.btml> [out]      Export to MIDI file")
+	fmt.Println("  backing-tracks render <file.btml> [out.wav]  Render to WAV audio (needs FluidSynth)")
 	fmt.Println("  backing-tracks strudel <file.btml> [out]     Export to Strudel code")
+	fmt.Println("  backing-tracks musicxml <file.btml> [out]    Export a MusicXML lead sheet")
+	fmt.Println("  backing-tracks chordpro <file.btml> [out]    Export a ChordPro song sheet")
+	fmt.Println("  backing-tracks chords <file.btml> [out.txt]  Print a diagram of each chord in the song")
+	fmt.Println("  backing-tracks show <file.btml>              Print the song bar by bar: chords, sections, lyrics")
+	fmt.Println("  backing-tracks analyze <file.btml>           Name each chord's function in the key, flag the ones outside it")
+	fmt.Println("  backing-tracks capo-advisor <file.btml>      Rank capo positions 0-7 by how many chords become open shapes")
+	fmt.Println("  backing-tracks import <file.mid> [out]       Sketch a BTML file from a MIDI file (tempo, key, chords)")
+	fmt.Println("  backing-tracks validate <file.btml>          Check a BTML file for mistakes")
+	fmt.Println("  backing-tracks scale <key> <type>            Print a scale on the fretboard (e.g. scale D dorian)")
+	fmt.Println("  backing-tracks key <key>                     List a key's diatonic chords and common progressions")
+	fmt.Println("  backing-tracks jam                           Loop a random chord progression to practice over")
+	fmt.Println("  backing-tracks styles                        List rhythm, drum, bass and melody styles")
+	fmt.Println("  backing-tracks instruments                   List instrument and drum kit names (GM programs)")
 	fmt.Println("  backing-tracks soundfonts                    List available SoundFonts")
 	fmt.Println()
 	fmt.Println("Options:")
 	fmt.Println("  --soundfont, -sf <path>   Use custom SoundFont (.sf2 file)")
+	fmt.Println("  --markers                 Also write section markers to <out>.markers.txt (export)")
+	fmt.Println("  --skill <level>           Chord voicings: beginner, intermediate, advanced")
+	fmt.Println("  --count-in <bars>         Click bars before the track (play, export, render)")
+	fmt.Println("  --dry                     No reverb or chorus (for your own effects chain)")
+	fmt.Println("  --transpose <n>           Shift the key and chords by n semitones (e.g. -2)")
+	fmt.Println("  --to-key <key>            Transpose to a key, e.g. G or Gb (spelling follows it)")
+	fmt.Println("  --transpose-display-only <n>  Shift the TUI's chords and fretboard by n semitones,")
+	fmt.Println("                            not the sound (play; like the -/= keys)")
+	fmt.Println("  --seed <n>                Random seed for humanize, melody and jam progressions (same seed = same render)")
+	fmt.Println("  --from-bar <n>            Start at bar n (play, export, render)")
+	fmt.Println("  --to-bar <n>              Stop after bar n (play, export, render)")
+	fmt.Println("  --loop                    Loop until stopped (play; loops the --from-bar/--to-bar range)")
+	fmt.Println("  --loop-bars <n>           Loop the first n bars until stopped (play)")
+	fmt.Println("  --max-voices <n>          Limit notes sounding at once; new notes cut the oldest (play)")
+	fmt.Println("  --trainer-step <bpm>      Speed trainer: raise the tempo this much after each loop pass (play)")
+	fmt.Println("  --trainer-max <bpm>       Speed trainer: stop speeding up at this tempo (play)")
+	fmt.Println("  --audio-driver <name>     FluidSynth audio output: pulseaudio (default), alsa, jack, ... (play)")
+	fmt.Println("  --log <file>              Write a practice log when playback stops: time spent on each")
+	fmt.Println("                            loop and at each tempo, as JSON (play)")
+	fmt.Println("  --theme <name>            TUI colors: dark (default), light, mono (no color)")
+	fmt.Println("  --key <key>               Key of the jam, e.g. G or Em (default C)")
+	fmt.Println("  --style <name>            Style of the jam: rock (default), pop, blues, jazz, funk,")
+	fmt.Println("                            country, reggae, folk, ballad")
+	fmt.Println("  --bars <n>                Bars (one chord each) in the jam, 2-64 (default 8)")
+	fmt.Println("  --tuning <name>           Guitar tuning, e.g. drop_d, dadgad or notes low string first like")
+	fmt.Println("                            C,G,C,G,C,D (replaces the track's; chords, fretboards, fingerstyle, scale)")
+	fmt.Println("  --capo <fret>             Capo position 0-12, 0 for none (replaces the track's; the player")
+	fmt.Println("                            starts with it sounding and drawn, as after pressing ])")
+	fmt.Println("  --lefty                   Left-handed fretboards and chord diagrams (nut on the right)")
+	fmt.Println("  --easy                    Chord diagrams show the easiest voicing (open shapes before barres)")
+	fmt.Println("  --minimal                 Bass and a kick/snare click only, no chords, melody or pad")
+	fmt.Println("                            (play, export, render)")
+	fmt.Println("  --no-tui                  Print a status line per bar instead of the full-screen player")
+	fmt.Println("                            (automatic when output is not a terminal)")
+	fmt.Println("  --countdown               Show a one-bar visual countdown before playback (play;")
+	fmt.Println("                            runs over the clicks when --count-in is set)")
+	fmt.Println("  --watch                   Restart playback when the BTML file changes (play)")
 	fmt.Println("  --help, -h                Show this help")
 	fmt.Println()
 	fmt.Println("Environment:")
 	fmt.Println("  SOUNDFONT                 Default SoundFont path")
+	fmt.Println("  AUDIO_DRIVER              Default audio driver (same values as --audio-driver)")
+	fmt.Println("  TUI_THEME                 Default TUI theme (same values as --theme)")
+	fmt.Println()
+	fmt.Println("Config file:")
+	fmt.Printf("  %s\n", configDisplayPath())
+	fmt.Println("  JSON defaults for options you'd pass on every run, e.g.")
+	fmt.Println(`  {"soundfont": "~/soundfonts/SGM.sf2", "audio_driver": "jack", "theme": "light", "tuning": "drop_d"}`)
+	fmt.Println("  Precedence: command-line flags, then environment variables, then the config file,")
+	fmt.Println("  then the built-in defaults")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  backing-tracks play examples/blues-full.btml")
 	fmt.Println("  backing-tracks play --soundfont ~/soundfonts/SGM.sf2 examples/edm-808.btml")
 	fmt.Println("  backing-tracks export examples/blues-full.btml my-track.mid")
+	fmt.Println("  backing-tracks render examples/blues-full.btml blues.wav")
 	fmt.Println("  backing-tracks strudel examples/blues-full.btml")
+	fmt.Println("  cat song.btml | backing-tracks export -      (- reads BTML from stdin; writes out.mid)")
 	fmt.Println()
 	fmt.Println("SoundFont tips:")
 	fmt.Println("  Place .sf2 files in ./soundfonts/ directory for auto-detection")
@@ -0,0 +1,231 @@
+package midi
+
+import (
+	"sort"
+
+	"backing-tracks/parser"
+)
+
+// Sections can override the track's rhythm, drums, style and chord instrument
+// (see parser.Section). A part is generated for the whole song with each
+// overriding section's settings, and the notes that start in that section's bars
+// replace the track's own.
+
+// InstrumentChange switches a channel to a GM instrument (by name) from Tick on
+type InstrumentChange struct {
+	Tick       uint32
+	Channel    uint8
+	Instrument string // GM instrument name ("" = the channel's default)
+}
+
+// sectionTicks returns the tick range of a section occurrence
+func sectionTicks(occurrence parser.SectionOccurrence, ticksPerBar uint32) (start, end uint32) {
+	return uint32(occurrence.StartBar) * ticksPerBar, uint32(occurrence.EndBar) * ticksPerBar
+}
+
+// arrangedChordEvents generates the chord part, with each section's rhythm in its bars
+func arrangedChordEvents(track *parser.Track, chords []parser.Chord, timeSig parser.TimeSignature) []midiEvent {
+	events := GenerateChordRhythmForMeter(chords, track.Rhythm, timeSig, track.Info.Skill)
+
+	generated := make(map[string][]midiEvent)
+	for _, occurrence := range track.GetSectionOccurrences() {
+		if occurrence.Section == nil || occurrence.Section.Rhythm == nil {
+			continue
+		}
+		override, ok := generated[occurrence.Name]
+		if !ok {
+			override = GenerateChordRhythmForMeter(chords, occurrence.Section.Rhythm, timeSig, track.Info.Skill)
+			generated[occurrence.Name] = override
+		}
+		start, end := sectionTicks(occurrence, timeSig.TicksPerBar())
+		inSection := func(tick uint32) bool { return tick >= start && tick < end }
+		events = append(keepNotes(events, func(tick uint32) bool { return !inSection(tick) }, true),
+			keepNotes(override, inSection, false)...)
+	}
+	return events
+}
+
+// keepNotes returns the notes of events (note-on and note-off) whose note-on tick
+// passes keep, plus the other events when withOther is set
+func keepNotes(events []midiEvent, keep func(tick uint32) bool, withOther bool) []midiEvent {
+	sorted := append([]midiEvent(nil), events...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].tick < sorted[j].tick
+	})
+
+	var kept []midiEvent
+	open := make(map[[2]uint8][]bool) // Whether each sounding note was kept, oldest first
+	for _, evt := range sorted {
+		var channel, key, velocity uint8
+		switch {
+		case evt.message.GetNoteStart(&channel, &key, &velocity):
+			keepNote := keep(evt.tick)
+			open[[2]uint8{channel, key}] = append(open[[2]uint8{channel, key}], keepNote)
+			if keepNote {
+				kept = append(kept, evt)
+			}
+		case evt.message.GetNoteEnd(&channel, &key):
+			if pending := open[[2]uint8{channel, key}]; len(pending) > 0 {
+				if pending[0] {
+					kept = append(kept, evt)
+				}
+				open[[2]uint8{channel, key}] = pending[1:]
+			}
+		default:
+			if withOther {
+				kept = append(kept, evt)
+			}
+		}
+	}
+	return kept
+}
+
+// arrangedDrumNotes generates the drum groove, with each section's drums in its bars
+func arrangedDrumNotes(track *parser.Track, totalBars int, timeSig parser.TimeSignature) []DrumNote {
+	dynamics := track.GetBarDynamics()
+	notes := GenerateDrumPatternForMeter(totalBars, track.Drums, timeSig, dynamics)
+
+	generated := make(map[string][]DrumNote)
+	for _, occurrence := range track.GetSectionOccurrences() {
+		if occurrence.Section == nil || occurrence.Section.Drums == nil {
+			continue
+		}
+		override, ok := generated[occurrence.Name]
+		if !ok {
+			override = GenerateDrumPatternForMeter(totalBars, occurrence.Section.Drums, timeSig, dynamics)
+			generated[occurrence.Name] = override
+		}
+		start, end := sectionTicks(occurrence, timeSig.TicksPerBar())
+		var spliced []DrumNote
+		for _, note := range notes {
+			if note.Tick < start || note.Tick >= end {
+				spliced = append(spliced, note)
+			}
+		}
+		for _, note := range override {
+			if note.Tick >= start && note.Tick < end {
+				spliced = append(spliced, note)
+			}
+		}
+		notes = spliced
+	}
+	return notes
+}
+
+// hasDrums reports whether the track or any of its sections has drums
+func hasDrums(track *parser.Track) bool {
+	if track.Drums != nil {
+		return true
+	}
+	for _, section := range track.Sections {
+		if section.Drums != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// arrangedMelodyNotes generates the melody, with each section's style in its bars
+func arrangedMelodyNotes(track *parser.Track, chords []parser.Chord, config *MelodyConfig, ticksPerBar uint32) []MelodyNote {
+	notes := GenerateMelody(chords, track.Info.Key, track.Info.Style, config, ticksPerBar)
+
+	generated := make(map[string][]MelodyNote)
+	for _, occurrence := range track.GetSectionOccurrences() {
+		if occurrence.Section == nil || occurrence.Section.Style == "" {
+			continue
+		}
+		override, ok := generated[occurrence.Name]
+		if !ok {
+			override = GenerateMelody(chords, track.Info.Key, occurrence.Section.Style, config, ticksPerBar)
+			generated[occurrence.Name] = override
+		}
+		start, end := sectionTicks(occurrence, ticksPerBar)
+		var spliced []MelodyNote
+		for _, note := range notes {
+			if note.Tick < start || note.Tick >= end {
+				spliced = append(spliced, note)
+			}
+		}
+		for _, note := range override {
+			if note.Tick >= start && note.Tick < end {
+				spliced = append(spliced, note)
+			}
+		}
+		notes = spliced
+	}
+	sort.SliceStable(notes, func(i, j int) bool {
+		return notes[i].Tick < notes[j].Tick
+	})
+	return notes
+}
+
+// arrangedTablature generates the fingerstyle tablature, with each section's style
+// choosing the pattern in its bars (an explicit pattern type applies everywhere)
+func arrangedTablature(track *parser.Track, config TablatureConfig) *Tablature {
+	tablature := GenerateTablature(track, config)
+	if tablature == nil || config.PatternType != "" {
+		return tablature
+	}
+
+	generated := make(map[string]*Tablature)
+	for _, occurrence := range track.GetSectionOccurrences() {
+		if occurrence.Section == nil || occurrence.Section.Style == "" {
+			continue
+		}
+		override, ok := generated[occurrence.Name]
+		if !ok {
+			styled := *track
+			styled.Info.Style = occurrence.Section.Style
+			override = GenerateTablature(&styled, config)
+			generated[occurrence.Name] = override
+		}
+		if override == nil {
+			continue
+		}
+		for i, bar := range tablature.Bars {
+			// Tab bars are numbered from 1
+			if bar.BarNumber > occurrence.StartBar && bar.BarNumber <= occurrence.EndBar && i < len(override.Bars) {
+				tablature.Bars[i] = override.Bars[i]
+			}
+		}
+	}
+	return tablature
+}
+
+// SectionInstrumentChanges returns the chord instrument at the start of the song and
+// wherever a section changes it (section instrument, then the section's
+// rhythm.instrument, then the track's). Nil when no section sets an instrument.
+func SectionInstrumentChanges(track *parser.Track) []InstrumentChange {
+	trackInstrument := ""
+	if track.Rhythm != nil {
+		trackInstrument = track.Rhythm.Instrument
+	}
+
+	var changes []InstrumentChange
+	current := trackInstrument
+	ticksPerBar := track.GetTimeSignature().TicksPerBar()
+	for _, occurrence := range track.GetSectionOccurrences() {
+		instrument := trackInstrument
+		if section := occurrence.Section; section != nil {
+			if section.Instrument != "" {
+				instrument = section.Instrument
+			} else if section.Rhythm != nil && section.Rhythm.Instrument != "" {
+				instrument = section.Rhythm.Instrument
+			}
+		}
+		if instrument != current {
+			start, _ := sectionTicks(occurrence, ticksPerBar)
+			changes = append(changes, InstrumentChange{Tick: start, Channel: 0, Instrument: instrument})
+			current = instrument
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	// Start from the track's instrument so a seek back can restore it
+	if changes[0].Tick > 0 {
+		changes = append([]InstrumentChange{{Tick: 0, Channel: 0, Instrument: trackInstrument}}, changes...)
+	}
+	return changes
+}
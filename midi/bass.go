@@ -6,27 +6,50 @@ import (
 
 // BassNote represents a single bass note with timing
 type BassNote struct {
-	Note     uint8   // MIDI note number
-	Tick     uint32  // When to play (in ticks)
-	Duration uint32  // How long to hold (in ticks)
-	Velocity uint8   // Note velocity (volume)
+	Note     uint8  // MIDI note number
+	Tick     uint32 // When to play (in ticks)
+	Duration uint32 // How long to hold (in ticks)
+	Velocity uint8  // Note velocity (volume)
 }
 
-// GenerateBassLine creates bass notes from a chord progression
-func GenerateBassLine(chords []parser.Chord, bass *parser.Bass, ticksPerBar uint32) []BassNote {
+// BassStyles lists the bass styles GenerateBassLine knows (keep in sync with its switch)
+var BassStyles = []string{
+	"root", "root_fifth", "walking", "swing_walking", "stride", "boogie", "808", "sub",
+	"808_octave", "edm", "funk", "slap", "funk_simple", "ska", "reggae", "one_drop",
+	"country", "country_walk", "train", "disco", "motown", "soul",
+}
+
+// shiftBassOctave moves notes by bass.octave octaves, clamped to the MIDI range
+// (transpose and capo are applied on top at playback)
+func shiftBassOctave(notes []BassNote, bass *parser.Bass) []BassNote {
+	if bass == nil || bass.Octave == 0 {
+		return notes
+	}
+	for i := range notes {
+		notes[i].Note = uint8(max(0, min(127, int(notes[i].Note)+12*bass.Octave)))
+	}
+	return notes
+}
+
+// GenerateBassLine creates bass notes from a chord progression in the given key
+func GenerateBassLine(chords []parser.Chord, bass *parser.Bass, key string, ticksPerBar uint32) []BassNote {
 	if bass == nil {
 		return nil
 	}
 
+	// Walking lines look ahead to the next chord, so they're built for the whole progression
+	switch bass.Style {
+	case "walking":
+		return GenerateWalkingBass(chords, key, bass.Style, ticksPerBar)
+	case "swing_walking":
+		return swingOffbeats(GenerateWalkingBass(chords, key, bass.Style, ticksPerBar), ticksPerBar/4, bass.Swing)
+	case "country_walk":
+		return GenerateCountryWalkBass(chords, key, ticksPerBar)
+	}
+
 	notes := []BassNote{}
 	currentTick := uint32(0)
 
-	// Determine swing ratio (0.5 = straight, 0.67 = triplet swing)
-	swing := 0.5
-	if bass.Swing > 0 {
-		swing = bass.Swing
-	}
-
 	for _, chord := range chords {
 		root := parseBassNote(chord.Symbol) // Use bass note for slash chords (Am/G → G)
 		// Support fractional bars by multiplying float first
@@ -57,67 +80,6 @@ func GenerateBassLine(chords []parser.Chord, bass *parser.Bass, ticksPerBar uint
 				Velocity: 85,
 			})
 
-		case "walking":
-			// Walking bass: root, 3rd, 5th, 6th (or 7th)
-			quarterNote := ticksPerBar / 4
-			third := getThird(chord.Symbol)
-			seventh := getSeventh(chord.Symbol)
-
-			pattern := []uint8{
-				root + 36,        // Root
-				root + 36 + third, // 3rd
-				root + 36 + 7,    // 5th
-				root + 36 + seventh, // 7th or 6th
-			}
-
-			for i, note := range pattern {
-				tick := currentTick + uint32(i)*quarterNote
-				notes = append(notes, BassNote{
-					Note:     note,
-					Tick:     tick,
-					Duration: quarterNote - 10,
-					Velocity: 85,
-				})
-			}
-
-		case "swing_walking":
-			// Swung walking bass (for jazz/blues)
-			quarterNote := ticksPerBar / 4
-			third := getThird(chord.Symbol)
-			seventh := getSeventh(chord.Symbol)
-
-			pattern := []uint8{
-				root + 36,
-				root + 36 + third,
-				root + 36 + 7,
-				root + 36 + seventh,
-			}
-
-			for i, note := range pattern {
-				// Apply swing feel to each beat pair
-				var tick uint32
-				beatPair := i / 2  // Which pair (0 or 1)
-				isOffbeat := i % 2 == 1
-
-				pairStart := currentTick + uint32(beatPair*2)*quarterNote
-
-				if !isOffbeat {
-					// On beats - normal timing
-					tick = pairStart
-				} else {
-					// Off beats - delayed based on swing ratio
-					// swing=0.5 means 50/50 (straight), swing=0.67 means 67/33 (triplet)
-					tick = pairStart + uint32(float64(quarterNote*2)*swing)
-				}
-
-				notes = append(notes, BassNote{
-					Note:     note,
-					Tick:     tick,
-					Duration: quarterNote - 10,
-					Velocity: 85,
-				})
-			}
-
 		case "stride":
 			// Stride bass for ragtime/stride piano: low bass on 1 & 3
 			// The "oom" in "oom-pah" - pairs with stride rhythm style for chords on 2 & 4
@@ -400,12 +362,12 @@ func GenerateBassLine(chords []parser.Chord, bass *parser.Bass, ticksPerBar uint
 				interval uint8
 				vel      uint8
 			}{
-				{0, 0, 95},     // 1 - root
-				{3, fifth, 75}, // 1a - fifth
-				{4, 0, 80},     // 2 - root
-				{7, third, 70}, // 2a - third
-				{8, fifth, 85}, // 3 - fifth
-				{10, 0, 75},    // 3& - root
+				{0, 0, 95},      // 1 - root
+				{3, fifth, 75},  // 1a - fifth
+				{4, 0, 80},      // 2 - root
+				{7, third, 70},  // 2a - third
+				{8, fifth, 85},  // 3 - fifth
+				{10, 0, 75},     // 3& - root
 				{12, third, 80}, // 4 - third
 				{14, fifth, 70}, // 4& - fifth
 			}
@@ -436,6 +398,21 @@ func GenerateBassLine(chords []parser.Chord, bass *parser.Bass, ticksPerBar uint
 	return notes
 }
 
+// swingOffbeats delays the notes on beats 2 and 4 for a swung walking line
+// (swing 0.5 = straight, 0.67 = triplet; 0 = unset, straight)
+func swingOffbeats(notes []BassNote, quarterNote uint32, swing float64) []BassNote {
+	if swing <= 0.5 {
+		return notes
+	}
+	for i := range notes {
+		if notes[i].Tick%(quarterNote*2) == quarterNote {
+			pairStart := notes[i].Tick - quarterNote
+			notes[i].Tick = pairStart + uint32(float64(quarterNote*2)*swing)
+		}
+	}
+	return notes
+}
+
 // getThird returns the third interval (major or minor)
 func getThird(chordSymbol string) uint8 {
 	quality := parseQuality(chordSymbol)
@@ -0,0 +1,67 @@
+package midi
+
+import (
+	"backing-tracks/parser"
+)
+
+// Count-in velocities: beat 1 is accented so the count-off reads clearly
+const (
+	countInAccentVelocity = 127
+	countInBeatVelocity   = 85
+)
+
+// countInVoices returns the drum notes used for beat 1 and the remaining beats
+func countInVoices(sound string) (accent, beat uint8) {
+	switch sound {
+	case "sticks", "stick":
+		return HiWoodBlock, SideStick
+	case "cowbell":
+		return HiWoodBlock, Cowbell
+	default: // "click"
+		return HiWoodBlock, LowWoodBlock
+	}
+}
+
+// GenerateCountIn creates count-off clicks for the given number of bars.
+// Beat 1 of each bar uses an accented, higher-pitched voice; the other
+// beats use the voice selected by sound (click, sticks or cowbell).
+func GenerateCountIn(bars int, sound string, ts parser.TimeSignature) []DrumNote {
+	if bars <= 0 {
+		return nil
+	}
+
+	accent, beat := countInVoices(sound)
+	beatsPerBar := ts.Beats
+	ticksPerBar := ts.TicksPerBar()
+	ticksPerBeat := ts.TicksPerBeat()
+
+	var notes []DrumNote
+	for bar := 0; bar < bars; bar++ {
+		barStartTick := uint32(bar) * ticksPerBar
+		for i := 0; i < beatsPerBar; i++ {
+			note := DrumNote{
+				Note:     beat,
+				Tick:     barStartTick + uint32(i)*ticksPerBeat,
+				Velocity: countInBeatVelocity,
+			}
+			if i == 0 {
+				note.Note = accent
+				note.Velocity = countInAccentVelocity
+			}
+			notes = append(notes, note)
+		}
+	}
+
+	return notes
+}
+
+// shiftDrumNotes moves drum notes later by the given number of ticks
+func shiftDrumNotes(notes []DrumNote, ticks uint32) []DrumNote {
+	if ticks == 0 {
+		return notes
+	}
+	for i := range notes {
+		notes[i].Tick += ticks
+	}
+	return notes
+}
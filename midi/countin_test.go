@@ -0,0 +1,48 @@
+package midi
+
+import (
+	"testing"
+
+	"backing-tracks/parser"
+)
+
+func TestGenerateCountInAccentsBeatOne(t *testing.T) {
+	ts := parser.ParseTimeSignature("4/4")
+	for _, tc := range []struct {
+		sound string
+		beat  uint8
+	}{
+		{"click", LowWoodBlock},
+		{"sticks", SideStick},
+		{"cowbell", Cowbell},
+	} {
+		notes := GenerateCountIn(1, tc.sound, ts)
+		if len(notes) != 4 {
+			t.Fatalf("%s: got %d clicks for one 4/4 bar, want 4", tc.sound, len(notes))
+		}
+
+		if notes[0].Tick != 0 || notes[0].Note != HiWoodBlock || notes[0].Velocity != 127 {
+			t.Errorf("%s: beat 1 = note %d velocity %d at tick %d, want HiWoodBlock (%d) velocity 127 at tick 0",
+				tc.sound, notes[0].Note, notes[0].Velocity, notes[0].Tick, HiWoodBlock)
+		}
+		for i, note := range notes[1:] {
+			beat := i + 2
+			if want := uint32(i+1) * ts.TicksPerBeat(); note.Tick != want {
+				t.Errorf("%s: beat %d at tick %d, want %d", tc.sound, beat, note.Tick, want)
+			}
+			if note.Note != tc.beat || note.Velocity != 85 {
+				t.Errorf("%s: beat %d = note %d velocity %d, want note %d velocity 85",
+					tc.sound, beat, note.Note, note.Velocity, tc.beat)
+			}
+			if note.Note == notes[0].Note || note.Velocity >= notes[0].Velocity {
+				t.Errorf("%s: beat %d is not distinct from the accented beat 1", tc.sound, beat)
+			}
+		}
+	}
+}
+
+func TestGenerateCountInNoBars(t *testing.T) {
+	if notes := GenerateCountIn(0, "click", parser.ParseTimeSignature("4/4")); notes != nil {
+		t.Errorf("got %d clicks for no count-in, want none", len(notes))
+	}
+}
@@ -0,0 +1,66 @@
+package midi
+
+import (
+	"testing"
+)
+
+// cueTrack is a rock beat in three sections of 2 bars each, with section cues
+const cueTrack = `
+track:
+  title: Cues
+  key: C
+  tempo: 120
+drums:
+  style: rock_beat
+  section_cue: true
+sections:
+  - name: Verse
+    chord_progression:
+      pattern: "C G"
+  - name: Chorus
+    chord_progression:
+      pattern: "F G"
+form: [Verse, Chorus, Verse]
+`
+
+func TestSectionCues(t *testing.T) {
+	track := parseTestTrack(t, cueTrack)
+	if !track.Drums.SectionCue {
+		t.Fatal("section_cue: true was not parsed")
+	}
+	ts := track.GetTimeSignature()
+	ticksPerBar := ts.TicksPerBar()
+	notes := GenerateDrumPatternWithDynamics(track.Progression.TotalBars(), track.Drums, ticksPerBar, track.GetBarDynamics())
+	notes = append(notes, GenerateSectionCues(track.Progression.GetSections(), ts)...)
+
+	// Sections start at bars 0, 2 and 4, so bars 1 and 3 lead into a new section
+	cueBars := map[int]bool{1: true, 3: true}
+	for bar := 0; bar < track.Progression.TotalBars(); bar++ {
+		start := uint32(bar) * ticksPerBar
+		var cues []DrumNote
+		for _, note := range notes {
+			if note.Note == SideStick && note.Tick >= start && note.Tick < start+ticksPerBar {
+				cues = append(cues, note)
+			}
+		}
+
+		if !cueBars[bar] {
+			if len(cues) != 0 {
+				t.Errorf("bar %d isn't before a section change but has %d cue hits", bar+1, len(cues))
+			}
+			continue
+		}
+		if len(cues) != ts.Beats {
+			t.Errorf("bar %d has %d cue hits, want one per beat (%d)", bar+1, len(cues), ts.Beats)
+			continue
+		}
+		for beat, cue := range cues {
+			if want := start + uint32(beat)*ts.TicksPerBeat(); cue.Tick != want {
+				t.Errorf("bar %d cue %d at tick %d, want %d", bar+1, beat+1, cue.Tick, want)
+			}
+			if beat > 0 && cue.Velocity <= cues[beat-1].Velocity {
+				t.Errorf("bar %d cue %d velocity %d, want louder than %d (a crescendo)", bar+1, beat+1, cue.Velocity, cues[beat-1].Velocity)
+			}
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package midi
 
 import (
+	"sort"
+	"strconv"
+
 	"backing-tracks/parser"
 )
 
@@ -13,16 +16,69 @@ type DrumNote struct {
 
 // GM Drum Map (General MIDI standard percussion)
 const (
-	KickDrum      = 36 // Bass Drum 1
-	SnareDrum     = 38 // Acoustic Snare
-	ClosedHihat   = 42 // Closed Hi-Hat
-	OpenHihat     = 46 // Open Hi-Hat
-	RideCymbal    = 51 // Ride Cymbal 1
-	CrashCymbal   = 49 // Crash Cymbal 1
+	KickDrum     = 36 // Bass Drum 1
+	SnareDrum    = 38 // Acoustic Snare
+	ClosedHihat  = 42 // Closed Hi-Hat
+	OpenHihat    = 46 // Open Hi-Hat
+	RideCymbal   = 51 // Ride Cymbal 1
+	CrashCymbal  = 49 // Crash Cymbal 1
+	SideStick    = 37 // Side Stick
+	Cowbell      = 56 // Cowbell
+	HiWoodBlock  = 76 // Hi Wood Block
+	LowWoodBlock = 77 // Low Wood Block
+	LowTom       = 45 // Low Tom
+	LowMidTom    = 47 // Low-Mid Tom
+	HiMidTom     = 48 // Hi-Mid Tom
+	HandClap     = 39 // Hand Clap
+	Tambourine   = 54 // Tambourine
 )
 
+// toms are the GM toms from high to low; a tom voice steps down them through the bar
+var toms = []uint8{50, HiMidTom, LowMidTom, LowTom, 43, 41}
+
+// DrumSounds lists the names a drum voice's sound can be set to
+var DrumSounds = []string{
+	"kick", "snare", "electric_snare", "side_stick", "clap", "closed_hihat", "pedal_hihat",
+	"open_hihat", "ride", "ride_bell", "crash", "splash", "china", "tambourine", "cowbell",
+	"tom_floor_low", "tom_floor_high", "tom_low", "tom_low_mid", "tom_hi_mid", "tom_high",
+	"wood_block_high", "wood_block_low",
+}
+
+// drumSoundNotes maps the DrumSounds names to GM drum notes
+var drumSoundNotes = map[string]uint8{
+	"kick": KickDrum, "snare": SnareDrum, "electric_snare": 40, "side_stick": SideStick,
+	"clap": HandClap, "closed_hihat": ClosedHihat, "pedal_hihat": 44, "open_hihat": OpenHihat,
+	"ride": RideCymbal, "ride_bell": 53, "crash": CrashCymbal, "splash": 55, "china": 52,
+	"tambourine": Tambourine, "cowbell": Cowbell,
+	"tom_floor_low": 41, "tom_floor_high": 43, "tom_low": LowTom, "tom_low_mid": LowMidTom,
+	"tom_hi_mid": HiMidTom, "tom_high": 50,
+	"wood_block_high": HiWoodBlock, "wood_block_low": LowWoodBlock,
+}
+
+// DrumSoundNote returns the GM drum note for a sound name or note number, and
+// whether the sound is known. Unknown and empty sounds return fallback.
+func DrumSoundNote(sound string, fallback uint8) (uint8, bool) {
+	if sound == "" {
+		return fallback, true
+	}
+	if note, ok := drumSoundNotes[sound]; ok {
+		return note, true
+	}
+	// GM percussion runs from 27 (high Q) to 87 (open surdo)
+	if n, err := strconv.Atoi(sound); err == nil && n >= 27 && n <= 87 {
+		return uint8(n), true
+	}
+	return fallback, false
+}
+
 // GenerateDrumPattern creates drum notes for the entire track
 func GenerateDrumPattern(totalBars int, drums *parser.Drums, ticksPerBar uint32) []DrumNote {
+	return GenerateDrumPatternWithDynamics(totalBars, drums, ticksPerBar, nil)
+}
+
+// GenerateDrumPatternWithDynamics creates drum notes that follow per-bar dynamic levels
+// (see parser.Track.GetBarDynamics). A nil dynamics slice plays every bar at the base level.
+func GenerateDrumPatternWithDynamics(totalBars int, drums *parser.Drums, ticksPerBar uint32, dynamics []float64) []DrumNote {
 	if drums == nil {
 		return nil
 	}
@@ -36,42 +92,155 @@ func GenerateDrumPattern(totalBars int, drums *parser.Drums, ticksPerBar uint32)
 	}
 	baseVelocity := uint8(float64(100) * intensity)
 
-	// Use style presets if no explicit patterns
+	// Use style presets if no explicit patterns (percussion voices play along)
 	if drums.Style != "" && drums.Kick == nil && drums.Snare == nil && drums.Hihat == nil {
-		return generatePresetPattern(drums.Style, totalBars, ticksPerBar, baseVelocity)
+		notes = generatePresetPattern(drums.Style, drums.Accent, drums.Swing, totalBars, ticksPerBar, baseVelocity, dynamics)
+		for bar := 0; bar < totalBars; bar++ {
+			velocity := dynamicVelocity(baseVelocity, barDynamicLevel(dynamics, bar))
+			notes = append(notes, generatePercussionBar(drums, uint32(bar)*ticksPerBar, ticksPerBar, ticksPerBar/4, velocity)...)
+		}
+		return addPeriodicFills(notes, drums, totalBars, ticksPerBar, ticksPerBar/2, dynamics)
 	}
 
 	// Generate from explicit patterns
 	for bar := 0; bar < totalBars; bar++ {
 		barStartTick := uint32(bar) * ticksPerBar
+		velocity := dynamicVelocity(baseVelocity, barDynamicLevel(dynamics, bar))
+		notes = append(notes, generateExplicitBar(drums, barStartTick, ticksPerBar, ticksPerBar/4, velocity)...)
+	}
 
-		// Kick drum
-		if drums.Kick != nil {
-			notes = append(notes, generateDrumVoice(drums.Kick, KickDrum, barStartTick, ticksPerBar, baseVelocity+10)...)
-		}
+	return addPeriodicFills(notes, drums, totalBars, ticksPerBar, ticksPerBar/2, dynamics)
+}
 
-		// Snare drum
-		if drums.Snare != nil {
-			notes = append(notes, generateDrumVoice(drums.Snare, SnareDrum, barStartTick, ticksPerBar, baseVelocity)...)
-		}
+// generateExplicitBar creates one bar of the kick/snare/hihat/ride patterns given in the BTML file
+func generateExplicitBar(drums *parser.Drums, barStartTick, ticksPerBar, ticksPerBeat uint32, baseVelocity uint8) []DrumNote {
+	notes := []DrumNote{}
+
+	// Kick drum
+	if drums.Kick != nil {
+		notes = append(notes, generateDrumVoice(drums.Kick, KickDrum, barStartTick, ticksPerBar, ticksPerBeat, baseVelocity+10)...)
+	}
+
+	// Snare drum
+	if drums.Snare != nil {
+		notes = append(notes, generateDrumVoice(drums.Snare, SnareDrum, barStartTick, ticksPerBar, ticksPerBeat, baseVelocity)...)
+	}
+
+	// Hi-hat
+	if drums.Hihat != nil {
+		notes = append(notes, generateDrumVoice(drums.Hihat, ClosedHihat, barStartTick, ticksPerBar, ticksPerBeat, baseVelocity-20)...)
+	}
 
-		// Hi-hat
-		if drums.Hihat != nil {
-			notes = append(notes, generateDrumVoice(drums.Hihat, ClosedHihat, barStartTick, ticksPerBar, baseVelocity-20)...)
+	// Ride cymbal
+	if drums.Ride != nil {
+		notes = append(notes, generateDrumVoice(drums.Ride, RideCymbal, barStartTick, ticksPerBar, ticksPerBeat, baseVelocity-15)...)
+	}
+
+	return append(notes, generatePercussionBar(drums, barStartTick, ticksPerBar, ticksPerBeat, baseVelocity)...)
+}
+
+// generatePercussionBar creates one bar of the tom, clap, tambourine and cowbell patterns
+func generatePercussionBar(drums *parser.Drums, barStartTick, ticksPerBar, ticksPerBeat uint32, baseVelocity uint8) []DrumNote {
+	notes := []DrumNote{}
+
+	// Toms: each hit one tom lower, unless the pattern picks a sound
+	if drums.Tom != nil {
+		hits := generateDrumVoice(drums.Tom, toms[0], barStartTick, ticksPerBar, ticksPerBeat, baseVelocity)
+		if drums.Tom.Sound == "" {
+			sort.SliceStable(hits, func(i, j int) bool { return hits[i].Tick < hits[j].Tick })
+			for i := range hits {
+				hits[i].Note = toms[i%len(toms)]
+			}
 		}
+		notes = append(notes, hits...)
+	}
 
-		// Ride cymbal
-		if drums.Ride != nil {
-			notes = append(notes, generateDrumVoice(drums.Ride, RideCymbal, barStartTick, ticksPerBar, baseVelocity-15)...)
+	if drums.Clap != nil {
+		notes = append(notes, generateDrumVoice(drums.Clap, HandClap, barStartTick, ticksPerBar, ticksPerBeat, baseVelocity)...)
+	}
+	if drums.Tambourine != nil {
+		notes = append(notes, generateDrumVoice(drums.Tambourine, Tambourine, barStartTick, ticksPerBar, ticksPerBeat, baseVelocity-20)...)
+	}
+	if drums.Cowbell != nil {
+		notes = append(notes, generateDrumVoice(drums.Cowbell, Cowbell, barStartTick, ticksPerBar, ticksPerBeat, baseVelocity-15)...)
+	}
+
+	return notes
+}
+
+// GenerateSectionCues creates a rim-click cue in the last bar before each section change
+// Side-stick hits on every beat with a small crescendo (50 to 85) into the new section
+func GenerateSectionCues(sections []parser.SectionInfo, ts parser.TimeSignature) []DrumNote {
+	notes := []DrumNote{}
+	ticksPerBar := ts.TicksPerBar()
+	ticksPerBeat := ts.TicksPerBeat()
+
+	for _, section := range sections {
+		if section.StartBar == 0 {
+			continue // Nothing to cue before the first bar
+		}
+		cueStartTick := uint32(section.StartBar-1) * ticksPerBar
+		for beat := 0; beat < ts.Beats; beat++ {
+			vel := uint8(85)
+			if ts.Beats > 1 {
+				vel = uint8(50 + 35*beat/(ts.Beats-1))
+			}
+			notes = append(notes, DrumNote{
+				Note:     SideStick,
+				Tick:     cueStartTick + uint32(beat)*ticksPerBeat,
+				Velocity: vel,
+			})
 		}
 	}
 
 	return notes
 }
 
-// generateDrumVoice creates notes for a single drum voice
-func generateDrumVoice(pattern *parser.DrumPattern, note uint8, startTick, ticksPerBar uint32, velocity uint8) []DrumNote {
+// drumAccentBoost is how much harder accented kick and snare hits are played
+const drumAccentBoost = 15
+
+// accentDrumBeats boosts the kick and snare hits of one bar that land on an accent beat
+func accentDrumBeats(notes []DrumNote, accentBeats map[int]bool, barStartTick, ticksPerBeat uint32) {
+	for i, note := range notes {
+		if note.Note != KickDrum && note.Note != SnareDrum {
+			continue
+		}
+		offset := note.Tick - barStartTick
+		if offset%ticksPerBeat == 0 && accentBeats[int(offset/ticksPerBeat)+1] {
+			notes[i].Velocity = clampVelocity(int(note.Velocity) + drumAccentBoost)
+		}
+	}
+}
+
+// swingDrumHits delays the hi-hat and ride hits of one bar on the off-beat eighths
+// (the "and" of each beat) to where swing puts them, like generateCustomPattern
+// does for chords: 0.5 = straight, 0.67 = triplet
+func swingDrumHits(notes []DrumNote, swing float64, barStartTick, ticksPerBeat uint32) {
+	if swing <= 0.5 {
+		return
+	}
+	ticksPerEighth := ticksPerBeat / 2
+	delay := uint32(float64(ticksPerEighth) * (swing - 0.5) * 2)
+	for i, note := range notes {
+		switch note.Note {
+		case ClosedHihat, OpenHihat, 44, RideCymbal:
+			if (note.Tick-barStartTick)%ticksPerBeat == ticksPerEighth {
+				notes[i].Tick += delay
+			}
+		}
+	}
+}
+
+// generateDrumVoice creates notes for a single drum voice, on the pattern's sound
+// if it sets one and on note otherwise
+func generateDrumVoice(pattern *parser.DrumPattern, note uint8, startTick, ticksPerBar, ticksPerBeat uint32, velocity uint8) []DrumNote {
 	notes := []DrumNote{}
+	note, _ = DrumSoundNote(pattern.Sound, note)
+
+	// Step pattern ("X..x..X."): the bar is divided by the pattern length
+	if pattern.Pattern != "" {
+		return generateStepPattern(pattern.Pattern, note, startTick, ticksPerBar, velocity)
+	}
 
 	// Euclidean rhythm
 	if pattern.Euclidean != nil {
@@ -91,11 +260,10 @@ func generateDrumVoice(pattern *parser.DrumPattern, note uint8, startTick, ticks
 
 	// Explicit beats
 	if pattern.Beats != nil && len(pattern.Beats) > 0 {
-		quarterNote := ticksPerBar / 4
 		for _, beat := range pattern.Beats {
 			notes = append(notes, DrumNote{
 				Note:     note,
-				Tick:     startTick + uint32(beat-1)*quarterNote,
+				Tick:     startTick + uint32(beat-1)*ticksPerBeat,
 				Velocity: velocity,
 			})
 		}
@@ -104,6 +272,32 @@ func generateDrumVoice(pattern *parser.DrumPattern, note uint8, startTick, ticks
 	return notes
 }
 
+// generateStepPattern creates hits from a step pattern string, one step per character:
+// X = accented hit (velocity+15), x = normal hit, . = rest
+func generateStepPattern(pattern string, note uint8, startTick, ticksPerBar uint32, velocity uint8) []DrumNote {
+	notes := []DrumNote{}
+	ticksPerStep := ticksPerBar / uint32(len(pattern))
+
+	for i, char := range pattern {
+		var vel uint8
+		switch char {
+		case 'X':
+			vel = clampVelocity(int(velocity) + 15)
+		case 'x':
+			vel = clampVelocity(int(velocity))
+		default:
+			continue // Rest
+		}
+		notes = append(notes, DrumNote{
+			Note:     note,
+			Tick:     startTick + uint32(i)*ticksPerStep,
+			Velocity: vel,
+		})
+	}
+
+	return notes
+}
+
 // generateEuclideanRhythm implements Bjorklund's algorithm for Euclidean rhythms
 func generateEuclideanRhythm(hits, steps, rotation int) []bool {
 	if hits >= steps {
@@ -168,12 +362,26 @@ func generateEuclideanRhythm(hits, steps, rotation int) []bool {
 	return result
 }
 
+// DrumStyles lists the preset drum styles generatePresetPattern knows (keep in sync with the switch)
+var DrumStyles = []string{
+	"rock_beat", "shuffle", "blues_shuffle", "jazz_swing", "four_on_floor", "edm", "trap", "ska",
+	"reggae", "one_drop", "country", "train", "disco", "motown", "soul", "flamenco", "rumba",
+	"bossa", "bossa_nova", "samba", "half_time_shuffle",
+}
+
 // generatePresetPattern creates preset drum patterns
-func generatePresetPattern(style string, totalBars int, ticksPerBar uint32, velocity uint8) []DrumNote {
+// Each bar's velocity follows its dynamic level; quiet bars also drop the off-beat hi-hats.
+// Kick and snare hits on the accent beats ("2,4", "" = none) are played harder, and
+// swing above 0.5 delays the off-beat eighth hi-hats and ride.
+func generatePresetPattern(style, accent string, swing float64, totalBars int, ticksPerBar uint32, baseVelocity uint8, dynamics []float64) []DrumNote {
 	notes := []DrumNote{}
+	accentBeats := parseAccentBeats(accent)
 
 	for bar := 0; bar < totalBars; bar++ {
 		barStartTick := uint32(bar) * ticksPerBar
+		level := barDynamicLevel(dynamics, bar)
+		velocity := dynamicVelocity(baseVelocity, level)
+		barStart := len(notes)
 
 		switch style {
 		case "rock_beat":
@@ -224,10 +432,32 @@ func generatePresetPattern(style string, totalBars int, ticksPerBar uint32, velo
 			// Flamenco rumba (cajon style)
 			notes = append(notes, flamencoBeat(barStartTick, ticksPerBar, velocity)...)
 
+		case "bossa", "bossa_nova":
+			// Bossa nova: side-stick cross-rhythm over a steady kick
+			notes = append(notes, bossaBeat(barStartTick, ticksPerBar, velocity)...)
+
+		case "samba":
+			// Samba: surdo on 2 and 4, busy 16ths and agogo
+			notes = append(notes, sambaBeat(barStartTick, ticksPerBar, velocity)...)
+
+		case "half_time_shuffle":
+			// Purdie-style half-time shuffle with ghosted snare
+			notes = append(notes, halfTimeShuffle(barStartTick, ticksPerBar, velocity)...)
+
 		default:
 			// Simple 4/4 beat
 			notes = append(notes, rockBeat(barStartTick, ticksPerBar, velocity)...)
 		}
+
+		accentDrumBeats(notes[barStart:], accentBeats, barStartTick, ticksPerBar/4)
+		swingDrumHits(notes[barStart:], swing, barStartTick, ticksPerBar/4)
+
+		// Sparser groove at low dynamics, busier at high
+		if level < thinDynamicLevel {
+			notes = append(notes[:barStart], thinOffbeatHats(notes[barStart:], barStartTick, ticksPerBar/4)...)
+		} else if level >= busyDynamicLevel {
+			notes = append(notes[:barStart], busyBar(notes[barStart:], barStartTick, ticksPerBar)...)
+		}
 	}
 
 	return notes
@@ -325,8 +555,8 @@ func bluesShuffle(startTick, ticksPerBar uint32, velocity uint8) []DrumNote {
 	notes = append(notes, DrumNote{Note: KickDrum, Tick: startTick + 11*tripletEighth, Velocity: velocity - 5}) // "a" of 4
 
 	// Snare: 2 and 4 with ghost notes
-	notes = append(notes, DrumNote{Note: SnareDrum, Tick: startTick + 3*tripletEighth, Velocity: velocity})      // beat 2
-	notes = append(notes, DrumNote{Note: SnareDrum, Tick: startTick + 9*tripletEighth, Velocity: velocity})      // beat 4
+	notes = append(notes, DrumNote{Note: SnareDrum, Tick: startTick + 3*tripletEighth, Velocity: velocity}) // beat 2
+	notes = append(notes, DrumNote{Note: SnareDrum, Tick: startTick + 9*tripletEighth, Velocity: velocity}) // beat 4
 	// Ghost notes (very soft)
 	notes = append(notes, DrumNote{Note: SnareDrum, Tick: startTick + 2*tripletEighth, Velocity: velocity - 35}) // before 2
 	notes = append(notes, DrumNote{Note: SnareDrum, Tick: startTick + 8*tripletEighth, Velocity: velocity - 35}) // before 4
@@ -654,6 +884,105 @@ func flamencoBeat(startTick, ticksPerBar uint32, velocity uint8) []DrumNote {
 	return notes
 }
 
+// bossaBeat generates a bossa nova beat
+func bossaBeat(startTick, ticksPerBar uint32, velocity uint8) []DrumNote {
+	notes := []DrumNote{}
+	eighthNote := ticksPerBar / 8
+	sixteenthNote := ticksPerBar / 16
+
+	// Kick: dotted "1 . . 2 3 . . 4" pulse (the surdo figure played on kick)
+	kickPositions := []int{0, 3, 4, 7}
+	for _, pos := range kickPositions {
+		vel := velocity - 5
+		if pos%4 == 0 {
+			vel = velocity + 5
+		}
+		notes = append(notes, DrumNote{Note: KickDrum, Tick: startTick + uint32(pos)*eighthNote, Velocity: vel})
+	}
+
+	// Side stick: clave-like cross-rhythm across the 16ths
+	rimPositions := []int{0, 3, 6, 10, 12}
+	for _, pos := range rimPositions {
+		notes = append(notes, DrumNote{Note: SideStick, Tick: startTick + uint32(pos)*sixteenthNote, Velocity: velocity - 5})
+	}
+
+	// Soft closed hi-hat on straight 8ths
+	for i := 0; i < 8; i++ {
+		notes = append(notes, DrumNote{Note: ClosedHihat, Tick: startTick + uint32(i)*eighthNote, Velocity: velocity - 30})
+	}
+
+	return notes
+}
+
+// sambaBeat generates a samba beat
+func sambaBeat(startTick, ticksPerBar uint32, velocity uint8) []DrumNote {
+	notes := []DrumNote{}
+	quarterNote := ticksPerBar / 4
+	sixteenthNote := ticksPerBar / 16
+
+	// Surdo: muted on 1 and 3, open and strong on 2 and 4 (low tom)
+	for beat := 0; beat < 4; beat++ {
+		tick := startTick + uint32(beat)*quarterNote
+		if beat%2 == 0 {
+			notes = append(notes, DrumNote{Note: KickDrum, Tick: tick, Velocity: velocity - 10})
+		} else {
+			notes = append(notes, DrumNote{Note: LowTom, Tick: tick, Velocity: velocity + 10})
+		}
+		// Kick pickup on the "a" before each beat
+		notes = append(notes, DrumNote{Note: KickDrum, Tick: tick + 3*sixteenthNote, Velocity: velocity - 20})
+	}
+
+	// Busy 16ths (shaker feel), accenting the last 16th of each beat
+	for i := 0; i < 16; i++ {
+		vel := velocity - 30
+		if i%4 == 3 {
+			vel = velocity - 10
+		}
+		notes = append(notes, DrumNote{Note: ClosedHihat, Tick: startTick + uint32(i)*sixteenthNote, Velocity: vel})
+	}
+
+	// Agogo bell figure
+	agogoPositions := []int{0, 3, 6, 8, 10, 13}
+	for _, pos := range agogoPositions {
+		notes = append(notes, DrumNote{Note: Cowbell, Tick: startTick + uint32(pos)*sixteenthNote, Velocity: velocity - 20})
+	}
+
+	return notes
+}
+
+// halfTimeShuffle generates a Purdie-style half-time shuffle
+// Backbeat on 3 only, ghost notes filling the triplet upbeats
+func halfTimeShuffle(startTick, ticksPerBar uint32, velocity uint8) []DrumNote {
+	notes := []DrumNote{}
+	tripletEighth := ticksPerBar / 12
+
+	// Kick: 1 and the "a" of 2 and 4
+	notes = append(notes, DrumNote{Note: KickDrum, Tick: startTick, Velocity: velocity + 10})
+	notes = append(notes, DrumNote{Note: KickDrum, Tick: startTick + 5*tripletEighth, Velocity: velocity - 5})
+	notes = append(notes, DrumNote{Note: KickDrum, Tick: startTick + 11*tripletEighth, Velocity: velocity - 5})
+
+	// Snare: half-time backbeat on 3
+	notes = append(notes, DrumNote{Note: SnareDrum, Tick: startTick + 6*tripletEighth, Velocity: velocity + 10})
+
+	// Ghost notes on the triplet upbeats
+	ghostPositions := []int{2, 5, 8, 11}
+	for _, pos := range ghostPositions {
+		notes = append(notes, DrumNote{Note: SnareDrum, Tick: startTick + uint32(pos)*tripletEighth, Velocity: velocity - 35})
+	}
+
+	// Hi-hat: swung 8ths
+	hatPattern := []int{0, 2, 3, 5, 6, 8, 9, 11}
+	for _, pos := range hatPattern {
+		vel := velocity - 15
+		if pos%3 == 2 {
+			vel -= 10 // Softer on triplet upbeats
+		}
+		notes = append(notes, DrumNote{Note: ClosedHihat, Tick: startTick + uint32(pos)*tripletEighth, Velocity: vel})
+	}
+
+	return notes
+}
+
 // min helper function
 func min(a, b int) int {
 	if a < b {
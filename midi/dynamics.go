@@ -0,0 +1,134 @@
+package midi
+
+import (
+	"backing-tracks/parser"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// Drum patterns drop their off-beat hi-hats below thinDynamicLevel (p and softer)
+// and add sixteenth-note hi-hats and a kick push from busyDynamicLevel (f and louder)
+const (
+	thinDynamicLevel = 0.5
+	busyDynamicLevel = 0.85
+)
+
+// Velocity limits for dynamics scaling, leaving headroom for pattern accents/ghost notes
+const (
+	minDynamicVelocity = 40
+	maxDynamicVelocity = 105
+)
+
+// barDynamicLevel returns the dynamic level for a bar (default level if not set)
+func barDynamicLevel(dynamics []float64, bar int) float64 {
+	if bar < 0 || bar >= len(dynamics) {
+		return parser.DefaultDynamicLevel
+	}
+	return dynamics[bar]
+}
+
+// dynamicVelocity scales a base velocity by a dynamic level relative to the default (mf)
+func dynamicVelocity(base uint8, level float64) uint8 {
+	if level == parser.DefaultDynamicLevel {
+		return base
+	}
+	v := float64(base) * level / parser.DefaultDynamicLevel
+	if v > maxDynamicVelocity {
+		v = maxDynamicVelocity
+	}
+	if low := float64(min(int(base), minDynamicVelocity)); v < low {
+		v = low
+	}
+	return uint8(v)
+}
+
+// thinOffbeatHats removes hi-hat hits that don't fall on a beat
+func thinOffbeatHats(notes []DrumNote, barStartTick, ticksPerBeat uint32) []DrumNote {
+	var kept []DrumNote
+	for _, note := range notes {
+		isHat := note.Note == ClosedHihat || note.Note == OpenHihat
+		if isHat && (note.Tick-barStartTick)%ticksPerBeat != 0 {
+			continue
+		}
+		kept = append(kept, note)
+	}
+	return kept
+}
+
+// busyBar adds a ghosted hi-hat after every on-grid eighth-note hat and a kick on
+// the last off-beat of the bar, pushing into the next one
+func busyBar(notes []DrumNote, barStartTick, ticksPerBar uint32) []DrumNote {
+	const eighth, sixteenth = 240, 120
+	hats := make(map[uint32]bool)
+	for _, note := range notes {
+		if note.Note == ClosedHihat || note.Note == OpenHihat {
+			hats[note.Tick] = true
+		}
+	}
+
+	var added []DrumNote
+	for _, note := range notes {
+		onEighth := (note.Tick-barStartTick)%eighth == 0
+		if note.Note == ClosedHihat && onEighth && !hats[note.Tick+sixteenth] {
+			added = append(added, DrumNote{Note: ClosedHihat, Tick: note.Tick + sixteenth, Velocity: uint8(int(note.Velocity) * 3 / 5)})
+		}
+	}
+
+	// The push takes the velocity of the bar's first kick (no kick, no push)
+	push := barStartTick + ticksPerBar - eighth
+	var kickVelocity uint8
+	for _, note := range notes {
+		if note.Note != KickDrum {
+			continue
+		}
+		if note.Tick == push {
+			kickVelocity = 0
+			break
+		}
+		if kickVelocity == 0 {
+			kickVelocity = note.Velocity
+		}
+	}
+	if kickVelocity > 0 {
+		added = append(added, DrumNote{Note: KickDrum, Tick: push, Velocity: kickVelocity})
+	}
+	return append(notes, added...)
+}
+
+// noteVelocityForLevel scales a melodic note's velocity by a dynamic level relative
+// to the default (mf); unlike drums there is no headroom cap below 127
+func noteVelocityForLevel(base uint8, level float64) uint8 {
+	if level == parser.DefaultDynamicLevel {
+		return base
+	}
+	v := float64(base) * level / parser.DefaultDynamicLevel
+	if v > 127 {
+		v = 127
+	}
+	if v < 1 {
+		v = 1
+	}
+	return uint8(v)
+}
+
+// applyDynamics scales the note-on velocities of events to the dynamic level of their bar
+func applyDynamics(events []midiEvent, dynamics []float64, ticksPerBar uint32) {
+	for i, evt := range events {
+		var channel, key, velocity uint8
+		if evt.message.GetNoteStart(&channel, &key, &velocity) {
+			level := barDynamicLevel(dynamics, int(evt.tick/ticksPerBar))
+			events[i].message = midi.NoteOn(channel, key, noteVelocityForLevel(velocity, level))
+		}
+	}
+}
+
+// applyPlaybackDynamics scales the note-on velocities of the pitched (non-drum)
+// events to the dynamic level of their bar
+func applyPlaybackDynamics(events []PlaybackEvent, dynamics []float64, ticksPerBar uint32) {
+	for i, evt := range events {
+		if evt.IsNoteOn && evt.Channel != 9 {
+			level := barDynamicLevel(dynamics, int(evt.Tick/ticksPerBar))
+			events[i].Velocity = noteVelocityForLevel(evt.Velocity, level)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package midi
+
+import (
+	"testing"
+)
+
+// dynamicsTrack is a rock beat with a pp section followed by an f section
+const dynamicsTrack = `
+track:
+  title: Dynamics
+  key: C
+  tempo: 120
+drums:
+  style: rock_beat
+sections:
+  - name: Soft
+    dynamics: pp
+    chord_progression:
+      pattern: "C"
+  - name: Loud
+    dynamics: f
+    chord_progression:
+      pattern: "C"
+form: [Soft, Loud]
+`
+
+// barDrumStats returns the hi-hat count and the loudest kick or snare of one bar
+func barDrumStats(notes []DrumNote, bar int, ticksPerBar uint32) (hats int, loudest uint8) {
+	start, end := uint32(bar)*ticksPerBar, uint32(bar+1)*ticksPerBar
+	for _, note := range notes {
+		if note.Tick < start || note.Tick >= end {
+			continue
+		}
+		switch note.Note {
+		case ClosedHihat, OpenHihat:
+			hats++
+		case KickDrum, SnareDrum:
+			loudest = max(loudest, note.Velocity)
+		}
+	}
+	return hats, loudest
+}
+
+func TestDrumDynamics(t *testing.T) {
+	track := parseTestTrack(t, dynamicsTrack)
+	const ticksPerBar = 1920
+	notes := GenerateDrumPatternWithDynamics(track.Progression.TotalBars(), track.Drums, ticksPerBar, track.GetBarDynamics())
+
+	softHats, softVelocity := barDrumStats(notes, 0, ticksPerBar)
+	loudHats, loudVelocity := barDrumStats(notes, 1, ticksPerBar)
+	if softVelocity == 0 || loudVelocity == 0 {
+		t.Fatalf("no kick or snare: pp bar %d, f bar %d", softVelocity, loudVelocity)
+	}
+	if softVelocity >= loudVelocity {
+		t.Errorf("pp bar kick/snare velocity %d, want less than the f bar's %d", softVelocity, loudVelocity)
+	}
+	if softHats >= loudHats {
+		t.Errorf("pp bar has %d hi-hats, want fewer than the f bar's %d", softHats, loudHats)
+	}
+}
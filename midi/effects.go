@@ -0,0 +1,29 @@
+package midi
+
+import (
+	"gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/smf"
+)
+
+// General MIDI effect send controllers
+const (
+	ReverbCC = 91
+	ChorusCC = 93
+)
+
+// EffectLevelCC converts an effect level (0.0-1.0) to a controller value (0-127)
+func EffectLevelCC(level float64) uint8 {
+	if level <= 0 {
+		return 0
+	}
+	if level >= 1 {
+		return 127
+	}
+	return uint8(level*127 + 0.5)
+}
+
+// addEffectSends sets the reverb and chorus sends for a channel at the start of a track
+func addEffectSends(t *smf.Track, channel uint8, reverb, chorus float64) {
+	t.Add(0, midi.ControlChange(channel, ReverbCC, EffectLevelCC(reverb)))
+	t.Add(0, midi.ControlChange(channel, ChorusCC, EffectLevelCC(chorus)))
+}
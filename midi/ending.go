@@ -0,0 +1,118 @@
+package midi
+
+import (
+	"sort"
+
+	"backing-tracks/parser"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// Endings for track.ending. With a crash ending the final bar drops the normal
+// pattern: crash cymbal and kick on beat 1, and the chords and bass struck there
+// ring to the end. A ritard ending slows the last bars instead (see TrackTempoMap).
+const (
+	EndingNone   = "none"
+	EndingCrash  = "crash"
+	EndingRitard = "ritard"
+)
+
+// Endings lists the track.ending values
+var Endings = []string{EndingNone, EndingCrash, EndingRitard}
+
+// endingVelocity is the velocity of the final crash and kick
+const endingVelocity = 115
+
+// strumWindow is how far apart (in ticks) the notes of one chord strike can be
+const strumWindow = 60
+
+// crashEndingBar returns the first and end tick of the final bar when the track
+// has a crash ending
+func crashEndingBar(track *parser.Track, totalTicks, ticksPerBar uint32) (lastBar, end uint32, ok bool) {
+	if track.Info.Ending != EndingCrash || totalTicks < ticksPerBar {
+		return 0, 0, false
+	}
+	return totalTicks - ticksPerBar, totalTicks, true
+}
+
+// endChordEvents replaces the chord strikes in the final bar with the first of
+// them, moved to the downbeat and held to the end
+func endChordEvents(events []midiEvent, lastBar, end uint32) []midiEvent {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].tick < events[j].tick
+	})
+
+	var kept, strike []midiEvent
+	sounding := make(map[[2]uint8]int) // Notes struck before the final bar
+	struck := make(map[[2]uint8]bool)
+	strikeTick := end
+	for _, evt := range events {
+		var channel, key, velocity uint8
+		switch {
+		case evt.message.GetNoteStart(&channel, &key, &velocity):
+			if evt.tick < lastBar {
+				sounding[[2]uint8{channel, key}]++
+				kept = append(kept, evt)
+				break
+			}
+			if strikeTick == end {
+				strikeTick = evt.tick
+			}
+			// A strummed chord spreads its notes over a few ticks; keep the spread
+			if evt.tick < strikeTick+strumWindow && !struck[[2]uint8{channel, key}] {
+				struck[[2]uint8{channel, key}] = true
+				strike = append(strike, midiEvent{lastBar + evt.tick - strikeTick, evt.message})
+			}
+		case evt.message.GetNoteEnd(&channel, &key):
+			// Note-offs of earlier notes stay; those of dropped strikes go
+			if sounding[[2]uint8{channel, key}] > 0 {
+				sounding[[2]uint8{channel, key}]--
+				kept = append(kept, evt)
+			}
+		default:
+			kept = append(kept, evt)
+		}
+	}
+
+	for _, evt := range strike {
+		var channel, key, velocity uint8
+		evt.message.GetNoteStart(&channel, &key, &velocity)
+		kept = append(kept, evt, midiEvent{end, midi.NoteOff(channel, key)})
+	}
+	return kept
+}
+
+// endBassNotes replaces the bass notes in the final bar with the first of them,
+// moved to the downbeat and held to the end
+func endBassNotes(notes []BassNote, lastBar, end uint32) []BassNote {
+	var kept []BassNote
+	var final *BassNote
+	for i, note := range notes {
+		if note.Tick < lastBar {
+			kept = append(kept, note)
+		} else if final == nil || note.Tick < final.Tick {
+			final = &notes[i]
+		}
+	}
+	if final != nil {
+		kept = append(kept, BassNote{Note: final.Note, Tick: lastBar, Duration: end - lastBar, Velocity: final.Velocity})
+	}
+	return kept
+}
+
+// endDrumNotes replaces the drums in the final bar with a crash and kick on beat 1
+func endDrumNotes(notes []DrumNote, lastBar uint32) []DrumNote {
+	if len(notes) == 0 {
+		return notes // No drum part to end
+	}
+	var kept []DrumNote
+	for _, note := range notes {
+		if note.Tick < lastBar {
+			kept = append(kept, note)
+		}
+	}
+	return append(kept,
+		DrumNote{Note: CrashCymbal, Tick: lastBar, Velocity: endingVelocity},
+		DrumNote{Note: KickDrum, Tick: lastBar, Velocity: endingVelocity},
+	)
+}
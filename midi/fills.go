@@ -0,0 +1,134 @@
+package midi
+
+import (
+	"strings"
+
+	"backing-tracks/parser"
+)
+
+// sixteenthTicks is the length of a 16th note (480 ticks per quarter)
+const sixteenthTicks = parser.TicksPerWholeNote / 16
+
+// AddSectionFills replaces the end of the last bar before each section change
+// with a drum fill and puts a crash on the downbeat of the new section.
+// Fills can be turned off with `fills: false` on the drums part.
+func AddSectionFills(notes []DrumNote, sections []parser.SectionInfo, drums *parser.Drums, ts parser.TimeSignature, dynamics []float64) []DrumNote {
+	if drums == nil || !drums.FillsEnabled() {
+		return notes
+	}
+
+	baseVelocity := fillBaseVelocity(drums)
+	ticksPerBar := ts.TicksPerBar()
+	fillLength := sectionFillLength(ts)
+
+	for _, section := range sections {
+		if section.StartBar == 0 {
+			continue // No fill before the first bar
+		}
+		velocity := dynamicVelocity(baseVelocity, barDynamicLevel(dynamics, section.StartBar-1))
+		notes = insertFill(notes, drums.Style, uint32(section.StartBar)*ticksPerBar, fillLength, velocity)
+	}
+
+	return notes
+}
+
+// addPeriodicFills plays a fill at the end of every drums.FillEvery-th bar (none
+// after the last bar), at the drums' intensity
+func addPeriodicFills(notes []DrumNote, drums *parser.Drums, totalBars int, ticksPerBar, fillLength uint32, dynamics []float64) []DrumNote {
+	if drums.FillEvery <= 0 {
+		return notes
+	}
+	baseVelocity := fillBaseVelocity(drums)
+	for bar := drums.FillEvery; bar < totalBars; bar += drums.FillEvery {
+		velocity := dynamicVelocity(baseVelocity, barDynamicLevel(dynamics, bar-1))
+		notes = insertFill(notes, drums.Style, uint32(bar)*ticksPerBar, fillLength, velocity)
+	}
+	return notes
+}
+
+// fillBaseVelocity returns the fill velocity for the drums' intensity (default 0.7)
+func fillBaseVelocity(drums *parser.Drums) uint8 {
+	intensity := 0.7
+	if drums.Intensity > 0 {
+		intensity = drums.Intensity
+	}
+	return uint8(float64(100) * intensity)
+}
+
+// sectionFillLength returns the length of a fill: the second half of the bar
+// (beats 3-4 in 4/4, the second pulse in 6/8)
+func sectionFillLength(ts parser.TimeSignature) uint32 {
+	fillBeats := (ts.Beats + 1) / 2
+	return uint32(fillBeats) * ts.TicksPerBeat()
+}
+
+// insertFill replaces the groove (except the kick) in the fillLength ticks before
+// downbeat with a fill and puts a crash on downbeat. A fill already there (a
+// periodic fill on a section change) is replaced rather than doubled.
+func insertFill(notes []DrumNote, style string, downbeat, fillLength uint32, velocity uint8) []DrumNote {
+	fillStart := downbeat - fillLength
+
+	// Drop the groove under the fill, keeping the kick for drive
+	kept := notes[:0]
+	for _, note := range notes {
+		if note.Tick >= fillStart && note.Tick < downbeat && note.Note != KickDrum {
+			continue
+		}
+		if note.Tick == downbeat && note.Note == CrashCymbal {
+			continue
+		}
+		kept = append(kept, note)
+	}
+	notes = kept
+
+	notes = append(notes, generateFill(style, fillStart, fillLength, velocity)...)
+	return append(notes, DrumNote{Note: CrashCymbal, Tick: downbeat, Velocity: clampVelocity(int(velocity) + 15)})
+}
+
+// generateFill creates one fill: a ride/snare figure for jazz styles,
+// otherwise a 16th-note roll from the snare down the toms
+func generateFill(style string, startTick, length uint32, velocity uint8) []DrumNote {
+	notes := []DrumNote{}
+	steps := int(length / sixteenthTicks)
+	if steps == 0 {
+		return notes
+	}
+
+	if strings.Contains(style, "jazz") {
+		// Ride on the beats, snare "comping" on the off-beats, kick on the last eighth
+		for step := 0; step < steps; step += 2 {
+			tick := startTick + uint32(step)*sixteenthTicks
+			vel := clampVelocity(int(velocity) - 15 + 20*step/steps)
+			notes = append(notes, DrumNote{Note: RideCymbal, Tick: tick, Velocity: vel})
+			if step+1 < steps {
+				notes = append(notes, DrumNote{Note: SnareDrum, Tick: tick + sixteenthTicks, Velocity: clampVelocity(int(vel) - 10)})
+			}
+		}
+		if steps >= 2 {
+			notes = append(notes, DrumNote{Note: KickDrum, Tick: startTick + length - 2*sixteenthTicks, Velocity: velocity})
+		}
+		return notes
+	}
+
+	// Snare, then high-mid, low-mid and low tom, getting louder
+	voices := []uint8{SnareDrum, HiMidTom, LowMidTom, LowTom}
+	for step := 0; step < steps; step++ {
+		notes = append(notes, DrumNote{
+			Note:     voices[step*len(voices)/steps],
+			Tick:     startTick + uint32(step)*sixteenthTicks,
+			Velocity: clampVelocity(int(velocity) - 20 + 30*step/steps),
+		})
+	}
+	return notes
+}
+
+// clampVelocity limits a velocity to the MIDI range 1-127
+func clampVelocity(v int) uint8 {
+	if v < 1 {
+		return 1
+	}
+	if v > 127 {
+		return 127
+	}
+	return uint8(v)
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"backing-tracks/parser"
+	"backing-tracks/theory"
 
 	"gitlab.com/gomidi/midi/v2"
 	"gitlab.com/gomidi/midi/v2/smf"
@@ -21,66 +22,125 @@ type midiEvent struct {
 // ChordVoicing represents MIDI note numbers for a chord
 type ChordVoicing []uint8
 
-// GenerateFromTrack creates a MIDI file from a track
+// PlaybackMIDIFile is the temporary MIDI file that playback generates and plays
+const PlaybackMIDIFile = "/tmp/backing-track.mid"
+
+// GenerateFromTrack creates the playback MIDI file (PlaybackMIDIFile) from a track
 func GenerateFromTrack(track *parser.Track) (string, error) {
-	// Create temporary MIDI file
-	tmpFile := "/tmp/backing-track.mid"
+	if err := GenerateFile(track, PlaybackMIDIFile); err != nil {
+		return "", err
+	}
+	return PlaybackMIDIFile, nil
+}
 
-	// Create SMF (Standard MIDI File)
-	s := smf.New()
+// GenerateFile creates a MIDI file from a track at filename
+func GenerateFile(track *parser.Track, filename string) error {
+	// Create a multi-track (format 1) SMF: tempo/markers on track 0, one named track per part
+	s := smf.NewSMF1()
 	s.TimeFormat = smf.MetricTicks(480) // 480 ticks per quarter note
 
+	// Calculate ticks per bar from the time signature
+	// 480 ticks per quarter note * 4 quarter notes = 1920 ticks per bar in 4/4
+	timeSig := track.GetTimeSignature()
+	ticksPerBar := timeSig.TicksPerBar()
+
+	// Count-in bars shift every track; the clicks go on the drum channel
+	countInTicks := uint32(track.Info.CountIn) * ticksPerBar
+
 	// Track 0: Tempo and metadata
 	var track0 smf.Track
+	track0.Add(0, smf.MetaTrackSequenceName(track.Info.Title))
 	track0.Add(0, smf.MetaTempo(float64(track.Info.Tempo)))
-	track0.Close(0)
-	s.Add(track0)
+	track0.Add(0, smf.MetaMeter(uint8(timeSig.Beats), uint8(timeSig.BeatUnit)))
 
-	// Track 1: Chord progression
-	var track1 smf.Track
+	// Tempo changes, and section markers for DAW navigation
+	metaEvents := tempoMetaEvents(TrackTempoMap(track), countInTicks)
+	for _, marker := range GetSectionMarkers(track, ticksPerBar) {
+		metaEvents = append(metaEvents, metaEvent{marker.Tick, smf.MetaMarker(marker.Name)})
+	}
+	sort.SliceStable(metaEvents, func(i, j int) bool {
+		return metaEvents[i].tick < metaEvents[j].tick
+	})
+	metaTick := uint32(0)
+	for _, evt := range metaEvents {
+		track0.Add(evt.tick-metaTick, evt.message)
+		metaTick = evt.tick
+	}
 
-	// Set program (0 = Acoustic Grand Piano)
-	track1.Add(0, midi.ProgramChange(0, 0))
+	track0.Close(0)
+	s.Add(track0)
 
+	reverb, chorus := track.ReverbLevel(), track.ChorusLevel()
 	chords := track.Progression.GetChords()
-
-	// Calculate ticks per bar (4/4 time signature assumed)
-	// 480 ticks per quarter note * 4 quarter notes = 1920 ticks per bar
-	ticksPerBar := uint32(1920)
-
-	// Generate chord events using rhythm pattern
-	chordEvents := GenerateChordRhythm(chords, track.Rhythm, ticksPerBar)
+	minimal := isMinimal(track) // Bass and drums only
 
 	// Calculate total duration for later use
 	currentTick := uint32(0)
 	for _, chord := range chords {
 		currentTick += uint32(chord.Bars * float64(ticksPerBar))
 	}
+	lastBar, end, crashEnding := crashEndingBar(track, currentTick, ticksPerBar)
+	dynamics := track.GetBarDynamics()
+	humanize, drumHumanize := trackHumanizers(track.Info.Humanize, track.Info.Seed)
 
-	// Sort events by absolute tick
-	sort.Slice(chordEvents, func(i, j int) bool {
-		return chordEvents[i].tick < chordEvents[j].tick
-	})
+	// Track 1: Chord progression
+	chordNotes := 0
+	if !minimal {
+		var track1 smf.Track
+		track1.Add(0, smf.MetaTrackSequenceName("Chords"))
+
+		track1.Add(0, midi.ProgramChange(0, uint8(ChannelProgram(track, 0))))
+		addEffectSends(&track1, 0, reverb, chorus)
+
+		// Generate chord events using rhythm pattern
+		chordEvents := arrangedChordEvents(track, chords, timeSig)
+		if crashEnding {
+			chordEvents = endChordEvents(chordEvents, lastBar, end)
+		}
+		applyDynamics(chordEvents, dynamics, ticksPerBar)
+		humanizeEvents(chordEvents, humanize)
+		chordNotes = len(chordEvents) / 2 // Each note has an on and an off
+
+		// Sections that change the instrument switch programs where they start
+		// (ahead of the notes there, which the stable sort keeps)
+		var programs []midiEvent
+		for _, change := range SectionInstrumentChanges(track) {
+			if change.Tick > 0 {
+				program := uint8(GMProgram(change.Instrument, DefaultChordsProgram))
+				programs = append(programs, midiEvent{change.Tick, midi.ProgramChange(change.Channel, program)})
+			}
+		}
+		chordEvents = append(programs, chordEvents...)
 
-	// Add events with DELTA times (Track.Add expects delta, not absolute!)
-	prevTick := uint32(0)
-	for _, evt := range chordEvents {
-		delta := evt.tick - prevTick
-		track1.Add(delta, evt.message)
-		prevTick = evt.tick
-	}
+		// Sort events by absolute tick
+		sort.SliceStable(chordEvents, func(i, j int) bool {
+			return chordEvents[i].tick < chordEvents[j].tick
+		})
 
-	track1.Close(0)
-	s.Add(track1)
+		// Add events with DELTA times (Track.Add expects delta, not absolute!)
+		prevTick := uint32(0)
+		for _, evt := range chordEvents {
+			tick := evt.tick + countInTicks
+			track1.Add(tick-prevTick, evt.message)
+			prevTick = tick
+		}
+
+		track1.Close(0)
+		s.Add(track1)
+	}
 
 	// Track 2: Bass (channel 1)
 	bassCount := 0
-	if track.Bass != nil {
+	if bass := arrangedBass(track); bass != nil {
 		var track2 smf.Track
-		// Set program (33 = Fingered Bass)
-		track2.Add(0, midi.ProgramChange(1, 33))
+		track2.Add(0, smf.MetaTrackSequenceName("Bass"))
+		track2.Add(0, midi.ProgramChange(1, uint8(ChannelProgram(track, 1))))
+		addEffectSends(&track2, 1, reverb, chorus)
 
-		bassNotes := GenerateBassLine(chords, track.Bass, ticksPerBar)
+		bassNotes := GenerateBassLineForMeter(chords, bass, track.Info.Key, timeSig)
+		if crashEnding {
+			bassNotes = endBassNotes(bassNotes, lastBar, end)
+		}
 		bassCount = len(bassNotes)
 		// Debug: print first few bass notes
 		if len(bassNotes) > 0 {
@@ -97,6 +157,8 @@ func GenerateFromTrack(track *parser.Track) (string, error) {
 			bassEvents = append(bassEvents, midiEvent{note.Tick, midi.NoteOn(1, note.Note, note.Velocity)})
 			bassEvents = append(bassEvents, midiEvent{note.Tick + note.Duration, midi.NoteOff(1, note.Note)})
 		}
+		applyDynamics(bassEvents, dynamics, ticksPerBar)
+		humanizeEvents(bassEvents, humanize)
 		sort.Slice(bassEvents, func(i, j int) bool {
 			return bassEvents[i].tick < bassEvents[j].tick
 		})
@@ -104,9 +166,9 @@ func GenerateFromTrack(track *parser.Track) (string, error) {
 		// Add with delta times
 		prevTick := uint32(0)
 		for _, evt := range bassEvents {
-			delta := evt.tick - prevTick
-			track2.Add(delta, evt.message)
-			prevTick = evt.tick
+			tick := evt.tick + countInTicks
+			track2.Add(tick-prevTick, evt.message)
+			prevTick = tick
 		}
 
 		track2.Close(0)
@@ -115,19 +177,39 @@ func GenerateFromTrack(track *parser.Track) (string, error) {
 
 	// Track 3: Drums (channel 9 - standard MIDI drum channel)
 	drumCount := 0
-	if track.Drums != nil {
+	if hasDrums(track) || countInTicks > 0 || minimal {
 		var track3 smf.Track
+		track3.Add(0, smf.MetaTrackSequenceName("Drums"))
+		track3.Add(0, midi.ProgramChange(9, uint8(DrumKitProgram(track))))
+		addEffectSends(&track3, 9, reverb, chorus)
 
 		totalBars := track.Progression.TotalBars()
-		drumNotes := GenerateDrumPattern(totalBars, track.Drums, ticksPerBar)
+		var drumNotes []DrumNote
+		if minimal {
+			drumNotes = GenerateMinimalDrums(totalBars, timeSig, dynamics)
+		} else {
+			drumNotes = arrangedDrumNotes(track, totalBars, timeSig)
+			if track.Drums != nil && track.Drums.SectionCue {
+				drumNotes = append(drumNotes, GenerateSectionCues(track.Progression.GetSections(), timeSig)...)
+			}
+			drumNotes = AddSectionFills(drumNotes, track.Progression.GetSections(), track.Drums, timeSig, dynamics)
+		}
+		if crashEnding {
+			drumNotes = endDrumNotes(drumNotes, lastBar)
+		}
 		drumCount = len(drumNotes)
 
+		// Count-in clicks come first, so this track is collected already shifted
+		drumNotes = shiftDrumNotes(drumNotes, countInTicks)
+		drumNotes = append(GenerateCountIn(track.Info.CountIn, track.Info.CountInSound, timeSig), drumNotes...)
+
 		// Collect drum events with absolute ticks
 		var drumEvents []midiEvent
 		for _, note := range drumNotes {
 			drumEvents = append(drumEvents, midiEvent{note.Tick, midi.NoteOn(9, note.Note, note.Velocity)})
 			drumEvents = append(drumEvents, midiEvent{note.Tick + 10, midi.NoteOff(9, note.Note)})
 		}
+		humanizeEvents(drumEvents, drumHumanize)
 		sort.Slice(drumEvents, func(i, j int) bool {
 			return drumEvents[i].tick < drumEvents[j].tick
 		})
@@ -146,24 +228,15 @@ func GenerateFromTrack(track *parser.Track) (string, error) {
 
 	// Track 4: Melody (channel 2)
 	melodyCount := 0
-	if track.Melody != nil && track.Melody.Enabled {
+	if track.Melody != nil && track.Melody.Enabled && !minimal {
 		var track4 smf.Track
-		// Set program (25 = Steel Guitar)
-		track4.Add(0, midi.ProgramChange(2, 25))
-
-		// Create melody config from track settings
-		melodyConfig := DefaultMelodyConfig()
-		if track.Melody.Style != "" {
-			melodyConfig.Style = MelodyStyleFromString(track.Melody.Style)
-		}
-		if track.Melody.Density > 0 {
-			melodyConfig.Density = track.Melody.Density
-		}
-		if track.Melody.Octave > 0 {
-			melodyConfig.Octave = track.Melody.Octave
-		}
+		track4.Add(0, smf.MetaTrackSequenceName("Melody"))
+		track4.Add(0, midi.ProgramChange(2, uint8(ChannelProgram(track, 2))))
+		addEffectSends(&track4, 2, reverb, chorus)
 
-		melodyNotes := GenerateMelody(chords, track.Info.Key, track.Info.Style, melodyConfig, ticksPerBar)
+		melodyConfig := MelodyConfigFromTrack(track)
+		melodyNotes := arrangedMelodyNotes(track, chords, melodyConfig, ticksPerBar)
+		melodyNotes = AddMelodyHarmony(melodyNotes, chords, track.Info.Key, track.Info.Style, melodyConfig, ticksPerBar)
 		melodyCount = len(melodyNotes)
 
 		// Collect melody events with absolute ticks
@@ -172,6 +245,8 @@ func GenerateFromTrack(track *parser.Track) (string, error) {
 			melodyEvents = append(melodyEvents, midiEvent{note.Tick, midi.NoteOn(2, note.Note, note.Velocity)})
 			melodyEvents = append(melodyEvents, midiEvent{note.Tick + note.Duration, midi.NoteOff(2, note.Note)})
 		}
+		applyDynamics(melodyEvents, dynamics, ticksPerBar)
+		humanizeEvents(melodyEvents, humanize)
 		sort.Slice(melodyEvents, func(i, j int) bool {
 			return melodyEvents[i].tick < melodyEvents[j].tick
 		})
@@ -179,34 +254,69 @@ func GenerateFromTrack(track *parser.Track) (string, error) {
 		// Add with delta times
 		prevTick := uint32(0)
 		for _, evt := range melodyEvents {
-			delta := evt.tick - prevTick
-			track4.Add(delta, evt.message)
-			prevTick = evt.tick
+			tick := evt.tick + countInTicks
+			track4.Add(tick-prevTick, evt.message)
+			prevTick = tick
 		}
 
 		track4.Close(0)
 		s.Add(track4)
 	}
 
+	// Track 5: Pad (channel 4)
+	padCount := 0
+	if track.Pad != nil && !minimal {
+		var track5 smf.Track
+		track5.Add(0, smf.MetaTrackSequenceName("Pad"))
+		track5.Add(0, midi.ProgramChange(PadChannel, uint8(ChannelProgram(track, PadChannel))))
+		addEffectSends(&track5, PadChannel, reverb, chorus)
+
+		padNotes := GeneratePad(track.Pad, track.Progression.TotalBars(), ticksPerBar)
+		padCount = len(padNotes)
+
+		// Collect pad events with absolute ticks
+		var padEvents []midiEvent
+		for _, note := range padNotes {
+			padEvents = append(padEvents, midiEvent{note.Tick, midi.NoteOn(PadChannel, note.Note, note.Velocity)})
+			padEvents = append(padEvents, midiEvent{note.Tick + note.Duration, midi.NoteOff(PadChannel, note.Note)})
+		}
+		applyDynamics(padEvents, dynamics, ticksPerBar)
+		humanizeEvents(padEvents, humanize)
+		sort.Slice(padEvents, func(i, j int) bool {
+			return padEvents[i].tick < padEvents[j].tick
+		})
+
+		// Add with delta times
+		prevTick := uint32(0)
+		for _, evt := range padEvents {
+			tick := evt.tick + countInTicks
+			track5.Add(tick-prevTick, evt.message)
+			prevTick = tick
+		}
+
+		track5.Close(0)
+		s.Add(track5)
+	}
+
 	// Debug output
-	chordEventCount := len(chordEvents) / 2 // Divide by 2 since each note has on+off
-	fmt.Printf("\n[MIDI] Generated %d chord events, %d bass notes, %d drum hits, %d melody notes\n", chordEventCount, bassCount, drumCount, melodyCount)
+	fmt.Printf("\n[MIDI] Generated %d chord events, %d bass notes, %d drum hits, %d melody notes, %d pad notes\n", chordNotes, bassCount, drumCount, melodyCount, padCount)
 	fmt.Printf("[MIDI] Tracks: %d\n", len(s.Tracks))
-	fmt.Printf("[MIDI] Channels: Chords=0 (Piano), Bass=1 (Fingered Bass), Melody=2 (Steel Guitar), Drums=9 (GM Drums)\n")
+	fmt.Printf("[MIDI] Channels (GM program): Chords=0 (%d), Bass=1 (%d), Melody=2 (%d), Pad=%d (%d), Drums=9 (kit %d)\n",
+		ChannelProgram(track, 0), ChannelProgram(track, 1), ChannelProgram(track, 2), PadChannel, ChannelProgram(track, PadChannel), DrumKitProgram(track))
 	fmt.Printf("[MIDI] Total duration: %d ticks (%d bars)\n", currentTick, currentTick/ticksPerBar)
+	if countInTicks > 0 {
+		fmt.Printf("[MIDI] Count-in: %d bars before the song\n", track.Info.CountIn)
+	}
 
 	// Write to file
-	f, err := os.Create(tmpFile)
+	f, err := os.Create(filename)
 	if err != nil {
-		return "", err
+		return err
 	}
 	defer f.Close()
 
-	if _, err := s.WriteTo(f); err != nil {
-		return "", err
-	}
-
-	return tmpFile, nil
+	_, err = s.WriteTo(f)
+	return err
 }
 
 // getChordVoicing returns MIDI note numbers for a chord symbol
@@ -221,10 +331,10 @@ func getChordVoicing(symbol string) ChordVoicing {
 	switch quality {
 	case "7": // Dominant 7th (e.g., A7)
 		return ChordVoicing{
-			rootNote,           // Root
-			rootNote + 4,       // Major 3rd
-			rootNote + 7,       // Perfect 5th
-			rootNote + 10,      // Minor 7th
+			rootNote,      // Root
+			rootNote + 4,  // Major 3rd
+			rootNote + 7,  // Perfect 5th
+			rootNote + 10, // Minor 7th
 		}
 	case "maj7", "^7": // Major 7th
 		return ChordVoicing{
@@ -236,10 +346,30 @@ func getChordVoicing(symbol string) ChordVoicing {
 	case "m7": // Minor 7th
 		return ChordVoicing{
 			rootNote,
-			rootNote + 3,  // Minor 3rd
+			rootNote + 3, // Minor 3rd
 			rootNote + 7,
 			rootNote + 10,
 		}
+	case "m7b5": // Half-diminished
+		return ChordVoicing{
+			rootNote,
+			rootNote + 3,
+			rootNote + 6, // Diminished 5th
+			rootNote + 10,
+		}
+	case "dim7": // Diminished 7th
+		return ChordVoicing{
+			rootNote,
+			rootNote + 3,
+			rootNote + 6,
+			rootNote + 9, // Diminished 7th
+		}
+	case "dim": // Diminished triad
+		return ChordVoicing{
+			rootNote,
+			rootNote + 3,
+			rootNote + 6,
+		}
 	case "m": // Minor triad
 		return ChordVoicing{
 			rootNote,
@@ -252,13 +382,98 @@ func getChordVoicing(symbol string) ChordVoicing {
 			rootNote + 7,
 			rootNote + 12, // Octave
 		}
-	default: // Major triad
-		return ChordVoicing{
-			rootNote,
-			rootNote + 4,
-			rootNote + 7,
+	default: // Major triad, or the tones of any other chord (6, 9, sus4, aug, ...)
+		return chordToneVoicing(symbol, rootNote)
+	}
+}
+
+// chordToneVoicing stacks the tones from theory.GetChordTones upward from rootNote,
+// so extensions (9ths, 13ths) land above the triad and audio matches the diagrams
+func chordToneVoicing(symbol string, rootNote uint8) ChordVoicing {
+	voicing := ChordVoicing{rootNote}
+	for _, tone := range theory.GetChordTones(symbol)[1:] {
+		note := rootNote + uint8((tone-int(rootNote)%12+12)%12)
+		for note <= voicing[len(voicing)-1] {
+			note += 12
 		}
+		voicing = append(voicing, note)
 	}
+	return voicing
+}
+
+// getSkillVoicing returns MIDI note numbers for a chord voiced for a skill level,
+// or from a guitar shape when a voicing preference (rhythm.voicing) is set.
+// Slash chords get their bass note at the bottom (beginners play the plain chord).
+func getSkillVoicing(symbol, skill, preference string) ChordVoicing {
+	if voicing := guitarShapeVoicing(theory.SimplifyChordForSkill(symbol, skill), preference); voicing != nil {
+		return voicing
+	}
+
+	switch theory.ParseSkill(skill) {
+	case theory.SkillBeginner:
+		return getChordVoicing(theory.SimplifyChordForSkill(symbol, skill))
+	case theory.SkillAdvanced:
+		return withSlashBass(getAdvancedVoicing(symbol), symbol)
+	default:
+		return withSlashBass(getChordVoicing(symbol), symbol)
+	}
+}
+
+// withSlashBass makes the slash note of a chord like C/E or D/F# the lowest note
+// of the voicing: in the octave of the chord's lowest note, dropped an octave if that
+// isn't below it. Chord tones with the same pitch class are left out so the inversion is clear.
+func withSlashBass(voicing ChordVoicing, symbol string) ChordVoicing {
+	idx := strings.Index(symbol, "/")
+	if idx <= 0 || idx == len(symbol)-1 || len(voicing) == 0 {
+		return voicing
+	}
+	if bass := symbol[idx+1]; bass < 'A' || bass > 'G' {
+		return voicing // 6/9 chord, not a bass note
+	}
+
+	bassClass := parseBassNote(symbol)
+	lowest := voicing[0]
+	for _, note := range voicing {
+		if note < lowest {
+			lowest = note
+		}
+	}
+	bass := lowest - lowest%12 + bassClass
+	for bass >= lowest {
+		bass -= 12
+	}
+
+	result := ChordVoicing{bass}
+	for _, note := range voicing {
+		if note%12 != bassClass {
+			result = append(result, note)
+		}
+	}
+	return result
+}
+
+// getAdvancedVoicing returns a drop-2 voicing for seventh chords and adds
+// the 9th for extended chords (9, 11, 13)
+func getAdvancedVoicing(symbol string) ChordVoicing {
+	voicing := getChordVoicing(symbol)
+
+	// Drop-2: lower the second-highest note of a close 4-note voicing by an octave
+	if len(voicing) == 4 {
+		dropped := voicing[2] - 12
+		voicing = ChordVoicing{dropped, voicing[0], voicing[1], voicing[3]}
+	}
+
+	// Extensions: add the 9th above the voicing
+	quality := symbol
+	if idx := strings.Index(quality, "/"); idx > 0 {
+		quality = quality[:idx]
+	}
+	if strings.Contains(quality, "9") || strings.Contains(quality, "11") || strings.Contains(quality, "13") {
+		root := parseRoot(symbol) + 48
+		voicing = append(voicing, root+14)
+	}
+
+	return voicing
 }
 
 // parseRoot extracts the root note from a chord symbol
@@ -318,10 +533,10 @@ func noteNameToMidi(root string) uint8 {
 		"G#": 8, "AB": 8,
 		"A":  9,
 		"A#": 10, "BB": 10,
-		"B":  11,
+		"B": 11,
 	}
 
-	if note, ok := noteMap[root]; ok {
+	if note, ok := noteMap[strings.ToUpper(root)]; ok { // "Bb" -> "BB"
 		return note
 	}
 
@@ -346,6 +561,15 @@ func parseQuality(symbol string) string {
 	if strings.HasPrefix(quality, "maj7") || quality == "^7" {
 		return "maj7"
 	}
+	if strings.HasPrefix(quality, "m7b5") || strings.HasPrefix(quality, "ø") {
+		return "m7b5"
+	}
+	if strings.HasPrefix(quality, "dim7") || strings.HasPrefix(quality, "o7") || strings.HasPrefix(quality, "°7") {
+		return "dim7"
+	}
+	if strings.HasPrefix(quality, "dim") || quality == "o" || quality == "°" {
+		return "dim"
+	}
 	if strings.HasPrefix(quality, "m7") {
 		return "m7"
 	}
@@ -0,0 +1,144 @@
+package midi
+
+import (
+	"math/rand"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// Humanize ranges at humanize: 1.0
+const (
+	humanizeMaxTicks    = 24   // Timing offset (+/- a 1/20 quarter note)
+	humanizeMaxVelocity = 12   // Velocity offset
+	drumHumanizeScale   = 0.25 // Drums stay tighter so the backbeat holds
+)
+
+// humanizer nudges note timing and velocity with a seeded RNG, so a track
+// renders identically each run for the same seed
+type humanizer struct {
+	rng      *rand.Rand
+	ticks    float64
+	velocity float64
+	pending  map[[2]uint8]int // Timing shift of the last note-on per channel/note, reused for its note-off
+}
+
+// newHumanizer returns a humanizer for an amount of 0.0-1.0, or nil for 0 (no change)
+func newHumanizer(amount float64, seed int64) *humanizer {
+	if amount <= 0 {
+		return nil
+	}
+	if amount > 1 {
+		amount = 1
+	}
+	return &humanizer{
+		rng:      rand.New(rand.NewSource(seed)),
+		ticks:    amount * humanizeMaxTicks,
+		velocity: amount * humanizeMaxVelocity,
+		pending:  make(map[[2]uint8]int),
+	}
+}
+
+// jitter returns a random offset in [-limit, limit]
+func (h *humanizer) jitter(limit float64) int {
+	return int((h.rng.Float64()*2 - 1) * limit)
+}
+
+// noteOn returns the humanized tick and velocity of a note-on
+func (h *humanizer) noteOn(channel, note uint8, tick uint32, velocity uint8) (uint32, uint8) {
+	shift := h.jitter(h.ticks)
+	if int(tick)+shift < 0 {
+		shift = -int(tick)
+	}
+	h.pending[[2]uint8{channel, note}] = shift
+	return uint32(int(tick) + shift), clampVelocity(int(velocity) + h.jitter(h.velocity))
+}
+
+// noteOff returns the tick of a note-off, shifted with its note-on so the length is kept
+func (h *humanizer) noteOff(channel, note uint8, tick uint32) uint32 {
+	key := [2]uint8{channel, note}
+	shift := h.pending[key]
+	delete(h.pending, key)
+	return uint32(int(tick) + shift)
+}
+
+// heldNote is the humanized note-on tick of the last note on a channel/note and
+// the index of its note-off event (-1 until it is seen)
+type heldNote struct {
+	on  uint32
+	off int
+}
+
+// releaseBefore returns the tick for a humanized note-off so it stays strictly
+// before the next note-on of the same pitch at next (a later off would cut the
+// new note short), but not before its own note-on at on
+func releaseBefore(off, on, next uint32) uint32 {
+	if off < next {
+		return off
+	}
+	if next == 0 || next-1 < on {
+		return on
+	}
+	return next - 1
+}
+
+// humanizeEvents nudges the notes in events, which must still be in creation
+// order (each note-on before its note-off). A nil humanizer leaves them unchanged.
+func humanizeEvents(events []midiEvent, h *humanizer) {
+	if h == nil {
+		return
+	}
+	held := make(map[[2]uint8]heldNote)
+	for i := range events {
+		var channel, note, velocity uint8
+		switch {
+		case events[i].message.GetNoteStart(&channel, &note, &velocity):
+			events[i].tick, velocity = h.noteOn(channel, note, events[i].tick, velocity)
+			events[i].message = midi.NoteOn(channel, note, velocity)
+			key := [2]uint8{channel, note}
+			if last, ok := held[key]; ok && last.off >= 0 {
+				events[last.off].tick = releaseBefore(events[last.off].tick, last.on, events[i].tick)
+			}
+			held[key] = heldNote{on: events[i].tick, off: -1}
+		case events[i].message.GetNoteEnd(&channel, &note):
+			events[i].tick = h.noteOff(channel, note, events[i].tick)
+			key := [2]uint8{channel, note}
+			if last, ok := held[key]; ok {
+				held[key] = heldNote{on: last.on, off: i}
+			}
+		}
+	}
+}
+
+// humanizePlaybackEvents nudges playback events in creation order, using the
+// drums humanizer for channel 9 and the other one for everything else
+func humanizePlaybackEvents(events []PlaybackEvent, h, drums *humanizer) {
+	held := make(map[[2]uint8]heldNote)
+	for i := range events {
+		evt := &events[i]
+		hz := h
+		if evt.Channel == 9 {
+			hz = drums
+		}
+		if hz == nil {
+			continue
+		}
+		key := [2]uint8{evt.Channel, evt.Note}
+		if evt.IsNoteOn {
+			evt.Tick, evt.Velocity = hz.noteOn(evt.Channel, evt.Note, evt.Tick, evt.Velocity)
+			if last, ok := held[key]; ok && last.off >= 0 {
+				events[last.off].Tick = releaseBefore(events[last.off].Tick, last.on, evt.Tick)
+			}
+			held[key] = heldNote{on: evt.Tick, off: -1}
+		} else {
+			evt.Tick = hz.noteOff(evt.Channel, evt.Note, evt.Tick)
+			if last, ok := held[key]; ok {
+				held[key] = heldNote{on: last.on, off: i}
+			}
+		}
+	}
+}
+
+// trackHumanizers returns the humanizers for a track's pitched parts and drums
+func trackHumanizers(amount float64, seed int64) (*humanizer, *humanizer) {
+	return newHumanizer(amount, seed), newHumanizer(amount*drumHumanizeScale, seed+1)
+}
@@ -0,0 +1,77 @@
+package midi
+
+import (
+	"fmt"
+	"testing"
+)
+
+// humanizedStrumTrack strums eighth notes with full humanize
+const humanizedStrumTrack = `
+track:
+  title: Humanized
+  key: C
+  tempo: 120
+  humanize: 1.0
+  seed: %d
+chord_progression:
+  pattern: "C G Am F"
+rhythm:
+  style: %s
+`
+
+func TestHumanizeKeepsRepeatedNotes(t *testing.T) {
+	// Chord notes end 10 ticks before the next strike of the same pitch; a
+	// humanized note-off must still land before it rather than cut it short
+	const minLength = humanizeMaxTicks
+	for _, style := range []string{"eighth", "quarter", "strum_down", "rock"} {
+		for _, seed := range []int64{1, 42, 7919} {
+			s := exportTestMIDI(t, parseTestTrack(t, fmt.Sprintf(humanizedStrumTrack, seed, style)))
+			cut, notes := 0, 0
+			for _, tr := range s.Tracks {
+				var tick uint32
+				started := make(map[[2]uint8]uint32)
+				for _, evt := range tr {
+					tick += evt.Delta
+					var channel, key, velocity uint8
+					switch {
+					case evt.Message.GetNoteStart(&channel, &key, &velocity):
+						if channel != 9 {
+							started[[2]uint8{channel, key}] = tick
+							notes++
+						}
+					case evt.Message.GetNoteEnd(&channel, &key):
+						if on, ok := started[[2]uint8{channel, key}]; ok && tick-on < minLength {
+							cut++
+						}
+						delete(started, [2]uint8{channel, key})
+					}
+				}
+			}
+			if notes == 0 {
+				t.Fatalf("%s: no notes exported", style)
+			}
+			if cut > 0 {
+				t.Errorf("%s, seed %d: %d of %d notes end within %d ticks of starting", style, seed, cut, notes, minLength)
+			}
+		}
+	}
+}
+
+func TestHumanizePlaybackKeepsRepeatedNotes(t *testing.T) {
+	// One pitch struck every eighth note, each released 10 ticks before the next
+	var events []PlaybackEvent
+	for strike := uint32(0); strike < 64; strike++ {
+		events = append(events,
+			PlaybackEvent{Tick: strike * 240, Channel: 0, Note: 60, Velocity: 90, IsNoteOn: true},
+			PlaybackEvent{Tick: strike*240 + 230, Channel: 0, Note: 60, IsNoteOn: false})
+	}
+	humanize, drumHumanize := trackHumanizers(1.0, 42)
+	humanizePlaybackEvents(events, humanize, drumHumanize)
+
+	for i := 0; i+2 < len(events); i += 2 {
+		on, off, next := events[i], events[i+1], events[i+2]
+		if off.Tick < on.Tick || off.Tick >= next.Tick {
+			t.Errorf("strike %d: note on at %d, off at %d, next on at %d; want the off between them", i/2, on.Tick, off.Tick, next.Tick)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package midi
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"backing-tracks/theory"
+
+	"gitlab.com/gomidi/midi/v2/smf"
+)
+
+// Importing reads any standard MIDI file back as a BTML sketch: its tempo, time
+// signature, a guessed key and one chord per bar, heard from all the pitched notes
+// together (drums are ignored). It is a starting point for editing, not a transcription.
+
+// importChordTypes are the chords recognized by ImportMIDI, simplest first so a
+// plain triad wins a tie
+var importChordTypes = []struct {
+	suffix string
+	tones  []int // Semitones above the root
+}{
+	{"", []int{0, 4, 7}},
+	{"m", []int{0, 3, 7}},
+	{"7", []int{0, 4, 7, 10}},
+	{"m7", []int{0, 3, 7, 10}},
+	{"maj7", []int{0, 4, 7, 11}},
+	{"sus4", []int{0, 5, 7}},
+	{"dim", []int{0, 3, 6}},
+}
+
+// Krumhansl-Kessler key profiles: how strongly each scale degree suggests a key
+var (
+	majorKeyProfile = [12]float64{6.35, 2.23, 3.48, 2.33, 4.38, 4.09, 2.52, 5.19, 2.39, 3.66, 2.29, 2.88}
+	minorKeyProfile = [12]float64{6.33, 2.68, 3.52, 5.38, 2.60, 3.53, 2.54, 4.75, 3.98, 2.69, 3.34, 3.17}
+)
+
+// importedNote is one note of the file, in absolute ticks
+type importedNote struct {
+	start, end uint32
+	key        uint8
+}
+
+// lastTick returns the last tick the note sounds on
+func (n importedNote) lastTick() uint32 {
+	if n.end > n.start {
+		return n.end - 1
+	}
+	return n.start
+}
+
+// ImportMIDI reads a standard MIDI file and returns it as BTML source
+func ImportMIDI(filename string) (string, error) {
+	s, err := smf.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	metric, ok := s.TimeFormat.(smf.MetricTicks)
+	if !ok {
+		return "", fmt.Errorf("%s: only files timed in ticks per quarter note can be imported", filename)
+	}
+
+	title := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	tempo := 0.0
+	var beats, beatUnit uint8
+	var notes []importedNote
+	for trackNo, track := range s.Tracks {
+		var tick uint32
+		open := make(map[[2]uint8][]int) // Sounding notes by channel and key, oldest first
+		for _, evt := range track {
+			tick += evt.Delta
+			msg := evt.Message
+
+			var channel, key, velocity, num, denom uint8
+			var bpm float64
+			var name string
+			switch {
+			case msg.GetNoteStart(&channel, &key, &velocity):
+				if channel == 9 { // Drums
+					continue
+				}
+				id := [2]uint8{channel, key}
+				open[id] = append(open[id], len(notes))
+				notes = append(notes, importedNote{start: tick, end: tick, key: key})
+			case msg.GetNoteEnd(&channel, &key):
+				id := [2]uint8{channel, key}
+				if pending := open[id]; len(pending) > 0 {
+					notes[pending[0]].end = tick
+					open[id] = pending[1:]
+				}
+			case msg.GetMetaTempo(&bpm):
+				if tempo == 0 {
+					tempo = bpm
+				}
+			case msg.GetMetaMeter(&num, &denom):
+				if beats == 0 { // Like the tempo, the song's opening meter
+					beats, beatUnit = num, denom
+				}
+			case trackNo == 0 && msg.GetMetaTrackName(&name):
+				if name = strings.TrimSpace(name); name != "" {
+					title = name
+				}
+			}
+		}
+	}
+	if len(notes) == 0 {
+		return "", fmt.Errorf("%s: no notes to import", filename)
+	}
+	if tempo == 0 {
+		tempo = 120 // The MIDI default
+	}
+	if beats == 0 || beatUnit == 0 {
+		beats, beatUnit = 4, 4
+	}
+
+	// Weigh each pitch class per bar by how long it sounds
+	ticksPerBar := uint32(metric.Resolution()) * 4 * uint32(beats) / uint32(beatUnit)
+	firstBar, lastBar := math.MaxInt, 0
+	for _, note := range notes {
+		firstBar = min(firstBar, int(note.start/ticksPerBar))
+		lastBar = max(lastBar, int(note.start/ticksPerBar)) // Not bars the last notes only ring into
+	}
+	numBars := lastBar - firstBar + 1
+	weights := make([][12]float64, numBars)
+	bass := make([]int, numBars) // Lowest key in each bar
+	var total [12]float64
+	for i := range bass {
+		bass[i] = math.MaxInt
+	}
+	for _, note := range notes {
+		startBar, endBar := note.start/ticksPerBar, min(int(note.lastTick()/ticksPerBar), lastBar)
+		for bar := startBar; int(bar) <= endBar; bar++ {
+			barStart, barEnd := bar*ticksPerBar, (bar+1)*ticksPerBar
+			end := note.end
+			if end > barEnd {
+				end = barEnd
+			}
+			length := float64(end-max(note.start, barStart)) + 1
+			i := int(bar) - firstBar
+			weights[i][note.key%12] += length
+			total[note.key%12] += length
+			bass[i] = min(bass[i], int(note.key))
+		}
+	}
+
+	key := guessKey(total)
+	names := theory.NoteNames
+	if theory.KeyUsesFlats(key) {
+		names = theory.NoteNamesFlat
+	}
+
+	chords := make([]string, numBars)
+	for i := range chords {
+		chords[i] = detectChord(weights[i], bass[i], names)
+	}
+	// Bars with no notes keep the chord before them (or after, at the start)
+	for i := range chords {
+		if chords[i] == "" && i > 0 {
+			chords[i] = chords[i-1]
+		}
+	}
+	for i := len(chords) - 1; i >= 0; i-- {
+		if chords[i] == "" && i+1 < len(chords) {
+			chords[i] = chords[i+1]
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Imported from %s by: backing-tracks import\n", filepath.Base(filename))
+	fmt.Fprintf(&sb, "track:\n  title: %q\n  key: %s\n  tempo: %d\n  time_signature: %d/%d\n\n",
+		title, key, int(math.Round(tempo)), beats, beatUnit)
+	fmt.Fprintf(&sb, "chord_progression:\n  pattern: \"%s\"\n  bars_per_chord: 1\n", strings.Join(chords, " "))
+	return sb.String(), nil
+}
+
+// guessKey returns the major or minor key whose profile best correlates with the
+// song's pitch class weights, spelled with flats in flat keys
+func guessKey(weights [12]float64) string {
+	bestKey, bestScore := "C", math.Inf(-1)
+	for root := 0; root < 12; root++ {
+		for _, minor := range []bool{false, true} {
+			profile := majorKeyProfile
+			if minor {
+				profile = minorKeyProfile
+			}
+			var rotated [12]float64
+			for pc := range rotated {
+				rotated[pc] = profile[(pc-root+12)%12]
+			}
+			if score := correlation(weights, rotated); score > bestScore {
+				suffix := ""
+				if minor {
+					suffix = "m"
+				}
+				bestKey = theory.NoteNamesFlat[root] + suffix
+				if !theory.KeyUsesFlats(bestKey) {
+					bestKey = theory.NoteNames[root] + suffix
+				}
+				bestScore = score
+			}
+		}
+	}
+	return bestKey
+}
+
+// correlation returns the Pearson correlation of a and b
+func correlation(a, b [12]float64) float64 {
+	var meanA, meanB float64
+	for i := range a {
+		meanA += a[i] / 12
+		meanB += b[i] / 12
+	}
+	var cov, varA, varB float64
+	for i := range a {
+		cov += (a[i] - meanA) * (b[i] - meanB)
+		varA += (a[i] - meanA) * (a[i] - meanA)
+		varB += (b[i] - meanB) * (b[i] - meanB)
+	}
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
+
+// detectChord names the chord that best explains a bar's pitch class weights: its
+// tones should sound and other notes shouldn't, a seventh has to be heard to be
+// chosen, and a root in the bass helps. It returns "" for an empty bar.
+func detectChord(weights [12]float64, bass int, names []string) string {
+	var sum float64
+	for _, w := range weights {
+		sum += w
+	}
+	if sum == 0 {
+		return ""
+	}
+
+	best, bestScore := "", math.Inf(-1)
+	for root := 0; root < 12; root++ {
+		for _, chord := range importChordTypes {
+			score := -0.1 * sum * float64(len(chord.tones)-3)
+			inChord := make(map[int]bool, len(chord.tones))
+			for _, tone := range chord.tones {
+				inChord[(root+tone)%12] = true
+			}
+			for pc, w := range weights {
+				if inChord[pc] {
+					score += w
+				} else {
+					score -= w
+				}
+			}
+			if bass%12 == root {
+				score += 0.2 * sum
+			}
+			if score > bestScore {
+				best, bestScore = names[root]+chord.suffix, score
+			}
+		}
+	}
+	return best
+}
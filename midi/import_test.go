@@ -0,0 +1,88 @@
+package midi
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/smf"
+)
+
+func TestImportRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name, key, timeSig, pattern string
+	}{
+		{"pop in C", "C", "4/4", "C Am F G C F G C"},
+		{"minor in 3/4", "Am", "3/4", "Am Dm E7 Am F Dm E Am"},
+		{"flat key", "F", "4/4", "F Bb C7 F Dm Bb C F"},
+	} {
+		source := "track:\n  title: \"" + tc.name + "\"\n  key: " + tc.key + "\n  tempo: 96\n  time_signature: " + tc.timeSig +
+			"\nchord_progression:\n  pattern: \"" + tc.pattern + "\"\ndrums:\n  style: rock_beat\n"
+		filename := filepath.Join(t.TempDir(), "song.mid")
+		if err := GenerateFile(parseTestTrack(t, source), filename); err != nil {
+			t.Fatalf("%s: exporting: %v", tc.name, err)
+		}
+
+		imported, err := ImportMIDI(filename)
+		if err != nil {
+			t.Fatalf("%s: importing: %v", tc.name, err)
+		}
+		track := parseTestTrack(t, imported)
+		if track.Info.Title != tc.name || track.Info.Key != tc.key || track.Info.Tempo != 96 || track.Info.TimeSignature != tc.timeSig {
+			t.Errorf("%s: imported title %q, key %s, tempo %d, time %s; want %q, %s, 96, %s",
+				tc.name, track.Info.Title, track.Info.Key, track.Info.Tempo, track.Info.TimeSignature, tc.name, tc.key, tc.timeSig)
+		}
+		var got []string
+		for _, chord := range track.Progression.GetChords() {
+			got = append(got, chord.Symbol)
+		}
+		if strings.Join(got, " ") != tc.pattern {
+			t.Errorf("%s: imported chords %q, want %q", tc.name, strings.Join(got, " "), tc.pattern)
+		}
+	}
+}
+
+func TestImportKeepsOpeningMeterAndTempo(t *testing.T) {
+	// Four bars of 4/4 at 100 BPM, then a change to 3/4 at 140 BPM
+	var tr smf.Track
+	tr.Add(0, smf.MetaMeter(4, 4))
+	tr.Add(0, smf.MetaTempo(100))
+	for bar := 0; bar < 4; bar++ {
+		for _, key := range []uint8{48, 52, 55} { // C major
+			tr.Add(0, midi.NoteOn(0, key, 90))
+		}
+		for i, key := range []uint8{48, 52, 55} {
+			delta := uint32(0)
+			if i == 0 {
+				delta = 1900
+			}
+			tr.Add(delta, midi.NoteOff(0, key))
+		}
+		if bar < 3 {
+			tr.Add(20, smf.MetaMeter(4, 4))
+		}
+	}
+	tr.Add(20, smf.MetaMeter(3, 4))
+	tr.Add(0, smf.MetaTempo(140))
+	tr.Add(0, midi.NoteOn(0, 48, 90))
+	tr.Add(1440, midi.NoteOff(0, 48))
+	tr.Close(0)
+
+	s := smf.NewSMF1()
+	s.TimeFormat = smf.MetricTicks(480)
+	s.Add(tr)
+	filename := filepath.Join(t.TempDir(), "meter.mid")
+	if err := s.WriteFile(filename); err != nil {
+		t.Fatalf("writing: %v", err)
+	}
+
+	imported, err := ImportMIDI(filename)
+	if err != nil {
+		t.Fatalf("importing: %v", err)
+	}
+	track := parseTestTrack(t, imported)
+	if track.Info.Tempo != 100 || track.Info.TimeSignature != "4/4" {
+		t.Errorf("imported tempo %d in %s, want the opening 100 in 4/4:\n%s", track.Info.Tempo, track.Info.TimeSignature, imported)
+	}
+}
@@ -0,0 +1,249 @@
+package midi
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"backing-tracks/parser"
+)
+
+// Each part plays on its own channel (chords 0, bass 1, melody 2, fingerstyle 3,
+// pad PadChannel, drums 9) with a General MIDI program chosen from the part's
+// instrument name, or given as "prog:N" for a voice without a name; the drums get
+// the program of their kit. The player and an exported file set the same programs,
+// so an export sounds the same in any player.
+
+// Default GM programs of the parts, for a part without a (known) instrument
+const (
+	DefaultChordsProgram = 0  // Acoustic grand piano
+	DefaultBassProgram   = 33 // Fingered bass
+	DefaultMelodyProgram = 25 // Steel guitar
+	FingerstyleProgram   = 24 // Nylon guitar (fingerstyle has no instrument setting)
+	DefaultPadProgram    = 88 // New age pad
+)
+
+// GMInstruments maps friendly instrument names to General MIDI program numbers
+var GMInstruments = map[string]int{
+	// Pianos
+	"piano":          0,
+	"acoustic_piano": 0,
+	"bright_piano":   1,
+	"electric_piano": 4,
+	"honky_tonk":     3,
+	"harpsichord":    6,
+	"clavinet":       7,
+
+	// Chromatic percussion
+	"celesta":       8,
+	"glockenspiel":  9,
+	"music_box":     10,
+	"vibraphone":    11,
+	"marimba":       12,
+	"xylophone":     13,
+	"tubular_bells": 14,
+	"dulcimer":      15,
+
+	// Guitars
+	"nylon_guitar": 24,
+	"steel_guitar": 25,
+	"jazz_guitar":  26,
+	"clean_guitar": 27,
+	"muted_guitar": 28,
+	"overdrive":    29,
+	"distortion":   30,
+	"harmonics":    31,
+
+	// Bass
+	"acoustic_bass": 32,
+	"fingered_bass": 33,
+	"picked_bass":   34,
+	"fretless_bass": 35,
+	"slap_bass":     36,
+	"synth_bass":    38,
+
+	// Strings
+	"violin":          40,
+	"viola":           41,
+	"cello":           42,
+	"contrabass":      43,
+	"tremolo_strings": 44,
+	"pizzicato":       45,
+	"harp":            46,
+	"timpani":         47,
+	"strings":         48,
+	"slow_strings":    49,
+
+	// Choir
+	"choir":       52,
+	"voice_oohs":  53,
+	"synth_voice": 54,
+
+	// Brass
+	"trumpet":     56,
+	"trombone":    57,
+	"tuba":        58,
+	"french_horn": 60,
+	"brass":       61,
+	"synth_brass": 62,
+
+	// Woodwinds
+	"soprano_sax":  64,
+	"alto_sax":     65,
+	"tenor_sax":    66,
+	"baritone_sax": 67,
+	"oboe":         68,
+	"english_horn": 69,
+	"bassoon":      70,
+	"clarinet":     71,
+	"piccolo":      72,
+	"flute":        73,
+	"recorder":     74,
+	"pan_flute":    75,
+	"whistle":      78,
+	"ocarina":      79,
+
+	// Synth
+	"synth_lead": 80,
+	"saw_lead":   81,
+	"synth_pad":  88,
+	"warm_pad":   89,
+	"choir_pad":  91,
+
+	// Ethnic
+	"sitar":       104,
+	"banjo":       105,
+	"shamisen":    106,
+	"koto":        107,
+	"kalimba":     108,
+	"bagpipe":     109,
+	"fiddle":      110,
+	"steel_drums": 114,
+	"mandolin":    25, // GM has no mandolin; steel guitar is the closest
+
+	// Organ
+	"organ":        16,
+	"church_organ": 19,
+	"reed_organ":   20,
+	"accordion":    21,
+	"harmonica":    22,
+	"bandoneon":    23,
+}
+
+// GMDrumKits maps drum kit names (drums.kit) to the programs that select them on
+// the drum channel: the GS kits most General MIDI SoundFonts have in the
+// percussion bank. A SoundFont without a kit plays the standard one.
+var GMDrumKits = map[string]int{
+	"standard":   0,
+	"room":       8,
+	"power":      16,
+	"electronic": 24,
+	"tr808":      25,
+	"jazz":       32,
+	"brush":      40,
+	"orchestra":  48,
+}
+
+// programPrefix starts a raw program number given as an instrument ("prog:105")
+const programPrefix = "prog:"
+
+// ParseProgram returns the GM program (0-127) for an instrument name in names or a
+// raw "prog:N" number
+func ParseProgram(name string, names map[string]int) (int, bool) {
+	if prog, ok := names[name]; ok {
+		return prog, true
+	}
+	if number, ok := strings.CutPrefix(name, programPrefix); ok {
+		if prog, err := strconv.Atoi(number); err == nil && prog >= 0 && prog <= 127 {
+			return prog, true
+		}
+	}
+	return 0, false
+}
+
+// GMProgram returns the GM program number for an instrument name or "prog:N", or
+// defaultProg when the name is empty or unknown
+func GMProgram(name string, defaultProg int) int {
+	if prog, ok := ParseProgram(name, GMInstruments); ok {
+		return prog
+	}
+	return defaultProg
+}
+
+// DrumKitProgram returns the drum channel program for the track's drums.kit (a kit
+// name or "prog:N"; the standard kit by default)
+func DrumKitProgram(track *parser.Track) int {
+	if track.Drums != nil {
+		if prog, ok := ParseProgram(track.Drums.Kit, GMDrumKits); ok {
+			return prog
+		}
+	}
+	return GMDrumKits["standard"]
+}
+
+// ChannelProgram returns the GM program a channel starts the track with: the
+// instrument of the part on it, or the part's default
+func ChannelProgram(track *parser.Track, channel uint8) int {
+	switch channel {
+	case 0:
+		if track.Rhythm != nil {
+			return GMProgram(track.Rhythm.Instrument, DefaultChordsProgram)
+		}
+		return DefaultChordsProgram
+	case 1:
+		if track.Bass != nil {
+			return GMProgram(track.Bass.Instrument, DefaultBassProgram)
+		}
+		return DefaultBassProgram
+	case 2:
+		if track.Melody != nil {
+			return GMProgram(track.Melody.Instrument, DefaultMelodyProgram)
+		}
+		return DefaultMelodyProgram
+	case 3:
+		return FingerstyleProgram
+	case PadChannel:
+		if track.Pad != nil {
+			return GMProgram(track.Pad.Instrument, DefaultPadProgram)
+		}
+		return DefaultPadProgram
+	case 9:
+		return DrumKitProgram(track)
+	}
+	return 0
+}
+
+// gmFamilies names the General MIDI families of eight programs each
+var gmFamilies = [16]string{
+	"Pianos", "Chromatic percussion", "Organs", "Guitars", "Basses", "Strings",
+	"Ensembles", "Brass", "Reeds", "Pipes", "Synth leads", "Synth pads",
+	"Synth effects", "Ethnic", "Percussive", "Sound effects",
+}
+
+// InstrumentGroups returns the instrument names by GM family and the drum kit
+// names, each in program order (for the instruments command)
+func InstrumentGroups() []StyleGroup {
+	byProgram := func(names map[string]int) []string {
+		var sorted []string
+		for name := range names {
+			sorted = append(sorted, name)
+		}
+		sort.Slice(sorted, func(i, j int) bool {
+			if names[sorted[i]] != names[sorted[j]] {
+				return names[sorted[i]] < names[sorted[j]]
+			}
+			return sorted[i] < sorted[j]
+		})
+		return sorted
+	}
+
+	var groups []StyleGroup
+	for _, name := range byProgram(GMInstruments) {
+		family := gmFamilies[GMInstruments[name]/8]
+		if len(groups) == 0 || groups[len(groups)-1].Name != family {
+			groups = append(groups, StyleGroup{Name: family, Field: "instrument"})
+		}
+		groups[len(groups)-1].Styles = append(groups[len(groups)-1].Styles, name)
+	}
+	return append(groups, StyleGroup{Name: "Drum kits", Field: "drums.kit", Styles: byProgram(GMDrumKits)})
+}
@@ -0,0 +1,65 @@
+package midi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"backing-tracks/parser"
+)
+
+// SectionMarker is a named position in the exported song (one per section start)
+type SectionMarker struct {
+	Name string
+	Bar  int           // Song bar (0-based, excluding count-in)
+	Tick uint32        // Absolute tick in the exported MIDI (including count-in)
+	Time time.Duration // Time from the start of the exported MIDI
+}
+
+// GetSectionMarkers returns a marker for the start of each section
+func GetSectionMarkers(track *parser.Track, ticksPerBar uint32) []SectionMarker {
+	return GetSectionMarkersInRange(track, ticksPerBar, 0, 0)
+}
+
+// GetSectionMarkersInRange returns the section markers of the song trimmed to bars
+// [startBar, endBar) as TrimMIDIFile does (endBar 0 = to the end): sections outside
+// the range are dropped, the one playing at startBar is marked there, and ticks and
+// times count from the start of the trimmed file
+func GetSectionMarkersInRange(track *parser.Track, ticksPerBar uint32, startBar, endBar int) []SectionMarker {
+	startBar, endBar = clampBarRange(startBar, endBar, track.Progression.TotalBars())
+	countInTicks := uint32(track.Info.CountIn) * ticksPerBar
+	tickDuration := time.Duration(float64(time.Second) * 60.0 / float64(track.Info.Tempo) / float64(parser.TicksPerWholeNote/4))
+
+	tempoMap := TrackTempoMap(track)
+	rangeTick := uint32(max(startBar, 0)) * ticksPerBar
+	rangeTime := tempoMapTime(tempoMap, tickDuration, rangeTick)
+
+	var markers []SectionMarker
+	for _, section := range track.Progression.GetSections() {
+		if section.EndBar <= startBar || section.StartBar >= endBar {
+			continue
+		}
+		bar := max(section.StartBar, startBar)
+		songTick := uint32(bar) * ticksPerBar
+		markers = append(markers, SectionMarker{
+			Name: section.Name,
+			Bar:  bar,
+			Tick: countInTicks + songTick - rangeTick,
+			Time: time.Duration(countInTicks)*tickDuration + tempoMapTime(tempoMap, tickDuration, songTick) - rangeTime,
+		})
+	}
+	return markers
+}
+
+// WriteMarkerFile writes a sidecar marker list (time, name) for DAWs that import text
+// markers, for the song trimmed to bars [startBar, endBar) (0, 0 = the whole song)
+func WriteMarkerFile(track *parser.Track, path string, startBar, endBar int) error {
+	var b strings.Builder
+	for _, m := range GetSectionMarkersInRange(track, track.GetTimeSignature().TicksPerBar(), startBar, endBar) {
+		minutes := int(m.Time / time.Minute)
+		seconds := (m.Time % time.Minute).Seconds()
+		fmt.Fprintf(&b, "%d:%06.3f\t%s\n", minutes, seconds, m.Name)
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
@@ -0,0 +1,183 @@
+package midi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"backing-tracks/parser"
+
+	"gitlab.com/gomidi/midi/v2/smf"
+)
+
+// markerTrack is a song of three sections (2, 1 and 2 bars of 4/4); the second
+// name has a space, as structured section names may
+const markerTrack = `
+track:
+  title: Markers
+  key: C
+  tempo: 120
+  count_in: %d
+sections:
+  - name: Intro
+    chord_progression:
+      pattern: "C G"
+  - name: Pre Chorus
+    chord_progression:
+      pattern: "Am"
+  - name: Chorus
+    chord_progression:
+      pattern: "F G"
+form: [Intro, Pre Chorus, Chorus]
+`
+
+// parseTestTrack loads BTML source, failing the test on errors
+func parseTestTrack(t *testing.T, source string) *parser.Track {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "track.btml")
+	if err := os.WriteFile(filename, []byte(source), 0644); err != nil {
+		t.Fatalf("writing track: %v", err)
+	}
+	track, err := parser.LoadTrack(filename)
+	if err != nil {
+		t.Fatalf("parsing track: %v", err)
+	}
+	return track
+}
+
+// exportTestMIDI exports the track to a file in the test's temporary directory
+// and reads it back
+func exportTestMIDI(t *testing.T, track *parser.Track) *smf.SMF {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "track.mid")
+	if err := GenerateFile(track, filename); err != nil {
+		t.Fatalf("exporting: %v", err)
+	}
+	s, err := smf.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("reading export: %v", err)
+	}
+	return s
+}
+
+// exportedMarkers exports the track and returns its marker meta events by tick
+func exportedMarkers(t *testing.T, track *parser.Track) map[uint32]string {
+	t.Helper()
+	return markerEvents(exportTestMIDI(t, track))
+}
+
+// markerEvents returns the marker meta events of a MIDI file by tick
+func markerEvents(s *smf.SMF) map[uint32]string {
+	markers := make(map[uint32]string)
+	for _, tr := range s.Tracks {
+		var tick uint32
+		for _, evt := range tr {
+			tick += evt.Delta
+			var name string
+			if evt.Message.GetMetaMarker(&name) {
+				markers[tick] = name
+			}
+		}
+	}
+	return markers
+}
+
+func TestExportedSectionMarkers(t *testing.T) {
+	const ticksPerBar = 1920
+	for _, countIn := range []int{0, 1} {
+		track := parseTestTrack(t, fmt.Sprintf(markerTrack, countIn))
+		shift := uint32(countIn) * ticksPerBar
+		want := map[uint32]string{
+			shift:                 "Intro",
+			shift + 2*ticksPerBar: "Pre Chorus",
+			shift + 3*ticksPerBar: "Chorus",
+		}
+
+		got := exportedMarkers(t, track)
+		if len(got) != len(want) {
+			t.Errorf("count_in %d: got markers %v, want %v", countIn, got, want)
+			continue
+		}
+		for tick, name := range want {
+			if got[tick] != name {
+				t.Errorf("count_in %d: marker at tick %d = %q, want %q (all: %v)", countIn, tick, got[tick], name, got)
+			}
+		}
+	}
+}
+
+func TestGetSectionMarkersBars(t *testing.T) {
+	track := parseTestTrack(t, fmt.Sprintf(markerTrack, 2))
+	markers := GetSectionMarkers(track, 1920)
+
+	wantNames := []string{"Intro", "Pre Chorus", "Chorus"}
+	wantBars := []int{0, 2, 3}
+	if len(markers) != len(wantNames) {
+		t.Fatalf("got %d markers, want %d: %+v", len(markers), len(wantNames), markers)
+	}
+	for i, marker := range markers {
+		if marker.Name != wantNames[i] || marker.Bar != wantBars[i] {
+			t.Errorf("marker %d = %q at bar %d, want %q at bar %d", i, marker.Name, marker.Bar, wantNames[i], wantBars[i])
+		}
+		if want := uint32(2+wantBars[i]) * 1920; marker.Tick != want {
+			t.Errorf("marker %q at tick %d, want %d (after 2 count-in bars)", marker.Name, marker.Tick, want)
+		}
+	}
+}
+
+func TestSectionMarkersInRange(t *testing.T) {
+	const ticksPerBar = 1920
+	track := parseTestTrack(t, fmt.Sprintf(markerTrack, 1))
+
+	// Bars 2-3: the second Intro bar and Pre Chorus; Chorus is outside the range
+	markers := GetSectionMarkersInRange(track, ticksPerBar, 1, 3)
+	want := []SectionMarker{
+		{Name: "Intro", Bar: 1, Tick: ticksPerBar, Time: 2 * time.Second},
+		{Name: "Pre Chorus", Bar: 2, Tick: 2 * ticksPerBar, Time: 4 * time.Second},
+	}
+	if len(markers) != len(want) {
+		t.Fatalf("got markers %+v, want %+v", markers, want)
+	}
+	for i, m := range markers {
+		drift := (m.Time - want[i].Time).Abs()
+		if m.Name != want[i].Name || m.Bar != want[i].Bar || m.Tick != want[i].Tick || drift > time.Millisecond {
+			t.Errorf("marker %d = %+v, want %+v", i, m, want[i])
+		}
+	}
+
+	// The sidecar matches the marker events of the trimmed MIDI file
+	dir := t.TempDir()
+	midiFile, markerFile := filepath.Join(dir, "range.mid"), filepath.Join(dir, "range.markers.txt")
+	if err := GenerateFile(track, midiFile); err != nil {
+		t.Fatalf("exporting: %v", err)
+	}
+	if err := TrimMIDIFile(midiFile, track, 1, 3); err != nil {
+		t.Fatalf("trimming: %v", err)
+	}
+	s, err := smf.ReadFile(midiFile)
+	if err != nil {
+		t.Fatalf("reading trimmed file: %v", err)
+	}
+	trimmed := markerEvents(s)
+	for _, marker := range markers {
+		if trimmed[marker.Tick] != marker.Name {
+			t.Errorf("trimmed file has %q at tick %d, want %q (all: %v)", trimmed[marker.Tick], marker.Tick, marker.Name, trimmed)
+		}
+	}
+	if len(trimmed) != len(markers) {
+		t.Errorf("trimmed file markers %v, want %d", trimmed, len(markers))
+	}
+
+	if err := WriteMarkerFile(track, markerFile, 1, 3); err != nil {
+		t.Fatalf("writing markers: %v", err)
+	}
+	data, err := os.ReadFile(markerFile)
+	if err != nil {
+		t.Fatalf("reading markers: %v", err)
+	}
+	if got, want := string(data), "0:02.000\tIntro\n0:04.000\tPre Chorus\n"; got != want {
+		t.Errorf("marker file = %q, want %q", got, want)
+	}
+}
@@ -34,8 +34,25 @@ type MelodyConfig struct {
 	Octave        int     // Base octave (default 4)
 	Density       float64 // 0.0-1.0, how many notes to play
 	UseChordTones bool    // Prioritize chord tones on strong beats
+	Scale         string  // Explicit scale override ("" = infer from style)
+	Seed          int64   // Random seed; the same seed gives the same melody (0 = random each run)
+	RestProb      float64 // 0.0-1.0, chance of a rest at the end of each 2-bar phrase
+	Harmony       string  // Harmony voice below the melody (HarmonyThird, ...; "" = none)
 }
 
+// Harmony voices for melody.harmony: a second line under the melody, kept in the scale
+const (
+	HarmonyThird  = "third"  // Diatonic third below (twin-guitar lines)
+	HarmonySixth  = "sixth"  // Diatonic sixth below
+	HarmonyOctave = "octave" // Same line an octave down
+)
+
+// HarmonyVoices lists the melody.harmony values
+var HarmonyVoices = []string{HarmonyThird, HarmonySixth, HarmonyOctave}
+
+// melodyPhraseBars is the phrase length used for RestProb rests
+const melodyPhraseBars = 2
+
 // DefaultMelodyConfig returns sensible defaults
 func DefaultMelodyConfig() *MelodyConfig {
 	return &MelodyConfig{
@@ -46,23 +63,125 @@ func DefaultMelodyConfig() *MelodyConfig {
 	}
 }
 
+// MelodyConfigFromTrack builds the melody config from the track's melody settings
+func MelodyConfigFromTrack(track *parser.Track) *MelodyConfig {
+	config := DefaultMelodyConfig()
+	config.Scale = track.ScaleName()
+	if track.Melody == nil {
+		return config
+	}
+	if track.Melody.Style != "" {
+		config.Style = MelodyStyleFromString(track.Melody.Style)
+	}
+	if track.Melody.Density > 0 {
+		config.Density = track.Melody.Density
+	}
+	if track.Melody.Octave > 0 {
+		config.Octave = track.Melody.Octave
+	}
+	config.Seed = track.Melody.Seed
+	config.RestProb = track.Melody.RestProb
+	config.Harmony = strings.ToLower(strings.TrimSpace(track.Melody.Harmony))
+	return config
+}
+
+// AddMelodyHarmony adds the config's harmony voice to a generated melody: each note
+// gets a companion a diatonic third or sixth (or an octave) below, in the scale of
+// the chord it falls on, slightly softer than the melody
+func AddMelodyHarmony(notes []MelodyNote, chords []parser.Chord, key, style string, config *MelodyConfig, ticksPerBar uint32) []MelodyNote {
+	var target float64 // Semitones below the melody
+	switch config.Harmony {
+	case HarmonyThird:
+		target = 3.5 // Minor or major third
+	case HarmonySixth:
+		target = 8.5 // Minor or major sixth
+	case HarmonyOctave:
+		target = 12
+	default:
+		return notes
+	}
+
+	harmonized := make([]MelodyNote, 0, 2*len(notes))
+	for _, note := range notes {
+		harmonized = append(harmonized, note)
+
+		harmony := int(note.Note) - 12
+		if config.Harmony != HarmonyOctave {
+			chord := chordAtTick(chords, note.Tick, ticksPerBar)
+			scale := theory.GetScaleWithOverride(key, style, chord, config.Scale)
+			harmony = closestBelow(scale.GetScaleNotes(int(note.Note)-12, int(note.Note)-1), int(note.Note), target)
+		}
+		if harmony < 28 {
+			continue // Below the guitar's range
+		}
+
+		harmonized = append(harmonized, MelodyNote{
+			Note:     uint8(harmony),
+			Tick:     note.Tick,
+			Duration: note.Duration,
+			Velocity: uint8(int(note.Velocity) * 4 / 5),
+		})
+	}
+	return harmonized
+}
+
+// chordAtTick returns the chord symbol playing at tick
+func chordAtTick(chords []parser.Chord, tick, ticksPerBar uint32) string {
+	start := uint32(0)
+	for _, chord := range chords {
+		end := start + uint32(chord.Bars*float64(ticksPerBar))
+		if tick < end {
+			return chord.Symbol
+		}
+		start = end
+	}
+	if len(chords) > 0 {
+		return chords[len(chords)-1].Symbol
+	}
+	return ""
+}
+
+// closestBelow returns the candidate whose distance below note is closest to target
+// semitones (the higher one on a tie), or -1 if there are none
+func closestBelow(candidates []int, note int, target float64) int {
+	best, bestDiff := -1, 0.0
+	for _, c := range candidates {
+		diff := float64(note-c) - target
+		if diff < 0 {
+			diff = -diff
+		}
+		if best < 0 || diff <= bestDiff {
+			best, bestDiff = c, diff
+		}
+	}
+	return best
+}
+
 // GenerateMelody creates a melody line for the track
 func GenerateMelody(chords []parser.Chord, key string, style string, config *MelodyConfig, ticksPerBar uint32) []MelodyNote {
 	if config == nil {
 		config = DefaultMelodyConfig()
 	}
 
-	// Seed random for variation
-	rand.Seed(time.Now().UnixNano())
+	// Seeded random source so a melody can be reproduced
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
 
 	// Use special generator for blues head / call-response style
 	if config.Style == MelodyBluesHead || config.Style == MelodyCallResponse {
-		return generateBluesHead(chords, key, style, config, ticksPerBar)
+		return generateBluesHead(chords, key, style, config, ticksPerBar, rng)
 	}
 
 	notes := []MelodyNote{}
 	currentTick := uint32(0)
 
+	// Phrases that end with a rest (decided once per phrase)
+	phraseLength := melodyPhraseBars * ticksPerBar
+	restingPhrase := -1
+
 	// Start in comfortable guitar range (MIDI 52-72 = E3-C5)
 	baseNote := 52 + (config.Octave-3)*12
 	currentNote := baseNote + 7 // Start on 5th degree
@@ -74,13 +193,13 @@ func GenerateMelody(chords []parser.Chord, key string, style string, config *Mel
 
 	switch config.Style {
 	case MelodySimple:
-		noteDuration = ticksPerBar / 2  // Half notes
+		noteDuration = ticksPerBar / 2 // Half notes
 		noteSpacing = ticksPerBar / 2
 	case MelodyModerate:
-		noteDuration = ticksPerBar / 4  // Quarter notes
+		noteDuration = ticksPerBar / 4 // Quarter notes
 		noteSpacing = ticksPerBar / 4
 	case MelodyActive:
-		noteDuration = ticksPerBar / 8  // Eighth notes
+		noteDuration = ticksPerBar / 8 // Eighth notes
 		noteSpacing = ticksPerBar / 8
 	default:
 		noteDuration = ticksPerBar / 4
@@ -92,7 +211,7 @@ func GenerateMelody(chords []parser.Chord, key string, style string, config *Mel
 		chordEndTick := currentTick + chordDuration
 
 		// Get scale for this chord
-		scale := theory.GetScaleForStyle(key, style, chord.Symbol)
+		scale := theory.GetScaleWithOverride(key, style, chord.Symbol, config.Scale)
 		scaleNotes := scale.GetScaleNotes(baseNote-12, baseNote+24) // 3 octave range
 
 		// Get chord tones for emphasis
@@ -101,10 +220,21 @@ func GenerateMelody(chords []parser.Chord, key string, style string, config *Mel
 		// Generate notes for this chord
 		for tick := currentTick; tick < chordEndTick; tick += noteSpacing {
 			// Random skip based on density
-			if rand.Float64() > config.Density {
+			if rng.Float64() > config.Density {
 				continue
 			}
 
+			// Breathing room: rest through the last half bar of some phrases
+			phrase := int(tick / phraseLength)
+			if config.RestProb > 0 && tick%phraseLength >= phraseLength-ticksPerBar/2 {
+				if restingPhrase < phrase && rng.Float64() < config.RestProb {
+					restingPhrase = phrase
+				}
+				if restingPhrase == phrase {
+					continue
+				}
+			}
+
 			// Determine if this is a strong beat
 			beatInBar := (tick % ticksPerBar) / (ticksPerBar / 4)
 			isStrongBeat := beatInBar == 0 || beatInBar == 2
@@ -112,7 +242,7 @@ func GenerateMelody(chords []parser.Chord, key string, style string, config *Mel
 			// Choose next note
 			if isStrongBeat && config.UseChordTones && len(chordTones) > 0 {
 				// Strong beat: prefer chord tone
-				currentNote = chooseChordTone(chordTones, currentNote, scaleNotes, baseNote)
+				currentNote = chooseChordTone(chordTones, currentNote, scaleNotes, baseNote, rng)
 			} else {
 				// Weak beat or passing tone: stepwise motion in scale
 				currentNote = chooseScaleNote(scaleNotes, currentNote, direction)
@@ -128,26 +258,26 @@ func GenerateMelody(chords []parser.Chord, key string, style string, config *Mel
 			}
 
 			// Occasionally change direction for more musical phrases
-			if rand.Float64() < 0.15 {
+			if rng.Float64() < 0.15 {
 				direction = -direction
 			}
 
 			// Occasionally make a larger leap (3rd or 4th)
-			if rand.Float64() < 0.1 {
-				leapAmount := 2 + rand.Intn(2) // 2 or 3 scale degrees
+			if rng.Float64() < 0.1 {
+				leapAmount := 2 + rng.Intn(2) // 2 or 3 scale degrees
 				for i := 0; i < leapAmount; i++ {
 					currentNote = chooseScaleNote(scaleNotes, currentNote, direction)
 				}
 			}
 
 			// Add the note
-			velocity := uint8(65 + rand.Intn(20)) // Slight velocity variation
+			velocity := uint8(65 + rng.Intn(20)) // Slight velocity variation
 			if isStrongBeat {
 				velocity += 10 // Accent strong beats
 			}
 
 			// Slight duration variation for more natural feel
-			dur := noteDuration - uint32(rand.Intn(int(noteDuration/8)+1))
+			dur := noteDuration - uint32(rng.Intn(int(noteDuration/8)+1))
 			if dur < noteDuration/2 {
 				dur = noteDuration / 2
 			}
@@ -167,7 +297,7 @@ func GenerateMelody(chords []parser.Chord, key string, style string, config *Mel
 }
 
 // chooseChordTone selects a chord tone near the current note
-func chooseChordTone(chordTones []int, currentNote int, scaleNotes []int, baseNote int) int {
+func chooseChordTone(chordTones []int, currentNote int, scaleNotes []int, baseNote int, rng *rand.Rand) int {
 	if len(chordTones) == 0 {
 		return currentNote
 	}
@@ -195,7 +325,7 @@ func chooseChordTone(chordTones []int, currentNote int, scaleNotes []int, baseNo
 	for _, c := range candidates {
 		dist := abs(c - currentNote)
 		// Prefer notes within a 4th (5 semitones) but allow some variety
-		if dist < closestDist || (dist <= 5 && rand.Float64() < 0.3) {
+		if dist < closestDist || (dist <= 5 && rng.Float64() < 0.3) {
 			closest = c
 			closestDist = dist
 		}
@@ -287,13 +417,14 @@ func MelodyStyleFromString(s string) MelodyStyle {
 
 // generateBluesHead creates the classic AAB 12-bar blues vocal melody pattern
 // Structure per 12 bars:
-//   Bars 1-2: Call phrase (A)
-//   Bars 3-4: Response/rest
-//   Bars 5-6: Repeat call (A)
-//   Bars 7-8: Response/rest
-//   Bars 9-10: Resolution phrase (B)
-//   Bars 11-12: Turnaround/rest
-func generateBluesHead(chords []parser.Chord, key string, style string, config *MelodyConfig, ticksPerBar uint32) []MelodyNote {
+//
+//	Bars 1-2: Call phrase (A)
+//	Bars 3-4: Response/rest
+//	Bars 5-6: Repeat call (A)
+//	Bars 7-8: Response/rest
+//	Bars 9-10: Resolution phrase (B)
+//	Bars 11-12: Turnaround/rest
+func generateBluesHead(chords []parser.Chord, key string, style string, config *MelodyConfig, ticksPerBar uint32, rng *rand.Rand) []MelodyNote {
 	notes := []MelodyNote{}
 
 	// Calculate total bars
@@ -306,7 +437,7 @@ func generateBluesHead(chords []parser.Chord, key string, style string, config *
 	baseNote := 52 + (config.Octave-3)*12 // E3 for octave 3
 
 	// Get the blues scale for the key
-	scale := theory.GetScaleForStyle(key, style, "")
+	scale := theory.GetScaleWithOverride(key, style, "", config.Scale)
 	scaleNotes := scale.GetScaleNotes(baseNote-5, baseNote+12)
 
 	// Process in 12-bar chunks
@@ -325,31 +456,31 @@ func generateBluesHead(chords []parser.Chord, key string, style string, config *
 
 			switch positionIn12 {
 			case 0, 1: // Bars 1-2: First call phrase (A)
-				phraseNotes := generateCallPhrase(barStartTick, ticksPerBar, scaleNotes, chordTones, baseNote, positionIn12, config.Density)
+				phraseNotes := generateCallPhrase(barStartTick, ticksPerBar, scaleNotes, chordTones, baseNote, positionIn12, config.Density, rng)
 				notes = append(notes, phraseNotes...)
 
 			case 2, 3: // Bars 3-4: Response (sparse or rest)
-				if rand.Float64() < 0.3 { // Sometimes add a response lick
-					responseNotes := generateResponsePhrase(barStartTick, ticksPerBar, scaleNotes, baseNote)
+				if rng.Float64() < 0.3 { // Sometimes add a response lick
+					responseNotes := generateResponsePhrase(barStartTick, ticksPerBar, scaleNotes, baseNote, rng)
 					notes = append(notes, responseNotes...)
 				}
 
 			case 4, 5: // Bars 5-6: Repeat call phrase (A) - similar to first
-				phraseNotes := generateCallPhrase(barStartTick, ticksPerBar, scaleNotes, chordTones, baseNote, positionIn12-4, config.Density)
+				phraseNotes := generateCallPhrase(barStartTick, ticksPerBar, scaleNotes, chordTones, baseNote, positionIn12-4, config.Density, rng)
 				notes = append(notes, phraseNotes...)
 
 			case 6, 7: // Bars 7-8: Response (sparse or rest)
-				if rand.Float64() < 0.3 {
-					responseNotes := generateResponsePhrase(barStartTick, ticksPerBar, scaleNotes, baseNote)
+				if rng.Float64() < 0.3 {
+					responseNotes := generateResponsePhrase(barStartTick, ticksPerBar, scaleNotes, baseNote, rng)
 					notes = append(notes, responseNotes...)
 				}
 
 			case 8, 9: // Bars 9-10: Resolution phrase (B) - different melody
-				resolveNotes := generateResolutionPhrase(barStartTick, ticksPerBar, scaleNotes, chordTones, baseNote, positionIn12-8, config.Density)
+				resolveNotes := generateResolutionPhrase(barStartTick, ticksPerBar, scaleNotes, chordTones, baseNote, positionIn12-8, config.Density, rng)
 				notes = append(notes, resolveNotes...)
 
 			case 10, 11: // Bars 11-12: Turnaround (sparse or characteristic lick)
-				if positionIn12 == 10 && rand.Float64() < 0.5 {
+				if positionIn12 == 10 && rng.Float64() < 0.5 {
 					turnaroundNotes := generateTurnaroundPhrase(barStartTick, ticksPerBar, scaleNotes, baseNote)
 					notes = append(notes, turnaroundNotes...)
 				}
@@ -366,7 +497,7 @@ func generateBluesHead(chords []parser.Chord, key string, style string, config *
 
 // generateCallPhrase creates the "call" melody (sung line A)
 // Typical blues vocal phrasing: starts on/near root, moves through scale, ends on chord tone
-func generateCallPhrase(startTick, ticksPerBar uint32, scaleNotes []int, chordTones []int, baseNote int, barInPhrase int, density float64) []MelodyNote {
+func generateCallPhrase(startTick, ticksPerBar uint32, scaleNotes []int, chordTones []int, baseNote int, barInPhrase int, density float64, rng *rand.Rand) []MelodyNote {
 	notes := []MelodyNote{}
 
 	if barInPhrase == 0 {
@@ -398,7 +529,7 @@ func generateCallPhrase(startTick, ticksPerBar uint32, scaleNotes []int, chordTo
 		})
 
 		// Third note - continue descending or jump
-		if rand.Float64() < density {
+		if rng.Float64() < density {
 			tick += ticksPerBar / 4
 			thirdNote := chooseScaleNote(scaleNotes, secondNote, -1)
 			notes = append(notes, MelodyNote{
@@ -444,7 +575,7 @@ func generateCallPhrase(startTick, ticksPerBar uint32, scaleNotes []int, chordTo
 }
 
 // generateResponsePhrase creates sparse instrumental response
-func generateResponsePhrase(startTick, ticksPerBar uint32, scaleNotes []int, baseNote int) []MelodyNote {
+func generateResponsePhrase(startTick, ticksPerBar uint32, scaleNotes []int, baseNote int, rng *rand.Rand) []MelodyNote {
 	notes := []MelodyNote{}
 
 	// Simple 2-3 note response, often descending
@@ -458,7 +589,7 @@ func generateResponsePhrase(startTick, ticksPerBar uint32, scaleNotes []int, bas
 		Velocity: 65,
 	})
 
-	if rand.Float64() < 0.6 {
+	if rng.Float64() < 0.6 {
 		tick += ticksPerBar / 4
 		note2 := chooseScaleNote(scaleNotes, note1, -1)
 		notes = append(notes, MelodyNote{
@@ -474,7 +605,7 @@ func generateResponsePhrase(startTick, ticksPerBar uint32, scaleNotes []int, bas
 
 // generateResolutionPhrase creates the "B" line (resolution/answer)
 // Different melodic contour than the A phrase
-func generateResolutionPhrase(startTick, ticksPerBar uint32, scaleNotes []int, chordTones []int, baseNote int, barInPhrase int, density float64) []MelodyNote {
+func generateResolutionPhrase(startTick, ticksPerBar uint32, scaleNotes []int, chordTones []int, baseNote int, barInPhrase int, density float64, rng *rand.Rand) []MelodyNote {
 	notes := []MelodyNote{}
 
 	if barInPhrase == 0 {
@@ -502,7 +633,7 @@ func generateResolutionPhrase(startTick, ticksPerBar uint32, scaleNotes []int, c
 			Velocity: 80,
 		})
 
-		if rand.Float64() < density {
+		if rng.Float64() < density {
 			tick += ticksPerBar / 4
 			note3 := chooseScaleNote(scaleNotes, note2, -1)
 			notes = append(notes, MelodyNote{
@@ -0,0 +1,217 @@
+package midi
+
+import (
+	"strings"
+
+	"backing-tracks/parser"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// Generators for time signatures other than 4/4. The 4/4 patterns elsewhere in
+// this package are written against a 4-beat bar, so odd meters get simple
+// beat-based grooves: waltz feel for 3/4, two dotted-quarter pulses for 6/8.
+
+// isBeatGroupStart reports whether a beat (0-based) starts a pulse group:
+// every third eighth in compound meters, beat 1 and the middle of longer simple meters
+func isBeatGroupStart(ts parser.TimeSignature, beat int) bool {
+	if beat == 0 {
+		return true
+	}
+	if ts.IsCompound() {
+		return beat%3 == 0
+	}
+	return ts.Beats > 3 && beat == (ts.Beats+1)/2
+}
+
+// beatGroupLength returns how many beats the pulse group starting at beat lasts
+func beatGroupLength(ts parser.TimeSignature, beat int) int {
+	length := 1
+	for b := beat + 1; b < ts.Beats && !isBeatGroupStart(ts, b); b++ {
+		length++
+	}
+	return length
+}
+
+// GenerateChordRhythmForMeter creates chord events for the track's time signature.
+// 4/4 uses the full rhythm style library; other meters strum on every beat
+// with accents on the pulse groups (or hold the chord for the "whole" style).
+func GenerateChordRhythmForMeter(chords []parser.Chord, rhythm *parser.Rhythm, ts parser.TimeSignature, skill string) []midiEvent {
+	ticksPerBar := ts.TicksPerBar()
+	if ts.IsCommonTime() {
+		return GenerateChordRhythmWithSkill(chords, rhythm, ticksPerBar, skill)
+	}
+
+	ticksPerBeat := ts.TicksPerBeat()
+	hold := rhythm == nil || rhythm.Style == "" || rhythm.Style == "whole"
+
+	events := []midiEvent{}
+	currentTick := uint32(0)
+	for _, chord := range chords {
+		notes := getSkillVoicing(chord.Symbol, skill, rhythm.VoicingPreference())
+		duration := uint32(chord.Bars * float64(ticksPerBar))
+
+		if hold {
+			for _, note := range notes {
+				events = append(events, midiEvent{currentTick, midi.NoteOn(0, note, 80)})
+				events = append(events, midiEvent{currentTick + duration - 10, midi.NoteOff(0, note)})
+			}
+			currentTick += duration
+			continue
+		}
+
+		for offset := uint32(0); offset < duration; offset += ticksPerBeat {
+			beat := int((currentTick+offset)/ticksPerBeat) % ts.Beats // Position in the bar, also for mid-bar chords
+			vel := uint8(60)
+			if beat == 0 {
+				vel = 85
+			} else if isBeatGroupStart(ts, beat) {
+				vel = 75
+			}
+			tick := currentTick + offset
+			for _, note := range notes {
+				events = append(events, midiEvent{tick, midi.NoteOn(0, note, vel)})
+				events = append(events, midiEvent{tick + ticksPerBeat - 10, midi.NoteOff(0, note)})
+			}
+		}
+
+		currentTick += duration
+	}
+
+	muteNotes(events, rhythm.MuteLevel())
+	return events
+}
+
+// GenerateBassLineForMeter creates bass notes for the track's time signature.
+// Outside 4/4 the bass plays the root on beat 1 and the fifth on the other
+// pulse groups (beat 4 of 6/8); the "root" style holds the root only.
+// bass.octave shifts the whole line.
+func GenerateBassLineForMeter(chords []parser.Chord, bass *parser.Bass, key string, ts parser.TimeSignature) []BassNote {
+	ticksPerBar := ts.TicksPerBar()
+	if bass == nil || ts.IsCommonTime() {
+		return shiftBassOctave(GenerateBassLine(chords, bass, key, ticksPerBar), bass)
+	}
+
+	ticksPerBeat := ts.TicksPerBeat()
+	notes := []BassNote{}
+	currentTick := uint32(0)
+
+	for _, chord := range chords {
+		root := parseBassNote(chord.Symbol) + 36
+		duration := uint32(chord.Bars * float64(ticksPerBar))
+
+		for offset := uint32(0); offset < duration; offset += ticksPerBeat {
+			beat := int((currentTick+offset)/ticksPerBeat) % ts.Beats // Position in the bar, also for mid-bar chords
+			if !isBeatGroupStart(ts, beat) {
+				continue
+			}
+
+			note, vel := root, uint8(90)
+			if beat != 0 {
+				if bass.Style == "root" {
+					continue
+				}
+				note, vel = root+7, 80 // Fifth on the secondary pulse
+			}
+
+			length := uint32(beatGroupLength(ts, beat)) * ticksPerBeat
+			if bass.Style == "root" {
+				length = ticksPerBar
+			}
+			if offset+length > duration {
+				length = duration - offset
+			}
+
+			notes = append(notes, BassNote{
+				Note:     note,
+				Tick:     currentTick + offset,
+				Duration: length - 10,
+				Velocity: vel,
+			})
+		}
+
+		currentTick += duration
+	}
+
+	return shiftBassOctave(notes, bass)
+}
+
+// GenerateDrumPatternForMeter creates drum notes for the track's time signature.
+// 4/4 uses the style presets; other meters play kick on beat 1, snare on the
+// remaining pulse groups (or soft backbeats in 3/4) and hi-hats on every beat.
+func GenerateDrumPatternForMeter(totalBars int, drums *parser.Drums, ts parser.TimeSignature, dynamics []float64) []DrumNote {
+	ticksPerBar := ts.TicksPerBar()
+	if drums == nil || ts.IsCommonTime() {
+		return GenerateDrumPatternWithDynamics(totalBars, drums, ticksPerBar, dynamics)
+	}
+
+	intensity := 0.7
+	if drums.Intensity > 0 {
+		intensity = drums.Intensity
+	}
+	baseVelocity := uint8(float64(100) * intensity)
+	explicit := drums.Kick != nil || drums.Snare != nil || drums.Hihat != nil
+	accentBeats := parseAccentBeats(drums.Accent)
+
+	notes := []DrumNote{}
+	for bar := 0; bar < totalBars; bar++ {
+		barStartTick := uint32(bar) * ticksPerBar
+		level := barDynamicLevel(dynamics, bar)
+		velocity := dynamicVelocity(baseVelocity, level)
+
+		if explicit {
+			notes = append(notes, generateExplicitBar(drums, barStartTick, ticksPerBar, ts.TicksPerBeat(), velocity)...)
+			continue
+		}
+
+		barNotes := meterDrumBar(drums.Style, ts, barStartTick, velocity)
+		accentDrumBeats(barNotes, accentBeats, barStartTick, ts.TicksPerBeat())
+		if level < thinDynamicLevel {
+			barNotes = thinOffbeatHats(barNotes, barStartTick, ts.TicksPerBeat())
+		} else if level >= busyDynamicLevel {
+			barNotes = busyBar(barNotes, barStartTick, ticksPerBar)
+		}
+		notes = append(notes, barNotes...)
+		notes = append(notes, generatePercussionBar(drums, barStartTick, ticksPerBar, ts.TicksPerBeat(), velocity)...)
+	}
+
+	return addPeriodicFills(notes, drums, totalBars, ticksPerBar, sectionFillLength(ts), dynamics)
+}
+
+// meterDrumBar generates one bar of a simple groove for a non-4/4 meter
+func meterDrumBar(style string, ts parser.TimeSignature, startTick uint32, velocity uint8) []DrumNote {
+	notes := []DrumNote{}
+	ticksPerBeat := ts.TicksPerBeat()
+
+	cymbal := uint8(ClosedHihat)
+	if strings.Contains(style, "jazz") {
+		cymbal = RideCymbal
+	}
+
+	for beat := 0; beat < ts.Beats; beat++ {
+		tick := startTick + uint32(beat)*ticksPerBeat
+
+		switch {
+		case beat == 0:
+			notes = append(notes, DrumNote{Note: KickDrum, Tick: tick, Velocity: velocity + 10})
+		case isBeatGroupStart(ts, beat):
+			notes = append(notes, DrumNote{Note: SnareDrum, Tick: tick, Velocity: velocity})
+		case !ts.IsCompound():
+			// Waltz "boom-chick-chick": soft snare on the remaining beats
+			notes = append(notes, DrumNote{Note: SnareDrum, Tick: tick, Velocity: velocity - 25})
+		}
+
+		hatVelocity := velocity - 20
+		if isBeatGroupStart(ts, beat) {
+			hatVelocity = velocity - 10
+		}
+		notes = append(notes, DrumNote{Note: cymbal, Tick: tick, Velocity: hatVelocity})
+
+		// Simple meters also get off-beat eighths
+		if !ts.IsCompound() && ts.BeatUnit == 4 {
+			notes = append(notes, DrumNote{Note: cymbal, Tick: tick + ticksPerBeat/2, Velocity: velocity - 30})
+		}
+	}
+
+	return notes
+}
@@ -0,0 +1,73 @@
+package midi
+
+import (
+	"os"
+
+	"backing-tracks/parser"
+
+	"gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/smf"
+)
+
+// The metronome plays the count-in clicks on every beat of the song instead of
+// the instruments: accented hi wood block on beat 1, count_in_sound on the rest.
+
+// GenerateMetronomeData creates playback data with only metronome clicks for the
+// track's bars. Sections, lyrics and the count-in are kept so the player and TUI
+// behave as for the full track.
+func GenerateMetronomeData(track *parser.Track) *PlaybackData {
+	data := GeneratePlaybackData(track)
+	data.Events = clickEvents(GenerateCountIn(data.TotalBars, track.Info.CountInSound, track.GetTimeSignature()))
+	return data
+}
+
+// GenerateMetronomeFromTrack writes a MIDI file with only metronome clicks
+// (count-in bars included) and returns its path
+func GenerateMetronomeFromTrack(track *parser.Track) (string, error) {
+	tmpFile := PlaybackMIDIFile
+
+	s := smf.NewSMF1()
+	s.TimeFormat = smf.MetricTicks(480)
+
+	timeSig := track.GetTimeSignature()
+
+	var track0 smf.Track
+	track0.Add(0, smf.MetaTrackSequenceName(track.Info.Title))
+	track0.Add(0, smf.MetaTempo(float64(track.Info.Tempo)))
+	track0.Add(0, smf.MetaMeter(uint8(timeSig.Beats), uint8(timeSig.BeatUnit)))
+	tempoTick := uint32(0)
+	for _, evt := range tempoMetaEvents(TrackTempoMap(track), uint32(track.Info.CountIn)*timeSig.TicksPerBar()) {
+		track0.Add(evt.tick-tempoTick, evt.message)
+		tempoTick = evt.tick
+	}
+	track0.Close(0)
+	s.Add(track0)
+
+	var clicks smf.Track
+	clicks.Add(0, smf.MetaTrackSequenceName("Metronome"))
+
+	bars := track.Info.CountIn + track.Progression.TotalBars()
+	prevTick := uint32(0)
+	for _, evt := range clickEvents(GenerateCountIn(bars, track.Info.CountInSound, timeSig)) {
+		msg := midi.NoteOff(evt.Channel, evt.Note)
+		if evt.IsNoteOn {
+			msg = midi.NoteOn(evt.Channel, evt.Note, evt.Velocity)
+		}
+		clicks.Add(evt.Tick-prevTick, msg)
+		prevTick = evt.Tick
+	}
+	clicks.Close(0)
+	s.Add(clicks)
+
+	f, err := os.Create(tmpFile)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := s.WriteTo(f); err != nil {
+		return "", err
+	}
+
+	return tmpFile, nil
+}
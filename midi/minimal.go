@@ -0,0 +1,56 @@
+package midi
+
+import (
+	"backing-tracks/parser"
+)
+
+// Arrangements for track.arrangement. A minimal arrangement is for practicing over
+// the changes: the chords, melody, fingerstyle and pad are left out when the track is
+// generated (so an export is minimal too), the bass plays (root notes unless the
+// track has a bass part) and the drums are a plain kick and snare on the beats.
+const (
+	ArrangementFull    = "full"
+	ArrangementMinimal = "minimal"
+)
+
+// Arrangements lists the track.arrangement values
+var Arrangements = []string{ArrangementFull, ArrangementMinimal}
+
+// isMinimal reports whether the track is generated with the minimal arrangement
+func isMinimal(track *parser.Track) bool {
+	return track.Info.Arrangement == ArrangementMinimal
+}
+
+// arrangedBass returns the bass part to generate: the track's, or root notes on the
+// chord changes for a minimal arrangement without one (nil = no bass)
+func arrangedBass(track *parser.Track) *parser.Bass {
+	if track.Bass == nil && isMinimal(track) {
+		return &parser.Bass{Style: "root"}
+	}
+	return track.Bass
+}
+
+// GenerateMinimalDrums creates the click-like groove of a minimal arrangement: kick
+// on the odd beats and snare on the even ones, with the last beat of an odd-length
+// bar on the snare too (3/4 is kick, snare, snare). Each bar follows its dynamic level.
+func GenerateMinimalDrums(totalBars int, ts parser.TimeSignature, dynamics []float64) []DrumNote {
+	ticksPerBar, ticksPerBeat := ts.TicksPerBar(), ts.TicksPerBeat()
+
+	var notes []DrumNote
+	for bar := 0; bar < totalBars; bar++ {
+		barStartTick := uint32(bar) * ticksPerBar
+		velocity := dynamicVelocity(80, barDynamicLevel(dynamics, bar))
+		for beat := 0; beat < ts.Beats; beat++ {
+			note := uint8(KickDrum)
+			if beat%2 == 1 || (beat > 0 && beat == ts.Beats-1) {
+				note = SnareDrum
+			}
+			notes = append(notes, DrumNote{
+				Note:     note,
+				Tick:     barStartTick + uint32(beat)*ticksPerBeat,
+				Velocity: velocity,
+			})
+		}
+	}
+	return notes
+}
@@ -0,0 +1,70 @@
+package midi
+
+import (
+	"backing-tracks/parser"
+)
+
+// PadChannel is the MIDI channel used for the pad layer
+const PadChannel = 4
+
+// PadNote represents a sustained pad note
+type PadNote struct {
+	Note     uint8
+	Tick     uint32
+	Duration uint32
+	Velocity uint8
+}
+
+// GeneratePad creates sustained pad notes for the whole track.
+// The pad progression loops independently of the main chords until totalBars is filled.
+func GeneratePad(pad *parser.Pad, totalBars int, ticksPerBar uint32) []PadNote {
+	if pad == nil || pad.Pattern == "" || totalBars <= 0 {
+		return nil
+	}
+
+	barsPerChord := pad.BarsPerChord
+	progression := parser.ChordProgression{Pattern: parser.StringOrList(pad.Pattern), BarsPerChord: 1}
+	chords := progression.GetChords()
+	if len(chords) == 0 {
+		return nil
+	}
+	if barsPerChord == 0 && len(chords) == 1 {
+		barsPerChord = totalBars // Single pad chord holds for the whole track
+	}
+	if barsPerChord > 0 {
+		progression.BarsPerChord = barsPerChord
+		chords = progression.GetChords()
+	}
+
+	velocity := uint8(60)
+	if pad.Velocity > 0 && pad.Velocity <= 127 {
+		velocity = uint8(pad.Velocity)
+	}
+
+	notes := []PadNote{}
+	endTick := uint32(totalBars) * ticksPerBar
+	tick := uint32(0)
+	for i := 0; tick < endTick; i++ {
+		chord := chords[i%len(chords)]
+		duration := uint32(chord.Bars * float64(ticksPerBar))
+		if duration == 0 {
+			break
+		}
+		if tick+duration > endTick {
+			duration = endTick - tick
+		}
+
+		// Pad sits an octave above the chord voicing for an airy texture
+		for _, note := range getChordVoicing(chord.Symbol) {
+			notes = append(notes, PadNote{
+				Note:     note + 12,
+				Tick:     tick,
+				Duration: duration,
+				Velocity: velocity,
+			})
+		}
+		tick += duration
+	}
+
+	return notes
+}
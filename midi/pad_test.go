@@ -0,0 +1,99 @@
+package midi
+
+import (
+	"testing"
+
+	"gitlab.com/gomidi/midi/v2/smf"
+)
+
+// padTrack strums four chords a bar over a tonic pad
+const padTrack = `
+track:
+  title: Pad
+  key: Am
+  tempo: 100
+chord_progression:
+  pattern: "Am F C G"
+rhythm:
+  style: eighth
+pad:
+  pattern: "Am"
+`
+
+// padSpan is one exported pad note, from note on to note off
+type padSpan struct {
+	note       uint8
+	start, end uint32
+}
+
+// exportedPadNotes exports the track and returns the notes on PadChannel
+func exportedPadNotes(t *testing.T, source string) []padSpan {
+	t.Helper()
+	return padSpans(exportTestMIDI(t, parseTestTrack(t, source)))
+}
+
+// padSpans returns the notes on PadChannel of a MIDI file
+func padSpans(s *smf.SMF) []padSpan {
+	var spans []padSpan
+	for _, tr := range s.Tracks {
+		var tick uint32
+		started := make(map[uint8]uint32)
+		for _, evt := range tr {
+			tick += evt.Delta
+			var channel, key, velocity uint8
+			switch {
+			case evt.Message.GetNoteStart(&channel, &key, &velocity):
+				if channel == PadChannel {
+					started[key] = tick
+				}
+			case evt.Message.GetNoteEnd(&channel, &key):
+				if start, ok := started[key]; ok && channel == PadChannel {
+					spans = append(spans, padSpan{key, start, tick})
+					delete(started, key)
+				}
+			}
+		}
+	}
+	return spans
+}
+
+func TestTonicPadSpansTrack(t *testing.T) {
+	const ticksPerBar, totalBars = 1920, 4
+	spans := exportedPadNotes(t, padTrack)
+	if len(spans) != 3 {
+		t.Fatalf("got %d pad notes, want the 3 notes of one Am chord held for the track: %+v", len(spans), spans)
+	}
+
+	pitchClasses := make(map[uint8]bool)
+	for _, span := range spans {
+		if span.start != 0 || span.end != totalBars*ticksPerBar {
+			t.Errorf("pad note %d held from tick %d to %d, want 0 to %d", span.note, span.start, span.end, totalBars*ticksPerBar)
+		}
+		pitchClasses[span.note%12] = true
+	}
+	for _, pc := range []uint8{9, 0, 4} { // A, C, E
+		if !pitchClasses[pc] {
+			t.Errorf("pad notes %+v are missing pitch class %d of Am", spans, pc)
+		}
+	}
+}
+
+func TestGeneratePadLoopsIndependently(t *testing.T) {
+	const ticksPerBar = 1920
+	notes := GeneratePad(parseTestTrack(t, padTrack+"  bars_per_chord: 3\n").Pad, 4, ticksPerBar)
+
+	// The 3-bar Am loops once and is cut short at the end of the 4-bar track
+	starts := make(map[uint32]uint32)
+	for _, note := range notes {
+		starts[note.Tick] = note.Duration
+	}
+	want := map[uint32]uint32{0: 3 * ticksPerBar, 3 * ticksPerBar: ticksPerBar}
+	if len(starts) != len(want) {
+		t.Fatalf("pad chords start at %v, want %v", starts, want)
+	}
+	for tick, duration := range want {
+		if starts[tick] != duration {
+			t.Errorf("pad chord at tick %d lasts %d ticks, want %d", tick, starts[tick], duration)
+		}
+	}
+}
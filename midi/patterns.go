@@ -30,11 +30,11 @@ type PatternNote struct {
 
 // FingerstylePattern defines a complete picking pattern for one bar
 type FingerstylePattern struct {
-	Name         string
-	Type         PatternType
+	Name          string
+	Type          PatternType
 	TimeSignature string // "4/4", "3/4", "6/8"
-	Notes        []PatternNote
-	Description  string
+	Notes         []PatternNote
+	Description   string
 }
 
 // PatternLibrary contains all available fingerstyle patterns
@@ -22,12 +22,22 @@ type PlaybackEvent struct {
 type PlaybackData struct {
 	Events       []PlaybackEvent
 	TicksPerBar  uint32
+	BeatsPerBar  int
 	TotalTicks   uint32
 	TotalBars    int
 	Tempo        int
-	TickDuration time.Duration         // Duration of one tick
-	Sections     []parser.SectionInfo  // Section boundaries
-	Lyrics       []parser.LyricsBlock  // Lyrics for each section
+	TickDuration time.Duration        // Duration of one tick at Tempo
+	TempoMap     []TempoChange        // Tempo changes after the start (nil = constant Tempo)
+	Instruments  []InstrumentChange   // Instrument changes by section (nil = the track's throughout)
+	Sections     []parser.SectionInfo // Section boundaries
+	Lyrics       []parser.LyricsBlock // Lyrics for each section
+
+	// Count-in clicks played before tick 0 (ticks are relative to the count-in start)
+	CountInEvents []PlaybackEvent
+	CountInTicks  uint32
+
+	// First bar to play when the data is a Slice of the song (0 = from the start)
+	StartBar int
 }
 
 // GeneratePlaybackData creates playback data from a track
@@ -37,7 +47,8 @@ func GeneratePlaybackData(track *parser.Track) *PlaybackData {
 
 // GeneratePlaybackDataWithPattern creates playback data with a specific fingerstyle pattern
 func GeneratePlaybackDataWithPattern(track *parser.Track, fingerstylePattern PatternType) *PlaybackData {
-	ticksPerBar := uint32(1920) // 480 ticks per quarter * 4 quarters
+	timeSig := track.GetTimeSignature()
+	ticksPerBar := timeSig.TicksPerBar() // 480 ticks per quarter * 4 quarters in 4/4
 	ticksPerQuarter := uint32(480)
 
 	// Calculate tick duration based on tempo
@@ -53,8 +64,18 @@ func GeneratePlaybackDataWithPattern(track *parser.Track, fingerstylePattern Pat
 	}
 	totalBars := int(totalTicks / ticksPerBar)
 
+	lastBar, end, crashEnding := crashEndingBar(track, totalTicks, ticksPerBar)
+
+	minimal := isMinimal(track) // Bass and drums only
+
 	// Generate chord events using rhythm pattern
-	chordMidiEvents := GenerateChordRhythm(chords, track.Rhythm, ticksPerBar)
+	var chordMidiEvents []midiEvent
+	if !minimal {
+		chordMidiEvents = arrangedChordEvents(track, chords, timeSig)
+	}
+	if crashEnding {
+		chordMidiEvents = endChordEvents(chordMidiEvents, lastBar, end)
+	}
 	for _, evt := range chordMidiEvents {
 		// Parse the MIDI message to extract note on/off
 		msg := evt.message
@@ -84,8 +105,11 @@ func GeneratePlaybackDataWithPattern(track *parser.Track, fingerstylePattern Pat
 	}
 
 	// Generate bass events
-	if track.Bass != nil {
-		bassNotes := GenerateBassLine(chords, track.Bass, ticksPerBar)
+	if bass := arrangedBass(track); bass != nil {
+		bassNotes := GenerateBassLineForMeter(chords, bass, track.Info.Key, timeSig)
+		if crashEnding {
+			bassNotes = endBassNotes(bassNotes, lastBar, end)
+		}
 		for _, note := range bassNotes {
 			// Note on
 			events = append(events, PlaybackEvent{
@@ -107,8 +131,20 @@ func GeneratePlaybackDataWithPattern(track *parser.Track, fingerstylePattern Pat
 	}
 
 	// Generate drum events
-	if track.Drums != nil {
-		drumNotes := GenerateDrumPattern(totalBars, track.Drums, ticksPerBar)
+	if hasDrums(track) || minimal {
+		var drumNotes []DrumNote
+		if minimal {
+			drumNotes = GenerateMinimalDrums(totalBars, timeSig, track.GetBarDynamics())
+		} else {
+			drumNotes = arrangedDrumNotes(track, totalBars, timeSig)
+			if track.Drums != nil && track.Drums.SectionCue {
+				drumNotes = append(drumNotes, GenerateSectionCues(track.Progression.GetSections(), timeSig)...)
+			}
+			drumNotes = AddSectionFills(drumNotes, track.Progression.GetSections(), track.Drums, timeSig, track.GetBarDynamics())
+		}
+		if crashEnding {
+			drumNotes = endDrumNotes(drumNotes, lastBar)
+		}
 		for _, note := range drumNotes {
 			// Note on (drums are usually short hits)
 			events = append(events, PlaybackEvent{
@@ -130,17 +166,10 @@ func GeneratePlaybackDataWithPattern(track *parser.Track, fingerstylePattern Pat
 	}
 
 	// Generate melody events
-	if track.Melody != nil && track.Melody.Enabled {
-		// Create melody config from track settings
-		melodyConfig := &MelodyConfig{
-			Density:   track.Melody.Density,
-			Style:     MelodyStyle(track.Melody.Style),
-		}
-		if melodyConfig.Density == 0 {
-			melodyConfig.Density = 0.5
-		}
-
-		melodyNotes := GenerateMelody(chords, track.Info.Key, track.Info.Style, melodyConfig, ticksPerBar)
+	if track.Melody != nil && track.Melody.Enabled && !minimal {
+		melodyConfig := MelodyConfigFromTrack(track)
+		melodyNotes := arrangedMelodyNotes(track, chords, melodyConfig, ticksPerBar)
+		melodyNotes = AddMelodyHarmony(melodyNotes, chords, track.Info.Key, track.Info.Style, melodyConfig, ticksPerBar)
 		for _, note := range melodyNotes {
 			// Note on
 			events = append(events, PlaybackEvent{
@@ -161,12 +190,31 @@ func GeneratePlaybackDataWithPattern(track *parser.Track, fingerstylePattern Pat
 		}
 	}
 
-	// Generate fingerstyle events from tablature
-	tuningName := track.Info.Tuning
-	if tuningName == "" {
-		tuningName = "standard"
+	// Generate pad events
+	if track.Pad != nil && !minimal {
+		padNotes := GeneratePad(track.Pad, totalBars, ticksPerBar)
+		for _, note := range padNotes {
+			// Note on
+			events = append(events, PlaybackEvent{
+				Tick:     note.Tick,
+				Channel:  PadChannel,
+				Note:     note.Note,
+				Velocity: note.Velocity,
+				IsNoteOn: true,
+			})
+			// Note off
+			events = append(events, PlaybackEvent{
+				Tick:     note.Tick + note.Duration,
+				Channel:  PadChannel,
+				Note:     note.Note,
+				Velocity: 0,
+				IsNoteOn: false,
+			})
+		}
 	}
-	tuning := theory.GetTuning(tuningName)
+
+	// Generate fingerstyle events from tablature
+	tuning := theory.GetTuning(track.TuningName())
 	tabConfig := TablatureConfig{
 		PatternType: fingerstylePattern, // Use specified pattern, or default if empty
 		Tuning:      tuning,
@@ -174,9 +222,12 @@ func GeneratePlaybackDataWithPattern(track *parser.Track, fingerstylePattern Pat
 		ShowFingers: true,
 		Complexity:  "moderate",
 	}
-	tablature := GenerateTablature(track, tabConfig)
+	var tablature *Tablature
+	if !minimal {
+		tablature = arrangedTablature(track, tabConfig)
+	}
 	if tablature != nil {
-		ticksPerBeat := ticksPerBar / 4 // Assuming 4/4 time
+		ticksPerBeat := timeSig.TicksPerBeat() // Tab beats count in the time signature's beat unit
 		for _, bar := range tablature.Bars {
 			barStartTick := uint32((bar.BarNumber - 1)) * ticksPerBar
 			for _, note := range bar.Notes {
@@ -191,6 +242,12 @@ func GeneratePlaybackDataWithPattern(track *parser.Track, fingerstylePattern Pat
 					duration = 0.5
 				}
 				durationTicks := uint32(duration * float64(ticksPerBeat))
+				if crashEnding && noteTick >= lastBar {
+					if noteTick > lastBar {
+						continue // Only the downbeat rings out
+					}
+					durationTicks = end - noteTick
+				}
 
 				velocity := uint8(note.Velocity)
 				if velocity == 0 {
@@ -217,26 +274,67 @@ func GeneratePlaybackDataWithPattern(track *parser.Track, fingerstylePattern Pat
 		}
 	}
 
+	// Section dynamics scale the pitched parts (the drums follow them as they are generated)
+	applyPlaybackDynamics(events, track.GetBarDynamics(), ticksPerBar)
+
+	// Humanize before sorting, while each note-on still precedes its note-off
+	humanize, drumHumanize := trackHumanizers(track.Info.Humanize, track.Info.Seed)
+	humanizePlaybackEvents(events, humanize, drumHumanize)
+
 	// Sort by tick
 	sort.Slice(events, func(i, j int) bool {
 		return events[i].Tick < events[j].Tick
 	})
 
+	// Generate count-in clicks
+	countInEvents := clickEvents(GenerateCountIn(track.Info.CountIn, track.Info.CountInSound, timeSig))
+
 	sections := track.Progression.GetSections()
 	lyrics := parser.BuildLyricsBlocks(track.Sections, sections)
 
 	return &PlaybackData{
 		Events:       events,
 		TicksPerBar:  ticksPerBar,
+		BeatsPerBar:  timeSig.Beats,
 		TotalTicks:   totalTicks,
 		TotalBars:    totalBars,
 		Tempo:        track.Info.Tempo,
 		TickDuration: tickDuration,
+		TempoMap:     TrackTempoMap(track),
+		Instruments:  SectionInstrumentChanges(track),
 		Sections:     sections,
 		Lyrics:       lyrics,
+
+		CountInEvents: countInEvents,
+		CountInTicks:  uint32(track.Info.CountIn) * ticksPerBar,
 	}
 }
 
+// clickEvents converts click notes (count-in, metronome) into short drum-channel events
+func clickEvents(notes []DrumNote) []PlaybackEvent {
+	var events []PlaybackEvent
+	for _, note := range notes {
+		events = append(events, PlaybackEvent{
+			Tick:     note.Tick,
+			Channel:  9,
+			Note:     note.Note,
+			Velocity: note.Velocity,
+			IsNoteOn: true,
+		})
+		events = append(events, PlaybackEvent{
+			Tick:     note.Tick + 50,
+			Channel:  9,
+			Note:     note.Note,
+			Velocity: 0,
+			IsNoteOn: false,
+		})
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Tick < events[j].Tick
+	})
+	return events
+}
+
 // GetSectionAtBar returns the section containing the given bar, or nil if no section
 func (p *PlaybackData) GetSectionAtBar(bar int) *parser.SectionInfo {
 	for i := range p.Sections {
@@ -267,14 +365,24 @@ func (p *PlaybackData) GetEventsInRange(startTick, endTick uint32) []PlaybackEve
 	return result
 }
 
-// TickToTime converts a tick position to duration from start
+// TickToTime converts a tick position to duration from start, following the tempo map
 func (p *PlaybackData) TickToTime(tick uint32) time.Duration {
-	return time.Duration(tick) * p.TickDuration
+	return tempoMapTime(p.TempoMap, p.TickDuration, tick)
 }
 
-// TimeToTick converts a duration to tick position
+// TimeToTick converts a duration to tick position, following the tempo map
 func (p *PlaybackData) TimeToTick(d time.Duration) uint32 {
-	return uint32(d / p.TickDuration)
+	return tempoMapTick(p.TempoMap, p.TickDuration, d)
+}
+
+// TempoAt returns the tempo at the given tick, following the tempo map
+func (p *PlaybackData) TempoAt(tick uint32) float64 {
+	return tempoMapBPM(p.TempoMap, float64(p.Tempo), tick)
+}
+
+// CountInTime returns the length of the count-in (played at the starting tempo)
+func (p *PlaybackData) CountInTime() time.Duration {
+	return time.Duration(p.CountInTicks) * p.TickDuration
 }
 
 // BarToTick converts a bar number to tick position
@@ -282,6 +390,11 @@ func (p *PlaybackData) BarToTick(bar int) uint32 {
 	return uint32(bar) * p.TicksPerBar
 }
 
+// StartTime returns the song time where playback begins (the start of StartBar)
+func (p *PlaybackData) StartTime() time.Duration {
+	return p.TickToTime(p.BarToTick(p.StartBar))
+}
+
 // FluidSynthCommand generates a FluidSynth shell command for an event
 func (e *PlaybackEvent) FluidSynthCommand() string {
 	if e.IsNoteOn {
@@ -17,6 +17,12 @@ type ChordEvent struct {
 
 // GenerateChordRhythm creates chord events based on rhythm style
 func GenerateChordRhythm(chords []parser.Chord, rhythm *parser.Rhythm, ticksPerBar uint32) []midiEvent {
+	return GenerateChordRhythmWithSkill(chords, rhythm, ticksPerBar, "")
+}
+
+// GenerateChordRhythmWithSkill creates chord events voiced for a player skill level
+// (beginner = simplified chords, advanced = drop-2 and extended voicings)
+func GenerateChordRhythmWithSkill(chords []parser.Chord, rhythm *parser.Rhythm, ticksPerBar uint32, skill string) []midiEvent {
 	events := []midiEvent{}
 	currentTick := uint32(0)
 
@@ -43,16 +49,29 @@ func GenerateChordRhythm(chords []parser.Chord, rhythm *parser.Rhythm, ticksPerB
 		accentBeats = parseAccentBeats(rhythm.Accent)
 	}
 
+	mute := rhythm.MuteLevel()
 	for _, chord := range chords {
-		notes := getChordVoicing(chord.Symbol)
+		notes := getSkillVoicing(chord.Symbol, skill, rhythm.VoicingPreference())
 		duration := uint32(chord.Bars * float64(ticksPerBar))
 
+		generate := func(startTick, duration uint32) []midiEvent {
+			if style == "pattern" {
+				return generateCustomPattern(pattern, notes, startTick, duration, ticksPerBar, swing)
+			}
+			return generateRhythmPattern(style, notes, startTick, duration, ticksPerBar, swing, accentBeats)
+		}
+
 		var chordEvents []midiEvent
-		if style == "pattern" {
-			chordEvents = generateCustomPattern(pattern, notes, currentTick, duration, ticksPerBar, swing)
+		if holdsChord(style) || (currentTick%ticksPerBar == 0 && duration%ticksPerBar == 0) {
+			chordEvents = generate(currentTick, duration)
 		} else {
-			chordEvents = generateRhythmPattern(style, notes, currentTick, duration, ticksPerBar, swing, accentBeats)
+			// Partial bars (e.g. "C G" in one bar): play the pattern over the whole bars
+			// around the chord and keep its part, so each chord gets the beats it falls on
+			barStart := currentTick / ticksPerBar * ticksPerBar
+			bars := (currentTick + duration - barStart + ticksPerBar - 1) / ticksPerBar
+			chordEvents = clipToChord(generate(barStart, bars*ticksPerBar), currentTick, currentTick+duration)
 		}
+		muteNotes(chordEvents, mute)
 		events = append(events, chordEvents...)
 
 		currentTick += duration
@@ -61,6 +80,66 @@ func GenerateChordRhythm(chords []parser.Chord, rhythm *parser.Rhythm, ticksPerB
 	return events
 }
 
+// RhythmStyles lists the rhythm styles generateRhythmPattern knows, plus
+// "pattern" for a custom rhythm.pattern (keep in sync with the switch)
+var RhythmStyles = []string{
+	"whole", "half", "quarter", "eighth", "strum_down", "strum_up_down", "folk", "shuffle_strum",
+	"stride", "ragtime", "travis", "fingerpick", "arpeggio_up", "arpeggio_down", "fingerpick_slow",
+	"pima", "pima_reverse", "pami", "classical", "banjo_roll", "forward_roll", "pinch",
+	"dust_in_wind", "kansas", "landslide", "blackbird", "funk", "funk_muted", "funk_chop",
+	"sixteenth", "16th", "ska", "skank", "reggae", "one_drop", "country", "train", "disco",
+	"motown", "soul", "flamenco", "rumba", "pattern",
+}
+
+// holdsChord reports whether a rhythm style sounds the chord from its first tick
+// for its whole duration (whole, half and unknown styles) rather than following a
+// bar-based pattern
+func holdsChord(style string) bool {
+	if style == "whole" || style == "half" {
+		return true
+	}
+	for _, known := range RhythmStyles {
+		if style == known {
+			return false
+		}
+	}
+	return true
+}
+
+// clipToChord keeps the notes in events (in creation order, each note-on before its
+// note-off) that start in [startTick, endTick), ending them before endTick
+func clipToChord(events []midiEvent, startTick, endTick uint32) []midiEvent {
+	var clipped []midiEvent
+	kept := make(map[[2]uint8]uint32) // Note-on ticks of kept notes by channel and key
+	for _, evt := range events {
+		var channel, note, velocity uint8
+		switch {
+		case evt.message.GetNoteStart(&channel, &note, &velocity):
+			if evt.tick >= startTick && evt.tick < endTick {
+				kept[[2]uint8{channel, note}] = evt.tick
+				clipped = append(clipped, evt)
+			}
+		case evt.message.GetNoteEnd(&channel, &note):
+			onTick, ok := kept[[2]uint8{channel, note}]
+			if !ok {
+				continue
+			}
+			delete(kept, [2]uint8{channel, note})
+			if onTick+10 < endTick {
+				if evt.tick > endTick-10 {
+					evt.tick = endTick - 10
+				}
+			} else if evt.tick >= endTick {
+				evt.tick = endTick - 1 // Started in the last 10 ticks
+			}
+			clipped = append(clipped, evt)
+		default:
+			clipped = append(clipped, evt)
+		}
+	}
+	return clipped
+}
+
 // generateRhythmPattern creates the actual rhythm pattern for a chord
 func generateRhythmPattern(style string, notes ChordVoicing, startTick, duration, ticksPerBar uint32, swing float64, accentBeats map[int]bool) []midiEvent {
 	events := []midiEvent{}
@@ -540,13 +619,15 @@ func generateRhythmPattern(style string, notes ChordVoicing, startTick, duration
 
 // generateCustomPattern creates chord events from a custom pattern string
 // Pattern notation:
-//   D = down strum (loud, low to high)
-//   U = up strum (softer, high to low)
-//   d = soft down strum
-//   u = soft up strum
-//   x = muted/ghost strum (very short, percussive)
-//   . = rest (silence)
-//   - = tie/hold previous
+//
+//	D = down strum (loud, low to high)
+//	U = up strum (softer, high to low)
+//	d = soft down strum
+//	u = soft up strum
+//	x = muted/ghost strum (very short, percussive)
+//	. = rest (silence)
+//	- = tie/hold previous
+//
 // Pattern length determines subdivision (8 chars = 8th notes, 16 chars = 16th notes)
 func generateCustomPattern(pattern string, notes ChordVoicing, startTick, duration, ticksPerBar uint32, swing float64) []midiEvent {
 	events := []midiEvent{}
@@ -584,28 +665,16 @@ func generateCustomPattern(pattern string, notes ChordVoicing, startTick, durati
 				stepTick += swingAmount
 			}
 
-			switch char {
-			case 'D': // Loud down strum
-				events = append(events, strumChord(notes, stepTick, ticksPerStep, 85, strumDelay, false)...)
-
-			case 'd': // Soft down strum
-				events = append(events, strumChord(notes, stepTick, ticksPerStep, 65, strumDelay, false)...)
-
-			case 'U': // Loud up strum
-				events = append(events, strumChord(notes, stepTick, ticksPerStep, 75, strumDelay, true)...)
-
-			case 'u': // Soft up strum
-				events = append(events, strumChord(notes, stepTick, ticksPerStep, 55, strumDelay, true)...)
-
-			case 'x', 'X': // Muted/ghost strum (short, percussive)
-				events = append(events, strumChord(notes, stepTick, ticksPerStep/4, 50, strumDelay/2, false)...)
-
-			case '.', '-', ' ':
-				// Rest or hold - no new notes
-
-			default:
-				// Unknown character - treat as rest
+			// Rests, holds and unknown characters strike nothing
+			stroke, ok := patternStrokes[char]
+			if !ok || stroke.velocity == 0 {
+				continue
 			}
+			length, delay := ticksPerStep, strumDelay
+			if stroke.muted {
+				length, delay = ticksPerStep/4, strumDelay/2 // Short, percussive
+			}
+			events = append(events, strumChord(notes, stepTick, length, stroke.velocity, delay, stroke.up)...)
 		}
 	}
 
@@ -639,6 +708,58 @@ func strumChord(notes ChordVoicing, startTick, duration uint32, velocity uint8,
 	return events
 }
 
+// Palm-mute levels for rhythm.mute. Unset lets chords ring until the next strum;
+// muting keeps a fraction of each note's length, capped so long notes are choked too.
+const (
+	MuteLight = "light" // Shortened, still a little sustain
+	MuteHeavy = "heavy" // Short and percussive (staccato)
+)
+
+// MuteLevels lists the rhythm.mute values
+var MuteLevels = []string{MuteLight, MuteHeavy}
+
+// muteNotes shortens the notes in events (in creation order, each note-on before
+// its note-off) for a palm-mute level; an unknown or empty level leaves them as is
+func muteNotes(events []midiEvent, mute string) {
+	var keep float64
+	var maxTicks, minTicks uint32
+	switch mute {
+	case MuteLight:
+		keep, maxTicks, minTicks = 0.6, 360, 60
+	case MuteHeavy:
+		keep, maxTicks, minTicks = 0.25, 100, 30
+	default:
+		return
+	}
+
+	starts := make(map[[2]uint8]uint32) // Note-on ticks by channel and key
+	for i := range events {
+		var channel, note, velocity uint8
+		switch {
+		case events[i].message.GetNoteStart(&channel, &note, &velocity):
+			starts[[2]uint8{channel, note}] = events[i].tick
+		case events[i].message.GetNoteEnd(&channel, &note):
+			start, ok := starts[[2]uint8{channel, note}]
+			if !ok || events[i].tick <= start {
+				continue
+			}
+			delete(starts, [2]uint8{channel, note})
+
+			length := events[i].tick - start
+			muted := uint32(float64(length) * keep)
+			if muted > maxTicks {
+				muted = maxTicks
+			}
+			if muted < minTicks {
+				muted = minTicks
+			}
+			if muted < length {
+				events[i].tick = start + muted
+			}
+		}
+	}
+}
+
 // parseAccentBeats parses accent string like "1,3" into a map
 func parseAccentBeats(accent string) map[int]bool {
 	result := map[int]bool{}
@@ -684,9 +805,9 @@ func travisPicking(notes ChordVoicing, startTick, duration, ticksPerBar uint32)
 		return events
 	}
 
-	bass := notes[0]        // Root (thumb)
-	fifth := notes[0] + 7   // Fifth for alternating bass
-	mid := notes[1]         // Middle note (middle finger)
+	bass := notes[0]            // Root (thumb)
+	fifth := notes[0] + 7       // Fifth for alternating bass
+	mid := notes[1]             // Middle note (middle finger)
 	high := notes[len(notes)-1] // Highest note (index finger)
 
 	for bar := uint32(0); bar < numBars; bar++ {
@@ -857,22 +978,22 @@ func funkRhythm(notes ChordVoicing, startTick, duration, ticksPerBar uint32, mut
 			vel uint8
 			hit bool
 		}{
-			{0, 95, true},   // 1 - THE ONE (heavy!)
-			{1, 0, false},   // e
-			{2, 60, true},   // &
-			{3, 0, false},   // a
-			{4, 0, false},   // 2
-			{5, 65, true},   // e (syncopation!)
-			{6, 80, true},   // & (accent)
-			{7, 0, false},   // a
-			{8, 70, true},   // 3
-			{9, 0, false},   // e
-			{10, 60, true},  // &
-			{11, 0, false},  // a
-			{12, 0, false},  // 4
-			{13, 65, true},  // e (syncopation!)
-			{14, 0, false},  // a
-			{15, 70, true},  // a (pickup to next bar)
+			{0, 95, true},  // 1 - THE ONE (heavy!)
+			{1, 0, false},  // e
+			{2, 60, true},  // &
+			{3, 0, false},  // a
+			{4, 0, false},  // 2
+			{5, 65, true},  // e (syncopation!)
+			{6, 80, true},  // & (accent)
+			{7, 0, false},  // a
+			{8, 70, true},  // 3
+			{9, 0, false},  // e
+			{10, 60, true}, // &
+			{11, 0, false}, // a
+			{12, 0, false}, // 4
+			{13, 65, true}, // e (syncopation!)
+			{14, 0, false}, // a
+			{15, 70, true}, // a (pickup to next bar)
 		}
 
 		for _, p := range funkPattern {
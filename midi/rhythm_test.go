@@ -0,0 +1,79 @@
+package midi
+
+import (
+	"testing"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// noteSpan is one note of a clipped pattern, from note on to note off
+type noteSpan struct {
+	key     uint8
+	on, off uint32
+}
+
+// noteSpans pairs the note-ons and note-offs of events on channel 0
+func noteSpans(events []midiEvent) []noteSpan {
+	var spans []noteSpan
+	started := make(map[uint8]int)
+	for _, evt := range events {
+		var channel, key, velocity uint8
+		switch {
+		case evt.message.GetNoteStart(&channel, &key, &velocity):
+			started[key] = len(spans)
+			spans = append(spans, noteSpan{key, evt.tick, 0})
+		case evt.message.GetNoteEnd(&channel, &key):
+			spans[started[key]].off = evt.tick
+		}
+	}
+	return spans
+}
+
+func TestClipToChord(t *testing.T) {
+	// A pattern bar of four quarter-note strikes, each held for 470 ticks
+	var pattern []midiEvent
+	for beat := uint32(0); beat < 4; beat++ {
+		pattern = append(pattern,
+			midiEvent{beat * 480, midi.NoteOn(0, 60, 100)},
+			midiEvent{beat*480 + 470, midi.NoteOff(0, 60)})
+	}
+
+	for _, tc := range []struct {
+		name       string
+		start, end uint32
+		want       []noteSpan
+	}{
+		{"whole bar", 0, 1920, []noteSpan{{60, 0, 470}, {60, 480, 950}, {60, 960, 1430}, {60, 1440, 1910}}},
+		{"first half", 0, 960, []noteSpan{{60, 0, 470}, {60, 480, 950}}},
+		{"second half", 960, 1920, []noteSpan{{60, 960, 1430}, {60, 1440, 1910}}},
+		{"ends mid-note", 0, 720, []noteSpan{{60, 0, 470}, {60, 480, 710}}},
+		{"starts mid-note", 240, 960, []noteSpan{{60, 480, 950}}},
+		{"empty", 500, 900, nil},
+	} {
+		got := noteSpans(clipToChord(pattern, tc.start, tc.end))
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got notes %v, want %v", tc.name, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: note %d = %v, want %v", tc.name, i, got[i], tc.want[i])
+			}
+		}
+	}
+}
+
+func TestClipToChordLateStart(t *testing.T) {
+	// A strike 5 ticks before the chord change still ends before it
+	pattern := []midiEvent{
+		{0, midi.NoteOn(0, 60, 100)},
+		{470, midi.NoteOff(0, 60)},
+		{955, midi.NoteOn(0, 64, 100)},
+		{1430, midi.NoteOff(0, 64)},
+	}
+	got := noteSpans(clipToChord(pattern, 0, 960))
+	want := []noteSpan{{60, 0, 470}, {64, 955, 959}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got notes %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,193 @@
+package midi
+
+import (
+	"strings"
+
+	"backing-tracks/theory"
+)
+
+// Voicing preferences for rhythm.voicing. Unset keeps the default close voicing
+// in octave 3; the others play the notes of a guitar chord shape (standard tuning),
+// so playback matches the chord diagrams.
+const (
+	VoicingOpen  = "open"  // Open-position shapes near the nut
+	VoicingBarre = "barre" // Closed (movable) shapes up the neck
+	VoicingAuto  = "auto"  // Open shape when the chord has a comfortable one, else barre
+)
+
+// VoicingPreferences lists the rhythm.voicing values
+var VoicingPreferences = []string{VoicingOpen, VoicingBarre, VoicingAuto}
+
+// maxOpenFret is the highest fret an open-position shape may use; "auto" only
+// keeps open shapes that stay within comfortableOpenFret
+const (
+	maxOpenFret         = 4
+	comfortableOpenFret = 3
+)
+
+// guitarShapeVoicing returns the notes of a guitar shape for the chord in the
+// preferred position, or nil when there is no preference (or no shape)
+func guitarShapeVoicing(symbol, preference string) ChordVoicing {
+	switch preference {
+	case VoicingOpen, VoicingBarre, VoicingAuto:
+	default:
+		return nil
+	}
+
+	chord := symbol
+	if idx := strings.Index(chord, "/"); idx > 0 {
+		chord = chord[:idx]
+	}
+
+	var shape *theory.ChordVoicing
+	switch preference {
+	case VoicingOpen:
+		shape = openShape(chord)
+	case VoicingBarre:
+		shape = barreShape(chord)
+	default:
+		if shape = openShape(chord); shape == nil || shapeMaxFret(*shape) > comfortableOpenFret {
+			if barre := barreShape(chord); barre != nil {
+				shape = barre
+			}
+		}
+	}
+	if shape == nil {
+		return nil
+	}
+
+	tuning := theory.Tunings["standard"]
+	var voicing ChordVoicing
+	for str, fret := range shape.Frets {
+		if fret >= 0 && str < len(tuning.Notes) {
+			voicing = append(voicing, uint8(tuning.Notes[str]+fret))
+		}
+	}
+	if len(voicing) == 0 {
+		return nil
+	}
+	return withSlashBass(voicing, symbol)
+}
+
+// shapeCandidates returns the predefined shape for the chord (if any) followed
+// by generated shapes across the neck
+func shapeCandidates(chord string) []theory.ChordVoicing {
+	var candidates []theory.ChordVoicing
+	if gv, ok := GuitarVoicings[chord]; ok {
+		candidates = append(candidates, theory.ChordVoicing{Frets: gv.Frets})
+	} else if gv, ok := GuitarVoicings[normalizeChordSymbol(chord)]; ok {
+		candidates = append(candidates, theory.ChordVoicing{Frets: gv.Frets})
+	}
+	return append(candidates, theory.GenerateMultipleVoicings(chord, theory.Tunings["standard"], 8)...)
+}
+
+// openShape returns the shape with the lowest highest fret, if it stays within
+// maxOpenFret (earlier candidates win ties, so the predefined shape comes first)
+func openShape(chord string) *theory.ChordVoicing {
+	var best *theory.ChordVoicing
+	for _, c := range shapeCandidates(chord) {
+		if shapeStrings(c) < 4 || shapeMaxFret(c) > maxOpenFret {
+			continue
+		}
+		if best == nil || shapeMaxFret(c) < shapeMaxFret(*best) {
+			shape := c
+			best = &shape
+		}
+	}
+	return best
+}
+
+// barreShapeForms are the open chords whose shapes move up the neck as barre chords:
+// the E form (root on the low E string) and the A form (root on the A string)
+var barreShapeForms = []struct {
+	root   string
+	offset int // Pitch class of the form's root
+}{
+	{"E", 4},
+	{"A", 9},
+}
+
+// barreShape returns the chord as the lowest E-form or A-form barre shape, built by
+// moving the open E/A shape of the same quality (e.g. E7, Am7) up the neck
+func barreShape(chord string) *theory.ChordVoicing {
+	root := parseRootFromSymbol(chord)
+	quality := normalizeChordSymbol(chord)[len(root):]
+	rootClass := int(parseRoot(chord))
+
+	var best *theory.ChordVoicing
+	for _, form := range barreShapeForms {
+		template, ok := GuitarVoicings[form.root+quality]
+		if !ok {
+			continue
+		}
+		shift := (rootClass - form.offset + 12) % 12
+		if shift == 0 {
+			shift = 12 // The open chord itself; the barre version is an octave up
+		}
+
+		shape := theory.ChordVoicing{Frets: template.Frets, BaseFret: shift}
+		for str, fret := range shape.Frets {
+			if fret >= 0 {
+				shape.Frets[str] = fret + shift
+			}
+		}
+		if best == nil || shape.BaseFret < best.BaseFret {
+			best = &shape
+		}
+	}
+	return best
+}
+
+// shapeStrings returns how many strings a shape plays
+func shapeStrings(v theory.ChordVoicing) int {
+	count := 0
+	for _, fret := range v.Frets {
+		if fret >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// shapeMinFret returns the lowest played fret (0 if any string is open)
+func shapeMinFret(v theory.ChordVoicing) int {
+	lowest := -1
+	for _, fret := range v.Frets {
+		if fret >= 0 && (lowest < 0 || fret < lowest) {
+			lowest = fret
+		}
+	}
+	return lowest
+}
+
+// shapeMaxFret returns the highest played fret
+func shapeMaxFret(v theory.ChordVoicing) int {
+	highest := 0
+	for _, fret := range v.Frets {
+		if fret > highest {
+			highest = fret
+		}
+	}
+	return highest
+}
+
+// IsOpenChordShape reports whether a chord (slash bass ignored) has an easy open
+// shape in standard tuning: a predefined voicing with an open string that stays
+// within comfortableOpenFret, so no barre (C, Am, B7 but not F or Bm)
+func IsOpenChordShape(chord string) bool {
+	if idx := strings.Index(chord, "/"); idx > 0 {
+		chord = chord[:idx]
+	}
+	gv, ok := GuitarVoicings[chord]
+	if !ok {
+		if gv, ok = GuitarVoicings[normalizeChordSymbol(chord)]; !ok {
+			return false
+		}
+	}
+	shape := theory.ChordVoicing{Frets: gv.Frets}
+	hasOpenString := false
+	for _, fret := range gv.Frets {
+		hasOpenString = hasOpenString || fret == 0
+	}
+	return hasOpenString && shapeMaxFret(shape) <= comfortableOpenFret
+}
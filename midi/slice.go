@@ -0,0 +1,183 @@
+package midi
+
+import (
+	"sort"
+
+	"backing-tracks/parser"
+
+	"gitlab.com/gomidi/midi/v2/smf"
+)
+
+// Bar ranges select part of a song for practice (--from-bar/--to-bar). Bars are
+// 0-based and the end bar is exclusive. Playback keeps the song's own bar numbers
+// so the TUI shows e.g. bars 17-24; exported MIDI files start at the range instead.
+
+// clampBarRange limits a bar range to the song; endBar <= 0 means the last bar
+func clampBarRange(startBar, endBar, totalBars int) (int, int) {
+	if endBar <= 0 || endBar > totalBars {
+		endBar = totalBars
+	}
+	if startBar < 0 {
+		startBar = 0
+	}
+	if startBar >= endBar {
+		startBar = endBar - 1
+	}
+	return startBar, endBar
+}
+
+// Slice returns a copy of the playback data limited to bars [startBar, endBar).
+// Ticks stay absolute: StartBar marks where playback begins and TotalBars/TotalTicks
+// where it ends. Notes still sounding at the end bar keep their note-offs.
+func (p *PlaybackData) Slice(startBar, endBar int) *PlaybackData {
+	startBar, endBar = clampBarRange(startBar, endBar, p.TotalBars)
+	startTick, endTick := p.BarToTick(startBar), p.BarToTick(endBar)
+
+	sliced := *p
+	sliced.StartBar = startBar
+	sliced.TotalBars = endBar
+	sliced.TotalTicks = endTick
+
+	sliced.Events = nil
+	for _, evt := range p.Events {
+		if evt.Tick < startTick || evt.Tick > endTick || (evt.IsNoteOn && evt.Tick == endTick) {
+			continue
+		}
+		sliced.Events = append(sliced.Events, evt)
+	}
+
+	sliced.Sections = nil
+	for _, s := range p.Sections {
+		if s.EndBar <= startBar || s.StartBar >= endBar {
+			continue
+		}
+		if s.StartBar < startBar {
+			s.StartBar = startBar
+		}
+		if s.EndBar > endBar {
+			s.EndBar = endBar
+		}
+		sliced.Sections = append(sliced.Sections, s)
+	}
+
+	return &sliced
+}
+
+// TrimMIDIFile rewrites a MIDI file generated from track so it only contains bars
+// [startBar, endBar), moved to the start of the file. Count-in clicks are kept,
+// notes held past the end bar are cut off there, and controller changes, the
+// current section marker and notes still sounding from before the range (a pad,
+// a chord held over the bar line) are moved to its start.
+func TrimMIDIFile(midiFile string, track *parser.Track, startBar, endBar int) error {
+	s, err := smf.ReadFile(midiFile)
+	if err != nil {
+		return err
+	}
+
+	ticksPerBar := track.GetTimeSignature().TicksPerBar()
+	startBar, endBar = clampBarRange(startBar, endBar, track.Progression.TotalBars())
+
+	countInTicks := uint32(track.Info.CountIn) * ticksPerBar
+	shift := uint32(startBar) * ticksPerBar
+	rangeStart := countInTicks + shift
+	rangeEnd := countInTicks + uint32(endBar)*ticksPerBar
+
+	trimmed := smf.NewSMF1()
+	trimmed.TimeFormat = s.TimeFormat
+	for _, t := range s.Tracks {
+		trimmed.Add(trimTrack(t, countInTicks, rangeStart, rangeEnd, shift))
+	}
+
+	return trimmed.WriteFile(midiFile)
+}
+
+// trimTrack keeps one track's events between rangeStart and rangeEnd (absolute ticks),
+// shifted back by shift; events before countInTicks are kept where they are. Pitched
+// notes sounding at rangeStart are restarted there, as a realtime seek does.
+func trimTrack(t smf.Track, countInTicks, rangeStart, rangeEnd, shift uint32) smf.Track {
+	var out smf.Track
+	var lastMarker smf.Message
+	active := make(map[[2]uint8]bool) // Sounding notes by channel and key
+	held := make(map[[2]uint8]int)    // Index of notes sounding before the range by channel and key
+
+	abs, prevTick := uint32(0), uint32(0)
+	add := func(tick uint32, msg smf.Message) {
+		out.Add(tick-prevTick, msg)
+		prevTick = tick
+	}
+
+	// Notes still sounding after the events at the range start restart there
+	restartHeld := func() {
+		var starts []int
+		for key, idx := range held {
+			if key[0] != 9 {
+				starts = append(starts, idx)
+			}
+		}
+		sort.Ints(starts) // Restart in the original order
+		for _, idx := range starts {
+			var channel, key, velocity uint8
+			t[idx].Message.GetNoteStart(&channel, &key, &velocity)
+			active[[2]uint8{channel, key}] = true
+			add(rangeStart-shift, t[idx].Message)
+		}
+		held = nil
+	}
+
+	for i, evt := range t {
+		abs += evt.Delta
+		msg := evt.Message
+
+		// The section marker in effect at the range start goes first
+		if lastMarker != nil && abs >= rangeStart {
+			add(rangeStart-shift, lastMarker)
+			lastMarker = nil
+		}
+		if held != nil && abs > rangeStart {
+			restartHeld()
+		}
+
+		var channel, key, velocity uint8
+		switch {
+		case msg.Is(smf.MetaEndOfTrackMsg):
+			continue
+		case abs < countInTicks:
+			add(abs, msg)
+		case msg.GetNoteStart(&channel, &key, &velocity):
+			if abs < rangeStart {
+				held[[2]uint8{channel, key}] = i
+			} else if abs < rangeEnd {
+				delete(held, [2]uint8{channel, key})
+				active[[2]uint8{channel, key}] = true
+				add(abs-shift, msg)
+			}
+		case msg.GetNoteEnd(&channel, &key):
+			delete(held, [2]uint8{channel, key}) // Released by the range start
+			if active[[2]uint8{channel, key}] {
+				delete(active, [2]uint8{channel, key})
+				tick := abs
+				if tick > rangeEnd {
+					tick = rangeEnd
+				}
+				add(tick-shift, msg)
+			}
+		case abs >= rangeEnd:
+			// Past the range; only pending note-offs are still needed
+		case msg.Is(smf.MetaMarkerMsg) && abs < rangeStart:
+			lastMarker = msg
+		case abs < rangeStart:
+			add(rangeStart-shift, msg)
+		default:
+			add(abs-shift, msg)
+		}
+	}
+
+	if lastMarker != nil {
+		add(rangeStart-shift, lastMarker)
+	}
+	if held != nil {
+		restartHeld()
+	}
+	out.Close(0)
+	return out
+}
@@ -0,0 +1,36 @@
+package midi
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/gomidi/midi/v2/smf"
+)
+
+func TestTrimMIDIFileRestartsHeldNotes(t *testing.T) {
+	const ticksPerBar = 1920
+	track := parseTestTrack(t, padTrack)
+	filename := filepath.Join(t.TempDir(), "trimmed.mid")
+	if err := GenerateFile(track, filename); err != nil {
+		t.Fatalf("exporting: %v", err)
+	}
+
+	// Bars 2-3 of 4: the pad started at bar 0 and is still sounding
+	if err := TrimMIDIFile(filename, track, 1, 3); err != nil {
+		t.Fatalf("trimming: %v", err)
+	}
+	s, err := smf.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("reading trimmed file: %v", err)
+	}
+
+	spans := padSpans(s)
+	if len(spans) != 3 {
+		t.Fatalf("got %d pad notes, want the 3 held notes of Am restarted: %+v", len(spans), spans)
+	}
+	for _, span := range spans {
+		if span.start != 0 || span.end != 2*ticksPerBar {
+			t.Errorf("pad note %d held from tick %d to %d, want 0 to %d", span.note, span.start, span.end, 2*ticksPerBar)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package midi
+
+import (
+	"sort"
+
+	"backing-tracks/parser"
+)
+
+// StrumStep is one step of a bar of the chord part, as it is played
+type StrumStep struct {
+	Symbol   string // "↓" down strum, "↑" up strum, "x" muted, "-" ringing, "." silent
+	Velocity uint8  // Loudest note struck on the step (0 when nothing is struck)
+}
+
+// patternStroke is how one character of a custom rhythm.pattern is played
+type patternStroke struct {
+	symbol   string
+	velocity uint8 // 0 = rest
+	up       bool  // Strummed high to low
+	muted    bool  // Short, percussive strum
+}
+
+// patternStrokes maps rhythm.pattern characters to strums; others are rests
+var patternStrokes = map[rune]patternStroke{
+	'D': {symbol: "↓", velocity: 85},              // Loud down strum
+	'd': {symbol: "↓", velocity: 65},              // Soft down strum
+	'U': {symbol: "↑", velocity: 75, up: true},    // Loud up strum
+	'u': {symbol: "↑", velocity: 55, up: true},    // Soft up strum
+	'x': {symbol: "x", velocity: 50, muted: true}, // Muted/ghost strum
+	'X': {symbol: "x", velocity: 50, muted: true},
+	'-': {symbol: "-"}, // Hold
+}
+
+// Notes closer together than strokeWindow ticks belong to one strum
+const strokeWindow = 90
+
+// StrumSteps returns one bar of the chord part as steps, for displays to follow
+// what actually plays. A custom rhythm.pattern gives one step per character.
+// Otherwise a bar of C is generated with the rhythm (straight and unmuted, so
+// swing and palm muting don't blur the grid) and its strums are read back onto
+// 8 steps, or 16 when any falls between eighths; other meters get one step per beat.
+func StrumSteps(rhythm *parser.Rhythm, ts parser.TimeSignature) []StrumStep {
+	if ts.IsCommonTime() && rhythm != nil && rhythm.Pattern != "" {
+		return PatternStrumSteps(rhythm.Pattern)
+	}
+
+	var straight *parser.Rhythm
+	if rhythm != nil {
+		plain := *rhythm
+		plain.Swing, plain.Mute = 0, ""
+		straight = &plain
+	}
+	events := GenerateChordRhythmForMeter([]parser.Chord{{Symbol: "C", Bars: 1}}, straight, ts, "")
+
+	// Pair note-ons with their note-offs
+	type hit struct {
+		tick, end      uint32
+		note, velocity uint8
+	}
+	var hits []hit
+	open := make(map[uint8][]int) // Indices of sounding hits by key, oldest first
+	for _, evt := range events {
+		var channel, key, velocity uint8
+		switch {
+		case evt.message.GetNoteStart(&channel, &key, &velocity):
+			open[key] = append(open[key], len(hits))
+			hits = append(hits, hit{tick: evt.tick, end: evt.tick, note: key, velocity: velocity})
+		case evt.message.GetNoteEnd(&channel, &key):
+			if pending := open[key]; len(pending) > 0 {
+				hits[pending[0]].end = evt.tick
+				open[key] = pending[1:]
+			}
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].tick < hits[j].tick
+	})
+
+	// Group the hits into strokes: notes in quick succession, no key twice
+	var strokes [][]hit
+	for _, h := range hits {
+		if n := len(strokes); n > 0 && h.tick < strokes[n-1][0].tick+strokeWindow {
+			repeated := false
+			for _, other := range strokes[n-1] {
+				repeated = repeated || other.note == h.note
+			}
+			if !repeated {
+				strokes[n-1] = append(strokes[n-1], h)
+				continue
+			}
+		}
+		strokes = append(strokes, []hit{h})
+	}
+
+	ticksPerBar := ts.TicksPerBar()
+	numSteps := uint32(ts.Beats)
+	if ts.IsCommonTime() {
+		numSteps = 8
+		for _, stroke := range strokes {
+			if (stroke[0].tick+sixteenthTicks/2)/sixteenthTicks%2 == 1 {
+				numSteps = 16
+			}
+		}
+	}
+	stepTicks := ticksPerBar / numSteps
+
+	steps := make([]StrumStep, numSteps)
+	for i := range steps {
+		steps[i].Symbol = "."
+	}
+	for _, stroke := range strokes {
+		i := (stroke[0].tick + stepTicks/2) / stepTicks
+		if i >= numSteps {
+			continue
+		}
+		var velocity uint8
+		var length uint32
+		for _, h := range stroke {
+			velocity = max(velocity, h.velocity)
+			length = max(length, h.end-h.tick)
+		}
+		if velocity <= steps[i].Velocity {
+			continue
+		}
+
+		symbol := "↓"
+		if first, last := stroke[0], stroke[len(stroke)-1]; first.note > last.note {
+			symbol = "↑"
+		} else if length <= sixteenthTicks/2 {
+			symbol = "x"
+		}
+		steps[i] = StrumStep{Symbol: symbol, Velocity: velocity}
+	}
+
+	// Steps with nothing struck show whether the last strum still rings
+	for i := range steps {
+		if steps[i].Velocity > 0 {
+			continue
+		}
+		start := uint32(i) * stepTicks
+		for _, h := range hits {
+			if h.tick < start && h.end > start {
+				steps[i].Symbol = "-"
+				break
+			}
+		}
+	}
+	return steps
+}
+
+// PatternStrumSteps returns the steps of a custom rhythm.pattern, one per character
+func PatternStrumSteps(pattern string) []StrumStep {
+	steps := make([]StrumStep, 0, len(pattern))
+	for _, char := range pattern {
+		stroke, ok := patternStrokes[char]
+		if !ok {
+			stroke.symbol = "."
+		}
+		steps = append(steps, StrumStep{Symbol: stroke.symbol, Velocity: stroke.velocity})
+	}
+	return steps
+}
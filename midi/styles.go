@@ -0,0 +1,38 @@
+package midi
+
+// StyleGroup is one category of style names the generators recognize
+type StyleGroup struct {
+	Name   string   // Category, e.g. "Rhythm styles"
+	Field  string   // Where the names are used, e.g. "rhythm.style"
+	Styles []string // Recognized names, in generator order
+}
+
+// StyleGroups returns every compiled-in style grouped by category, built from
+// the same lists validate checks against (for the styles command)
+func StyleGroups() []StyleGroup {
+	melodyStyles := []string{
+		string(MelodySimple), string(MelodyModerate), string(MelodyActive),
+		string(MelodyBluesHead), string(MelodyCallResponse),
+	}
+
+	var patternTypes []string
+	for _, pt := range AllPatternTypes {
+		if _, ok := PatternLibrary[pt]; ok {
+			patternTypes = append(patternTypes, string(pt))
+		}
+	}
+
+	return []StyleGroup{
+		{Name: "Rhythm styles", Field: "rhythm.style", Styles: RhythmStyles},
+		{Name: "Chord voicings", Field: "rhythm.voicing", Styles: VoicingPreferences},
+		{Name: "Palm mute", Field: "rhythm.mute", Styles: MuteLevels},
+		{Name: "Drum presets", Field: "drums.style", Styles: DrumStyles},
+		{Name: "Drum sounds", Field: "drums.<voice>.sound", Styles: DrumSounds},
+		{Name: "Bass styles", Field: "bass.style", Styles: BassStyles},
+		{Name: "Melody styles", Field: "melody.style", Styles: melodyStyles},
+		{Name: "Melody harmony", Field: "melody.harmony", Styles: HarmonyVoices},
+		{Name: "Endings", Field: "track.ending", Styles: Endings},
+		{Name: "Arrangements", Field: "track.arrangement", Styles: Arrangements},
+		{Name: "Fingerstyle patterns", Field: "tab view, ; and ' keys", Styles: patternTypes},
+	}
+}
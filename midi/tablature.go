@@ -9,11 +9,11 @@ import (
 
 // TablatureConfig holds configuration for tablature generation
 type TablatureConfig struct {
-	PatternType   PatternType
-	Tuning        theory.Tuning
-	Capo          int
-	ShowFingers   bool // Show right-hand fingering (p, i, m, a)
-	Complexity    string // "simple", "moderate", "advanced"
+	PatternType PatternType
+	Tuning      theory.Tuning
+	Capo        int
+	ShowFingers bool   // Show right-hand fingering (p, i, m, a)
+	Complexity  string // "simple", "moderate", "advanced"
 }
 
 // DefaultTablatureConfig returns a sensible default configuration
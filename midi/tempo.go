@@ -0,0 +1,134 @@
+package midi
+
+import (
+	"time"
+
+	"backing-tracks/parser"
+
+	"gitlab.com/gomidi/midi/v2/smf"
+)
+
+// TempoChange sets the tempo from Tick on (song ticks, count-in excluded).
+// A tempo map is a list of changes sorted by tick; before the first change the
+// track's own tempo applies.
+type TempoChange struct {
+	Tick uint32
+	BPM  float64
+}
+
+// A ritard ending slows the last ritardBars bars a little more on every beat,
+// down to ritardEndFactor of the tempo on the final beat
+const (
+	ritardBars      = 2
+	ritardEndFactor = 0.6
+)
+
+// TrackTempoMap returns the track's tempo changes (nil when the tempo is constant):
+// section tempos and tempo_change entries, then the ritard ending if any
+func TrackTempoMap(track *parser.Track) []TempoChange {
+	if track.Info.Tempo <= 0 {
+		return nil
+	}
+
+	timeSig := track.GetTimeSignature()
+	ticksPerBar := timeSig.TicksPerBar()
+	totalBars := track.Progression.TotalBars()
+
+	var changes []TempoChange
+	current := float64(track.Info.Tempo)
+	for _, change := range track.GetTempoChanges() {
+		if change.Bar >= totalBars || change.BPM == current {
+			continue
+		}
+		tick := uint32(change.Bar) * ticksPerBar
+		if n := len(changes); n > 0 && changes[n-1].Tick == tick {
+			changes[n-1].BPM = change.BPM
+		} else {
+			changes = append(changes, TempoChange{Tick: tick, BPM: change.BPM})
+		}
+		current = change.BPM
+	}
+
+	if track.Info.Ending == EndingRitard {
+		bars := min(ritardBars, totalBars)
+		start := uint32(totalBars-bars) * ticksPerBar
+		from := tempoMapBPM(changes, float64(track.Info.Tempo), start)
+
+		// The ritard takes over from any later changes
+		kept := changes[:0]
+		for _, change := range changes {
+			if change.Tick < start {
+				kept = append(kept, change)
+			}
+		}
+		changes = kept
+
+		beats := bars * timeSig.Beats
+		for i := 1; i <= beats; i++ {
+			slowdown := (1 - ritardEndFactor) * float64(i) / float64(beats)
+			changes = append(changes, TempoChange{
+				Tick: start + uint32(i-1)*timeSig.TicksPerBeat(),
+				BPM:  from * (1 - slowdown),
+			})
+		}
+	}
+	return changes
+}
+
+// tempoMapBPM returns the tempo in effect at tick, starting from bpm
+func tempoMapBPM(changes []TempoChange, bpm float64, tick uint32) float64 {
+	for _, change := range changes {
+		if change.Tick > tick {
+			break
+		}
+		bpm = change.BPM
+	}
+	return bpm
+}
+
+// tickDurationAt returns the duration of one tick at the given tempo
+func tickDurationAt(bpm float64) time.Duration {
+	return time.Duration(float64(time.Second) * 60.0 / bpm / 480.0)
+}
+
+// tempoMapTime converts a tick to the time from tick 0, starting at tickDuration
+// and following the tempo changes
+func tempoMapTime(changes []TempoChange, tickDuration time.Duration, tick uint32) time.Duration {
+	elapsed, from := time.Duration(0), uint32(0)
+	for _, change := range changes {
+		if change.Tick >= tick {
+			break
+		}
+		elapsed += time.Duration(change.Tick-from) * tickDuration
+		from, tickDuration = change.Tick, tickDurationAt(change.BPM)
+	}
+	return elapsed + time.Duration(tick-from)*tickDuration
+}
+
+// tempoMapTick is the inverse of tempoMapTime
+func tempoMapTick(changes []TempoChange, tickDuration time.Duration, d time.Duration) uint32 {
+	elapsed, from := time.Duration(0), uint32(0)
+	for _, change := range changes {
+		next := elapsed + time.Duration(change.Tick-from)*tickDuration
+		if next > d {
+			break
+		}
+		elapsed, from, tickDuration = next, change.Tick, tickDurationAt(change.BPM)
+	}
+	return from + uint32((d-elapsed)/tickDuration)
+}
+
+// metaEvent is a meta event (tempo, marker) at an absolute tick in the exported MIDI
+type metaEvent struct {
+	tick    uint32
+	message smf.Message
+}
+
+// tempoMetaEvents returns a tempo meta event for each change, shifted past the count-in
+func tempoMetaEvents(changes []TempoChange, countInTicks uint32) []metaEvent {
+	var events []metaEvent
+	for _, change := range changes {
+		events = append(events, metaEvent{countInTicks + change.Tick, smf.MetaTempo(change.BPM)})
+	}
+	return events
+}
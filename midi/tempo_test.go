@@ -0,0 +1,35 @@
+package midi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTempoMapTimeAndTick(t *testing.T) {
+	at120, at60 := tickDurationAt(120), tickDurationAt(60)
+	slowdown := []TempoChange{{Tick: 1920, BPM: 60}}
+	speedup := []TempoChange{{Tick: 960, BPM: 240}, {Tick: 1920, BPM: 120}}
+
+	for _, tc := range []struct {
+		name    string
+		changes []TempoChange
+		tick    uint32
+		want    time.Duration
+	}{
+		{"no changes, start", nil, 0, 0},
+		{"no changes", nil, 1920, 1920 * at120},
+		{"before the change", slowdown, 960, 960 * at120},
+		{"on the change", slowdown, 1920, 1920 * at120},
+		{"after the change", slowdown, 3840, 1920*at120 + 1920*at60},
+		{"between two changes", speedup, 1440, 960*at120 + 480*tickDurationAt(240)},
+		{"after two changes", speedup, 2400, 960*at120 + 960*tickDurationAt(240) + 480*at120},
+	} {
+		got := tempoMapTime(tc.changes, at120, tc.tick)
+		if got != tc.want {
+			t.Errorf("%s: tempoMapTime(tick %d) = %v, want %v", tc.name, tc.tick, got, tc.want)
+		}
+		if back := tempoMapTick(tc.changes, at120, got); back != tc.tick {
+			t.Errorf("%s: tempoMapTick(%v) = %d, want tick %d back", tc.name, got, back, tc.tick)
+		}
+	}
+}
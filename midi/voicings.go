@@ -9,14 +9,16 @@ import (
 // Strings are indexed 0-5 where 0 = low E, 5 = high e
 type GuitarVoicing struct {
 	Name       string
-	Frets      [6]int  // Low E, A, D, G, B, high e
-	Fingers    [6]int  // 0 = not used, 1-4 = finger number, 5 = thumb
-	BassFret   int     // Which fret has the bass note (for reference)
-	BassString int     // Which string is the bass (0-5)
+	Frets      [6]int // Low E, A, D, G, B, high e
+	Fingers    [6]int // 0 = not used, 1-4 = finger number, 5 = thumb
+	BassFret   int    // Which fret has the bass note (for reference)
+	BassString int    // Which string is the bass (0-5)
 }
 
-// GetFretNote returns the MIDI note for a given string and fret using a tuning
+// GetFretNote returns the MIDI note for a given string and fret using a tuning.
+// Strings are numbered like voicings, over theory.ChordStrings(tuning).
 func GetFretNote(tuning theory.Tuning, stringNum int, fret int) int {
+	tuning = theory.ChordStrings(tuning)
 	if fret < 0 || stringNum < 0 || stringNum >= len(tuning.Notes) {
 		return -1 // muted
 	}
@@ -25,6 +27,7 @@ func GetFretNote(tuning theory.Tuning, stringNum int, fret int) int {
 
 // GetFretNoteWithCapo returns the MIDI note adjusted for capo position
 func GetFretNoteWithCapo(tuning theory.Tuning, stringNum int, fret int, capo int) int {
+	tuning = theory.ChordStrings(tuning)
 	if fret < 0 || stringNum < 0 || stringNum >= len(tuning.Notes) {
 		return -1 // muted
 	}
@@ -469,8 +472,8 @@ func isStandardTuning(tuning theory.Tuning) bool {
 // Uses the predefined voicing if available (for standard tuning),
 // otherwise generates one dynamically based on the tuning
 func GetGuitarVoicing(symbol string, tuning theory.Tuning) GuitarVoicing {
-	// Only use predefined voicings for standard tuning
-	if isStandardTuning(tuning) {
+	// Only use predefined voicings for standard tuning (or a 7-string's top six)
+	if isStandardTuning(theory.ChordStrings(tuning)) {
 		// First try exact match in predefined voicings
 		if voicing, ok := GuitarVoicings[symbol]; ok {
 			return voicing
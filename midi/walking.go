@@ -0,0 +1,176 @@
+package midi
+
+import (
+	"backing-tracks/parser"
+	"backing-tracks/theory"
+)
+
+// GenerateWalkingBass creates a quarter-note walking line: each chord starts on its
+// root (the bass note of a slash chord), walks up through its chord tones, and the
+// last beat before a chord change is a chromatic approach to the next root. After
+// the last chord the line leads back to the key's tonic (or the first chord when
+// the key is unknown), so looped playback walks into the top of the song.
+// style is the bass style: "swing_walking" leans on beats 2 and 4, "walking" on 1.
+func GenerateWalkingBass(chords []parser.Chord, key, style string, ticksPerBar uint32) []BassNote {
+	notes := []BassNote{}
+	quarterNote := ticksPerBar / 4
+	currentTick := uint32(0)
+
+	for i, chord := range chords {
+		duration := uint32(float64(ticksPerBar) * chord.Bars)
+		root := parseBassNote(chord.Symbol) + 36 // Bass octave (C2..B2)
+
+		var target uint8
+		switch {
+		case i+1 < len(chords):
+			target = parseBassNote(chords[i+1].Symbol) + 36
+		case key != "":
+			target = parseRoot(key) + 36
+		default:
+			target = parseBassNote(chords[0].Symbol) + 36
+		}
+
+		beats := duration / quarterNote
+		if beats == 0 {
+			// Shorter than a beat: just the root
+			notes = append(notes, BassNote{Note: root, Tick: currentTick, Duration: duration - 10, Velocity: 85})
+			currentTick += duration
+			continue
+		}
+
+		tones := []uint8{root, root + getThird(chord.Symbol), root + 7, root + getSeventh(chord.Symbol)}
+		prev := root
+		for b := uint32(0); b < beats; b++ {
+			tick := currentTick + b*quarterNote
+			note := tones[b%uint32(len(tones))]
+			if b == beats-1 && beats > 1 {
+				note = approachNote(prev, target)
+			}
+
+			notes = append(notes, BassNote{
+				Note:     note,
+				Tick:     tick,
+				Duration: quarterNote - 10,
+				Velocity: walkingVelocity(style, int(tick/quarterNote)%4),
+			})
+			prev = note
+		}
+
+		currentTick += duration
+	}
+
+	return notes
+}
+
+// GenerateCountryWalkBass creates a country bass line: root on beats 1 and 3 and
+// the fifth on 2 of every bar. Beat 4 is the fifth too, except in the chord's last
+// bar, where it steps into the next root (a scale tone of the key a half or whole
+// step away, else a chromatic half step). Like the walking line, the last chord
+// leads back to the key's tonic or the first chord.
+func GenerateCountryWalkBass(chords []parser.Chord, key string, ticksPerBar uint32) []BassNote {
+	notes := []BassNote{}
+	quarterNote := ticksPerBar / 4
+	currentTick := uint32(0)
+
+	var scale *theory.Scale
+	if key != "" {
+		root, isMinor := theory.ParseKey(key)
+		scaleType := theory.ScaleNaturalMajor
+		if isMinor {
+			scaleType = theory.ScaleNaturalMinor
+		}
+		scale = theory.NewScale(root, scaleType)
+	}
+
+	for i, chord := range chords {
+		duration := uint32(float64(ticksPerBar) * chord.Bars)
+		root := parseBassNote(chord.Symbol) + 36 // Bass octave (C2..B2)
+
+		var target uint8
+		switch {
+		case i+1 < len(chords):
+			target = parseBassNote(chords[i+1].Symbol) + 36
+		case key != "":
+			target = parseRoot(key) + 36
+		default:
+			target = parseBassNote(chords[0].Symbol) + 36
+		}
+
+		beats := duration / quarterNote
+		if beats == 0 {
+			// Shorter than a beat: just the root
+			notes = append(notes, BassNote{Note: root, Tick: currentTick, Duration: duration - 10, Velocity: 90})
+			currentTick += duration
+			continue
+		}
+
+		pattern := []struct {
+			note uint8
+			vel  uint8
+		}{
+			{root, 95},     // 1
+			{root + 7, 80}, // 2
+			{root, 90},     // 3
+			{root + 7, 80}, // 4
+		}
+		for b := uint32(0); b < beats; b++ {
+			step := pattern[b%4]
+			if b == beats-1 && beats > 1 {
+				step.note = countryApproachNote(pattern[(b-1)%4].note, target, scale)
+			}
+			notes = append(notes, BassNote{
+				Note:     step.note,
+				Tick:     currentTick + b*quarterNote,
+				Duration: quarterNote - 30,
+				Velocity: step.vel,
+			})
+		}
+
+		currentTick += duration
+	}
+
+	return notes
+}
+
+// countryApproachNote returns the note leading from prev into target: the key's
+// scale tone a half or whole step away on the side the line comes from (other
+// than prev itself), or a chromatic half step when the key is unknown or has no
+// such tone
+func countryApproachNote(prev, target uint8, scale *theory.Scale) uint8 {
+	if scale != nil {
+		for step := 1; step <= 2; step++ {
+			candidate := int(target) - step
+			if prev > target {
+				candidate = int(target) + step
+			}
+			if scale.ContainsNote(candidate) && candidate != int(prev) {
+				return uint8(candidate)
+			}
+		}
+	}
+	return approachNote(prev, target)
+}
+
+// approachNote returns the note a half step from target on the side the line is
+// coming from: below when walking up to it, above when walking down
+func approachNote(prev, target uint8) uint8 {
+	if prev > target {
+		return target + 1
+	}
+	return target - 1
+}
+
+// walkingVelocity accents a walking line: beat 1 for "walking", the backbeat
+// (beats 2 and 4) for the swing feel of "swing_walking"
+func walkingVelocity(style string, beat int) uint8 {
+	if style == "swing_walking" {
+		if beat%2 == 1 {
+			return 90
+		}
+		return 80
+	}
+	if beat == 0 {
+		return 90
+	}
+	return 82
+}
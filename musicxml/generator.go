@@ -0,0 +1,404 @@
+package musicxml
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"backing-tracks/display"
+	"backing-tracks/midi"
+	"backing-tracks/parser"
+	"backing-tracks/theory"
+)
+
+// divisions is the MusicXML duration unit per quarter note; it matches the
+// MIDI resolution so melody ticks can be used directly
+const divisions = 480
+
+// gridTicks is the quantization grid for notated melody notes (16th note)
+const gridTicks = divisions / 4
+
+// noteType is a notated duration: a MusicXML note type with an optional dot
+type noteType struct {
+	sixteenths int
+	name       string
+	dotted     bool
+}
+
+// noteTypes lists the note values used for melody notes, longest first
+var noteTypes = []noteType{
+	{16, "whole", false},
+	{12, "half", true},
+	{8, "half", false},
+	{6, "quarter", true},
+	{4, "quarter", false},
+	{3, "eighth", true},
+	{2, "eighth", false},
+	{1, "16th", false},
+}
+
+// melodyNote is a melody note or rest placed within one measure
+type melodyNote struct {
+	pitch    int // MIDI note number, or -1 for a rest
+	start    int // Ticks from the start of the measure
+	duration int // Ticks
+}
+
+// GenerateMusicXML converts a BTML track to a MusicXML lead sheet: chord symbols
+// as harmony elements, the generated melody (if enabled) as the notated voice,
+// and per-bar lyrics
+func GenerateMusicXML(track *parser.Track) string {
+	var sb strings.Builder
+
+	timeSig := track.GetTimeSignature()
+	ticksPerBar := int(timeSig.TicksPerBar())
+	ticksPerBeat := int(timeSig.TicksPerBeat())
+	fifths, mode := keySignature(track.Info.Key)
+	useFlats := fifths < 0
+
+	bars := display.ProcessChordsIntoBars(track)
+	measures := melodyMeasures(track, len(bars), ticksPerBar)
+
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n")
+	sb.WriteString(`<!DOCTYPE score-partwise PUBLIC "-//Recordare//DTD MusicXML 3.1 Partwise//EN" "http://www.musicxml.org/dtds/partwise.dtd">` + "\n")
+	sb.WriteString(`<score-partwise version="3.1">` + "\n")
+	sb.WriteString(fmt.Sprintf("  <work><work-title>%s</work-title></work>\n", html.EscapeString(track.Info.Title)))
+	sb.WriteString("  <identification><encoding><software>backing-tracks</software></encoding></identification>\n")
+	sb.WriteString("  <part-list>\n")
+	sb.WriteString(`    <score-part id="P1"><part-name>Lead Sheet</part-name></score-part>` + "\n")
+	sb.WriteString("  </part-list>\n")
+	sb.WriteString(`  <part id="P1">` + "\n")
+
+	lastChord := ""
+	for i, bar := range bars {
+		sb.WriteString(fmt.Sprintf(`    <measure number="%d">`+"\n", i+1))
+
+		if i == 0 {
+			sb.WriteString("      <attributes>\n")
+			sb.WriteString(fmt.Sprintf("        <divisions>%d</divisions>\n", divisions))
+			sb.WriteString(fmt.Sprintf("        <key><fifths>%d</fifths><mode>%s</mode></key>\n", fifths, mode))
+			sb.WriteString(fmt.Sprintf("        <time><beats>%d</beats><beat-type>%d</beat-type></time>\n", timeSig.Beats, timeSig.BeatUnit))
+			sb.WriteString("        <clef><sign>G</sign><line>2</line></clef>\n")
+			sb.WriteString("      </attributes>\n")
+			sb.WriteString(`      <direction placement="above"><direction-type>` +
+				fmt.Sprintf(`<metronome><beat-unit>quarter</beat-unit><per-minute>%d</per-minute></metronome>`, track.Info.Tempo) +
+				fmt.Sprintf(`</direction-type><sound tempo="%d"/></direction>`+"\n", track.Info.Tempo))
+		}
+
+		// Chord symbols only where the harmony changes, as on a lead sheet
+		for _, chord := range bar.Chords {
+			if chord.Symbol != lastChord {
+				sb.WriteString(harmonyXML(chord.Symbol, chord.StartBeat*ticksPerBeat))
+				lastChord = chord.Symbol
+			}
+		}
+
+		notes := measures[i]
+		words := strings.Fields(bar.Lyrics)
+		if len(words) > 0 && !hasPitchedNote(notes) {
+			// No melody to carry the lyrics: show them as text under the bar
+			sb.WriteString(fmt.Sprintf(`      <direction placement="below"><direction-type><words>%s</words></direction-type></direction>`+"\n",
+				html.EscapeString(bar.Lyrics)))
+			words = nil
+		}
+		sb.WriteString(notesXML(notes, words, useFlats))
+
+		sb.WriteString("    </measure>\n")
+	}
+
+	sb.WriteString("  </part>\n")
+	sb.WriteString("</score-partwise>\n")
+
+	return sb.String()
+}
+
+// melodyMeasures generates the track's melody and splits it into measures of
+// monophonic notes and rests on a 16th-note grid. Without a melody every
+// measure is a single whole-bar rest.
+func melodyMeasures(track *parser.Track, numBars, ticksPerBar int) [][]melodyNote {
+	measures := make([][]melodyNote, numBars)
+
+	var generated []midi.MelodyNote
+	if track.Melody != nil && track.Melody.Enabled {
+		config := midi.MelodyConfigFromTrack(track)
+		generated = midi.GenerateMelody(track.Progression.GetChords(), track.Info.Key, track.Info.Style, config, uint32(ticksPerBar))
+		sort.Slice(generated, func(i, j int) bool { return generated[i].Tick < generated[j].Tick })
+	}
+
+	// Quantize, then keep one note per grid position (a voice can't overlap itself)
+	byBar := make([][]melodyNote, numBars)
+	for _, n := range generated {
+		start := quantize(int(n.Tick))
+		bar := start / ticksPerBar
+		if bar >= numBars {
+			continue
+		}
+		offset := start - bar*ticksPerBar
+		if k := len(byBar[bar]); k > 0 && byBar[bar][k-1].start == offset {
+			continue
+		}
+		byBar[bar] = append(byBar[bar], melodyNote{pitch: int(n.Note), start: offset, duration: max(quantize(int(n.Duration)), gridTicks)})
+	}
+
+	for bar, notes := range byBar {
+		var measure []melodyNote
+		pos := 0
+		for i, n := range notes {
+			if n.start > pos {
+				measure = append(measure, melodyNote{pitch: -1, start: pos, duration: n.start - pos})
+			}
+			end := min(n.start+n.duration, ticksPerBar)
+			if i+1 < len(notes) {
+				end = min(end, notes[i+1].start)
+			}
+			n.duration = end - n.start
+			measure = append(measure, n)
+			pos = end
+		}
+		if pos < ticksPerBar {
+			measure = append(measure, melodyNote{pitch: -1, start: pos, duration: ticksPerBar - pos})
+		}
+		measures[bar] = measure
+	}
+
+	return measures
+}
+
+// quantize rounds ticks to the nearest 16th note
+func quantize(ticks int) int {
+	return (ticks + gridTicks/2) / gridTicks * gridTicks
+}
+
+// hasPitchedNote reports whether a measure contains a note (not only rests)
+func hasPitchedNote(notes []melodyNote) bool {
+	for _, n := range notes {
+		if n.pitch >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// notesXML writes a measure's notes and rests. Durations that have no single
+// note value are split into tied notes; lyric words go one per note, with any
+// extra words on the last note.
+func notesXML(notes []melodyNote, words []string, useFlats bool) string {
+	var sb strings.Builder
+
+	pitched := 0
+	for _, n := range notes {
+		if n.pitch >= 0 {
+			pitched++
+		}
+	}
+
+	noteIndex := 0
+	for _, n := range notes {
+		parts := splitDuration(n.duration / gridTicks)
+
+		lyric := ""
+		if n.pitch >= 0 && noteIndex < len(words) {
+			lyric = words[noteIndex]
+			if noteIndex == pitched-1 {
+				lyric = strings.Join(words[noteIndex:], " ")
+			}
+		}
+		if n.pitch >= 0 {
+			noteIndex++
+		}
+
+		for p, part := range parts {
+			sb.WriteString("      <note>\n")
+			if n.pitch < 0 {
+				sb.WriteString("        <rest/>\n")
+			} else {
+				sb.WriteString(pitchXML(n.pitch, useFlats))
+			}
+			sb.WriteString(fmt.Sprintf("        <duration>%d</duration>\n", part.sixteenths*gridTicks))
+			if n.pitch >= 0 && len(parts) > 1 {
+				if p > 0 {
+					sb.WriteString(`        <tie type="stop"/>` + "\n")
+				}
+				if p < len(parts)-1 {
+					sb.WriteString(`        <tie type="start"/>` + "\n")
+				}
+			}
+			sb.WriteString("        <voice>1</voice>\n")
+			sb.WriteString(fmt.Sprintf("        <type>%s</type>\n", part.name))
+			if part.dotted {
+				sb.WriteString("        <dot/>\n")
+			}
+			if lyric != "" && p == 0 {
+				sb.WriteString(fmt.Sprintf("        <lyric><syllabic>single</syllabic><text>%s</text></lyric>\n", html.EscapeString(lyric)))
+			}
+			sb.WriteString("      </note>\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// splitDuration breaks a duration in 16ths into note values, longest first
+func splitDuration(sixteenths int) []noteType {
+	var parts []noteType
+	for sixteenths > 0 {
+		for _, t := range noteTypes {
+			if t.sixteenths <= sixteenths {
+				parts = append(parts, t)
+				sixteenths -= t.sixteenths
+				break
+			}
+		}
+	}
+	return parts
+}
+
+// sharpSteps and flatSteps spell the 12 pitch classes as step + alter
+var (
+	sharpSteps = []struct {
+		step  string
+		alter int
+	}{{"C", 0}, {"C", 1}, {"D", 0}, {"D", 1}, {"E", 0}, {"F", 0}, {"F", 1}, {"G", 0}, {"G", 1}, {"A", 0}, {"A", 1}, {"B", 0}}
+	flatSteps = []struct {
+		step  string
+		alter int
+	}{{"C", 0}, {"D", -1}, {"D", 0}, {"E", -1}, {"E", 0}, {"F", 0}, {"G", -1}, {"G", 0}, {"A", -1}, {"A", 0}, {"B", -1}, {"B", 0}}
+)
+
+// pitchXML writes a MIDI note as a pitch element
+func pitchXML(note int, useFlats bool) string {
+	spelled := sharpSteps[note%12]
+	if useFlats {
+		spelled = flatSteps[note%12]
+	}
+	alter := ""
+	if spelled.alter != 0 {
+		alter = fmt.Sprintf("<alter>%d</alter>", spelled.alter)
+	}
+	return fmt.Sprintf("        <pitch><step>%s</step>%s<octave>%d</octave></pitch>\n", spelled.step, alter, note/12-1)
+}
+
+// harmonyXML writes a chord symbol as a harmony element at an offset into the measure
+func harmonyXML(symbol string, offset int) string {
+	root, quality, bass := splitChordSymbol(symbol)
+	if root == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("      <harmony>\n")
+	sb.WriteString(fmt.Sprintf("        <root>%s</root>\n", stepXML("root", root)))
+	sb.WriteString(fmt.Sprintf("        <kind text=\"%s\">%s</kind>\n", html.EscapeString(quality), harmonyKind(quality)))
+	if bass != "" {
+		sb.WriteString(fmt.Sprintf("        <bass>%s</bass>\n", stepXML("bass", bass)))
+	}
+	if offset > 0 {
+		sb.WriteString(fmt.Sprintf("        <offset>%d</offset>\n", offset))
+	}
+	sb.WriteString("      </harmony>\n")
+	return sb.String()
+}
+
+// stepXML writes a note name (e.g. "F#") as <prefix-step>/<prefix-alter> elements
+func stepXML(prefix, name string) string {
+	alter := ""
+	if len(name) > 1 {
+		switch name[1] {
+		case '#':
+			alter = fmt.Sprintf("<%s-alter>1</%s-alter>", prefix, prefix)
+		case 'b':
+			alter = fmt.Sprintf("<%s-alter>-1</%s-alter>", prefix, prefix)
+		}
+	}
+	return fmt.Sprintf("<%s-step>%s</%s-step>%s", prefix, name[:1], prefix, alter)
+}
+
+// splitChordSymbol splits "F#m7/C#" into root "F#", quality "m7" and bass "C#"
+func splitChordSymbol(symbol string) (root, quality, bass string) {
+	symbol = strings.TrimSpace(symbol)
+	if idx := strings.Index(symbol, "/"); idx > 0 {
+		symbol, bass = symbol[:idx], symbol[idx+1:]
+	}
+	if symbol == "" || !strings.ContainsRune("ABCDEFG", rune(symbol[0])) {
+		return "", "", ""
+	}
+	rootLen := 1
+	if len(symbol) > 1 && (symbol[1] == '#' || symbol[1] == 'b') {
+		rootLen = 2
+	}
+	return symbol[:rootLen], symbol[rootLen:], bass
+}
+
+// harmonyKind maps a chord quality to a MusicXML harmony kind
+func harmonyKind(quality string) string {
+	switch quality {
+	case "", "maj":
+		return "major"
+	case "m", "min", "-":
+		return "minor"
+	case "7":
+		return "dominant"
+	case "maj7", "M7", "^7":
+		return "major-seventh"
+	case "m7", "min7", "-7":
+		return "minor-seventh"
+	case "dim", "o", "°":
+		return "diminished"
+	case "dim7", "o7", "°7":
+		return "diminished-seventh"
+	case "m7b5", "min7b5", "ø", "ø7":
+		return "half-diminished"
+	case "aug", "+":
+		return "augmented"
+	case "sus4", "sus":
+		return "suspended-fourth"
+	case "sus2":
+		return "suspended-second"
+	case "6":
+		return "major-sixth"
+	case "m6":
+		return "minor-sixth"
+	case "9":
+		return "dominant-ninth"
+	case "maj9":
+		return "major-ninth"
+	case "m9":
+		return "minor-ninth"
+	case "11":
+		return "dominant-11th"
+	case "13":
+		return "dominant-13th"
+	case "5":
+		return "power"
+	default:
+		return "other"
+	}
+}
+
+// keySignature returns the number of sharps (positive) or flats (negative)
+// and the mode for a key such as "A", "F#m" or "Bb"
+func keySignature(key string) (int, string) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return 0, "major"
+	}
+	root, quality, _ := splitChordSymbol(key)
+	if root == "" {
+		return 0, "major"
+	}
+
+	mode := "major"
+	pc := theory.NoteToMidi(root)
+	if strings.HasPrefix(quality, "m") && !strings.HasPrefix(quality, "maj") {
+		mode = "minor"
+		pc = (pc + 3) % 12 // Relative major
+	}
+
+	// Position on the circle of fifths, preferring flats for flat-named keys
+	fifths := pc * 7 % 12
+	if fifths > 6 || (fifths == 6 && strings.Contains(root, "b")) {
+		fifths -= 12
+	}
+	return fifths, mode
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DefaultDynamicLevel is the level used when no dynamics are given (mf)
+const DefaultDynamicLevel = 0.7
+
+// dynamicMarkings maps musical dynamics to levels (0.0-1.0)
+var dynamicMarkings = map[string]float64{
+	"pp": 0.25,
+	"p":  0.4,
+	"mp": 0.55,
+	"mf": 0.7,
+	"f":  0.85,
+	"ff": 1.0,
+}
+
+// ParseDynamics converts a dynamics marking (pp..ff) or number (0.0-1.0) to a level
+// Returns false if the value is empty or not recognized
+func ParseDynamics(value string) (float64, bool) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	if value == "" {
+		return 0, false
+	}
+	if level, ok := dynamicMarkings[value]; ok {
+		return level, true
+	}
+	if level, err := strconv.ParseFloat(value, 64); err == nil && level >= 0 && level <= 1 {
+		return level, true
+	}
+	return 0, false
+}
+
+// GetBarDynamics returns the dynamic level for each bar of the song
+// Bars outside sections with dynamics use DefaultDynamicLevel
+func (t *Track) GetBarDynamics() []float64 {
+	levels := make([]float64, t.Progression.TotalBars())
+	for i := range levels {
+		levels[i] = DefaultDynamicLevel
+	}
+
+	sectionLevels := make(map[string]float64)
+	for _, section := range t.Sections {
+		if level, ok := ParseDynamics(section.Dynamics); ok {
+			sectionLevels[section.Name] = level
+		}
+	}
+	if len(sectionLevels) == 0 {
+		return levels
+	}
+
+	for _, info := range t.Progression.GetSections() {
+		level, ok := sectionLevels[info.Name]
+		if !ok {
+			continue
+		}
+		for bar := info.StartBar; bar < info.EndBar && bar < len(levels); bar++ {
+			levels[bar] = level
+		}
+	}
+	return levels
+}
@@ -0,0 +1,25 @@
+package parser
+
+// Default effect send levels (0.0-1.0) for tracks that don't set reverb/chorus
+const (
+	DefaultReverbLevel = 0.3
+	DefaultChorusLevel = 0.1
+)
+
+// ReverbLevel returns the track's reverb send level (0.0-1.0)
+func (t *Track) ReverbLevel() float64 {
+	return effectLevel(t.Info.Reverb, DefaultReverbLevel)
+}
+
+// ChorusLevel returns the track's chorus send level (0.0-1.0)
+func (t *Track) ChorusLevel() float64 {
+	return effectLevel(t.Info.Chorus, DefaultChorusLevel)
+}
+
+// effectLevel clamps a level to 0.0-1.0, using def when it isn't set
+func effectLevel(level *float64, def float64) float64 {
+	if level == nil {
+		return def
+	}
+	return min(max(*level, 0), 1)
+}
@@ -1,11 +1,14 @@
 package parser
 
 import (
+	"io"
 	"math"
 	"os"
 	"strconv"
 	"strings"
 
+	"backing-tracks/theory"
+
 	"gopkg.in/yaml.v3"
 )
 
@@ -21,23 +24,47 @@ type Track struct {
 	Lyrics      []string         `yaml:"lyrics,omitempty"` // Lyrics per bar
 	Melody      *Melody          `yaml:"melody,omitempty"` // Auto-generated melody settings
 	Scale       *ScaleConfig     `yaml:"scale,omitempty"`  // Scale override settings
+	Pad         *Pad             `yaml:"pad,omitempty"`    // Sustained pad layer under the progression
 }
 
 // Section represents a named section of the song (verse, chorus, bridge, etc.)
 type Section struct {
 	Name        string           `yaml:"name"`
 	Progression ChordProgression `yaml:"chord_progression"`
+	Dynamics    string           `yaml:"dynamics,omitempty"` // pp, p, mp, mf, f, ff or 0.0-1.0
+	Tempo       int              `yaml:"tempo,omitempty"`    // BPM for this section (default: the track tempo)
+
+	// Arrangement overrides for this section (default: the track's settings)
+	Rhythm     *Rhythm `yaml:"rhythm,omitempty"`     // Chord rhythm, replacing the track's rhythm
+	Drums      *Drums  `yaml:"drums,omitempty"`      // Drum groove, replacing the track's drums
+	Style      string  `yaml:"style,omitempty"`      // Style for the melody and fingerstyle patterns
+	Instrument string  `yaml:"instrument,omitempty"` // GM instrument for the chords
 }
 
 // TrackInfo contains metadata about the track
 type TrackInfo struct {
-	Title         string `yaml:"title"`
-	Key           string `yaml:"key"`
-	Tempo         int    `yaml:"tempo"`
-	TimeSignature string `yaml:"time_signature"`
-	Style         string `yaml:"style"`
-	Capo          int    `yaml:"capo,omitempty"`   // Capo position (0 = no capo)
-	Tuning        string `yaml:"tuning,omitempty"` // Guitar tuning (standard, drop_d, open_e, etc.)
+	Title         string       `yaml:"title"`
+	Key           string       `yaml:"key"`
+	Tempo         int          `yaml:"tempo"`
+	TempoChange   StringOrList `yaml:"tempo_change,omitempty"` // Tempo changes by bar, e.g. "bar 16 = 90"
+	TimeSignature string       `yaml:"time_signature"`
+	Style         string       `yaml:"style"`
+	Capo          int          `yaml:"capo,omitempty"`           // Capo position (0 = no capo)
+	Tuning        string       `yaml:"tuning,omitempty"`         // Guitar tuning (standard, drop_d, open_e, etc.)
+	CountIn       int          `yaml:"count_in,omitempty"`       // Bars of count-off clicks before the track starts
+	CountInSound  string       `yaml:"count_in_sound,omitempty"` // Count-off sound: click, sticks, cowbell
+	Skill         string       `yaml:"skill,omitempty"`          // Voicing difficulty: beginner, intermediate, advanced
+	Scale         string       `yaml:"scale,omitempty"`          // Explicit scale (dorian, blues, ...) instead of the style default
+	CustomTuning  string       `yaml:"custom_tuning,omitempty"`  // Comma-separated notes, low string first (overrides tuning)
+	Reverb        *float64     `yaml:"reverb,omitempty"`         // Reverb send 0.0-1.0 (default 0.3)
+	Chorus        *float64     `yaml:"chorus,omitempty"`         // Chorus send 0.0-1.0 (default 0.1)
+	Humanize      float64      `yaml:"humanize,omitempty"`       // Timing/velocity jitter 0.0-1.0 (0 = on the grid)
+	Seed          int64        `yaml:"seed,omitempty"`           // Random seed for humanize (same seed = same render)
+	Transpose     int          `yaml:"transpose,omitempty"`      // Semitones to shift the key and chords (e.g. -2 for a singer)
+	LeftHanded    bool         `yaml:"left_handed,omitempty"`    // Mirror the fretboards and chord diagrams (nut on the right)
+	EasyVoicings  bool         `yaml:"easy_voicings,omitempty"`  // Chord diagrams show the easiest voicing of each chord first
+	Ending        string       `yaml:"ending,omitempty"`         // How the last bar ends: crash, ritard or none (default)
+	Arrangement   string       `yaml:"arrangement,omitempty"`    // Parts to generate: full (default) or minimal (bass and kick/snare only)
 }
 
 // ChordProgression represents the chord sequence
@@ -83,32 +110,91 @@ type SectionInfo struct {
 	EndBar   int // Exclusive
 }
 
-// LoadTrack reads and parses a BTML file
+// SectionOccurrence is one appearance of a section in the form
+type SectionOccurrence struct {
+	SectionInfo
+	Section *Section // The section's definition (nil if it has none)
+}
+
+// GetSectionOccurrences returns every section appearance in song order with its definition
+func (t *Track) GetSectionOccurrences() []SectionOccurrence {
+	definitions := make(map[string]*Section)
+	for i := range t.Sections {
+		definitions[t.Sections[i].Name] = &t.Sections[i]
+	}
+
+	var occurrences []SectionOccurrence
+	for _, info := range t.Progression.GetSections() {
+		occurrences = append(occurrences, SectionOccurrence{info, definitions[info.Name]})
+	}
+	return occurrences
+}
+
+// Stdin is the file name that reads BTML from standard input
+const Stdin = "-"
+
+// LoadTrack reads and parses a BTML file (standard input for Stdin)
 func LoadTrack(filename string) (*Track, error) {
+	if filename == Stdin {
+		return ParseReader(os.Stdin)
+	}
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
+	return ParseTrack(data)
+}
 
+// ParseReader reads and parses BTML source
+func ParseReader(r io.Reader) (*Track, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTrack(data)
+}
+
+// ParseTrack parses BTML source
+func ParseTrack(data []byte) (*Track, error) {
 	var track Track
 	if err := yaml.Unmarshal(data, &track); err != nil {
 		return nil, err
 	}
+	if err := track.finishLoading(); err != nil {
+		return nil, err
+	}
+
+	return &track, nil
+}
+
+// finishLoading registers a custom tuning, expands sections and sets defaults after decoding
+func (t *Track) finishLoading() error {
+	// Register a custom tuning so it can be looked up by name like the built-ins
+	if t.Info.CustomTuning != "" {
+		tuning, err := ParseTuning(t.Info.CustomTuning)
+		if err != nil {
+			return err
+		}
+		theory.RegisterTuning(CustomTuningName, tuning)
+	}
+
+	// Shift the key and chords before sections are expanded
+	t.transposeChords(t.Info.Transpose)
 
 	// If sections and form are defined, expand them into Progression
-	if len(track.Sections) > 0 && len(track.Form) > 0 {
-		track.expandSections()
+	if len(t.Sections) > 0 && len(t.Form) > 0 {
+		t.expandSections()
 	}
 
 	// Set defaults
-	if track.Progression.BarsPerChord == 0 {
-		track.Progression.BarsPerChord = 1
+	if t.Progression.BarsPerChord == 0 {
+		t.Progression.BarsPerChord = 1
 	}
-	if track.Progression.Repeat == 0 {
-		track.Progression.Repeat = 1
+	if t.Progression.Repeat == 0 {
+		t.Progression.Repeat = 1
 	}
 
-	return &track, nil
+	return nil
 }
 
 // expandSections builds the Progression from Sections and Form
@@ -131,6 +217,8 @@ func (t *Track) expandSections() {
 		if !ok {
 			continue // Skip unknown sections
 		}
+		// Mark the section start so section bar ranges survive expansion
+		allChords = append(allChords, "["+sectionMarkerEscaper.Replace(section.Name)+"]")
 		// Get chords from this section (without repeat applied)
 		chords := section.Progression.GetChords()
 		for _, chord := range chords {
@@ -149,6 +237,13 @@ func (t *Track) expandSections() {
 	t.Progression.Repeat = 1       // Form already specifies the structure
 }
 
+// Section markers in an expanded pattern are single tokens, so spaces and tabs in
+// a section name ("Pre Chorus") are escaped, and unescaped when the pattern is read
+var (
+	sectionMarkerEscaper   = strings.NewReplacer("%", "%25", " ", "%20", "\t", "%09")
+	sectionMarkerUnescaper = strings.NewReplacer("%25", "%", "%20", " ", "%09", "\t")
+)
+
 // GetChords parses the pattern string and returns a slice of chords
 // Supports inline duration notation: "Em*2" = Em for 2 bars, "G*0.5" = G for half a bar
 // Supports inline section markers: "[Verse] Am G | [Chorus] C G"
@@ -160,7 +255,7 @@ func (cp *ChordProgression) GetChords() []Chord {
 	for _, part := range parts {
 		// Check for section marker [SectionName]
 		if strings.HasPrefix(part, "[") && strings.HasSuffix(part, "]") {
-			currentSection = part[1 : len(part)-1]
+			currentSection = sectionMarkerUnescaper.Replace(part[1 : len(part)-1])
 			continue
 		}
 		// Skip bar separators
@@ -192,7 +287,7 @@ func (cp *ChordProgression) GetSections() []SectionInfo {
 	chords := cp.GetChords()
 	var sections []SectionInfo
 	currentSection := ""
-	currentBar := 0
+	currentBar := 0.0 // Fractional chords ("G*0.5") share bars
 	sectionStartBar := 0
 
 	for _, chord := range chords {
@@ -202,13 +297,13 @@ func (cp *ChordProgression) GetSections() []SectionInfo {
 				sections = append(sections, SectionInfo{
 					Name:     currentSection,
 					StartBar: sectionStartBar,
-					EndBar:   currentBar,
+					EndBar:   int(math.Ceil(currentBar)),
 				})
 			}
 			currentSection = chord.Section
-			sectionStartBar = currentBar
+			sectionStartBar = int(currentBar) // The bar the section starts in
 		}
-		currentBar += int(math.Ceil(chord.Bars))
+		currentBar += chord.Bars
 	}
 
 	// Don't forget the last section
@@ -216,7 +311,7 @@ func (cp *ChordProgression) GetSections() []SectionInfo {
 		sections = append(sections, SectionInfo{
 			Name:     currentSection,
 			StartBar: sectionStartBar,
-			EndBar:   currentBar,
+			EndBar:   int(math.Ceil(currentBar)),
 		})
 	}
 
@@ -257,29 +352,64 @@ func (cp *ChordProgression) TotalBars() int {
 
 // Bass represents the bass line configuration
 type Bass struct {
-	Style      string  `yaml:"style"`              // walking, root, root_fifth, etc.
-	Pattern    string  `yaml:"pattern,omitempty"`  // Custom pattern (optional)
-	Swing      float64 `yaml:"swing,omitempty"`    // Swing feel (0.5 = straight, 0.67 = triplet)
+	Style      string  `yaml:"style"`                // walking, root, root_fifth, etc.
+	Pattern    string  `yaml:"pattern,omitempty"`    // Custom pattern (optional)
+	Swing      float64 `yaml:"swing,omitempty"`      // Swing feel (0.5 = straight, 0.67 = triplet)
 	Instrument string  `yaml:"instrument,omitempty"` // GM instrument name (default: fingered_bass)
+	Octave     int     `yaml:"octave,omitempty"`     // Octaves up (+) or down (-) from the style's register
 }
 
 // Rhythm represents the chord strumming/voicing pattern
 type Rhythm struct {
-	Style      string  `yaml:"style"`              // whole, half, quarter, eighth, strum_down, strum_up_down, folk, shuffle_strum, pattern
-	Pattern    string  `yaml:"pattern,omitempty"`  // Custom pattern: D=down, U=up, .=rest, x=muted, e.g. "D.DU.UDU"
-	Swing      float64 `yaml:"swing,omitempty"`    // Swing feel (0.5 = straight, 0.67 = triplet)
-	Accent     string  `yaml:"accent,omitempty"`   // Which beats to accent: "1", "1,3", "2,4", etc.
+	Style      string  `yaml:"style"`                // whole, half, quarter, eighth, strum_down, strum_up_down, folk, shuffle_strum, pattern
+	Pattern    string  `yaml:"pattern,omitempty"`    // Custom pattern: D=down, U=up, .=rest, x=muted, e.g. "D.DU.UDU"
+	Swing      float64 `yaml:"swing,omitempty"`      // Swing feel (0.5 = straight, 0.67 = triplet)
+	Accent     string  `yaml:"accent,omitempty"`     // Which beats to accent: "1", "1,3", "2,4", etc.
 	Instrument string  `yaml:"instrument,omitempty"` // GM instrument name (default: piano)
+	Voicing    string  `yaml:"voicing,omitempty"`    // Chord shapes: open, barre, auto (default: close voicing)
+	Mute       string  `yaml:"mute,omitempty"`       // Palm mute: light, heavy (default: chords ring out)
+}
+
+// VoicingPreference returns rhythm.voicing, lowercased ("" for a nil rhythm)
+func (r *Rhythm) VoicingPreference() string {
+	if r == nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(r.Voicing))
+}
+
+// MuteLevel returns rhythm.mute, lowercased ("" for a nil rhythm)
+func (r *Rhythm) MuteLevel() string {
+	if r == nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(r.Mute))
 }
 
 // Drums represents the drum configuration
 type Drums struct {
-	Style    string          `yaml:"style"`    // shuffle, rock_beat, jazz_swing, etc.
-	Kick     *DrumPattern    `yaml:"kick,omitempty"`
-	Snare    *DrumPattern    `yaml:"snare,omitempty"`
-	Hihat    *DrumPattern    `yaml:"hihat,omitempty"`
-	Ride     *DrumPattern    `yaml:"ride,omitempty"`
-	Intensity float64        `yaml:"intensity,omitempty"` // 0.0 to 1.0
+	Style string       `yaml:"style"`         // shuffle, rock_beat, jazz_swing, etc.
+	Kit   string       `yaml:"kit,omitempty"` // Drum kit: standard, jazz, brush, tr808, ... or prog:N
+	Kick  *DrumPattern `yaml:"kick,omitempty"`
+	Snare *DrumPattern `yaml:"snare,omitempty"`
+	Hihat *DrumPattern `yaml:"hihat,omitempty"`
+	Ride  *DrumPattern `yaml:"ride,omitempty"`
+	// Percussion voices, also layered over a style preset
+	Tom        *DrumPattern `yaml:"tom,omitempty"` // Hits step down the six toms
+	Clap       *DrumPattern `yaml:"clap,omitempty"`
+	Tambourine *DrumPattern `yaml:"tambourine,omitempty"`
+	Cowbell    *DrumPattern `yaml:"cowbell,omitempty"`
+	Intensity  float64      `yaml:"intensity,omitempty"`   // 0.0 to 1.0
+	Accent     string       `yaml:"accent,omitempty"`      // Beats where preset kick/snare hit harder: "2,4", "1,3", etc.
+	Swing      float64      `yaml:"swing,omitempty"`       // Swing of preset off-beat hats/ride (0.5 = straight, 0.67 = triplet)
+	SectionCue bool         `yaml:"section_cue,omitempty"` // Rim-click cue in the bar before each new section
+	Fills      *bool        `yaml:"fills,omitempty"`       // Fill + crash at section changes (default true)
+	FillEvery  int          `yaml:"fill_every,omitempty"`  // Fill + crash every N bars (0 = none)
+}
+
+// FillsEnabled reports whether fills are played at section changes (default true)
+func (d *Drums) FillsEnabled() bool {
+	return d.Fills == nil || *d.Fills
 }
 
 // DrumPattern represents a drum pattern (can be Euclidean or explicit)
@@ -287,18 +417,23 @@ type DrumPattern struct {
 	// Option 1: Euclidean rhythm
 	Euclidean *EuclideanRhythm `yaml:"euclidean,omitempty"`
 
-	// Option 2: Explicit pattern string
+	// Option 2: Explicit pattern string, one step per character
+	// ("X" = accent, "x" = hit, "." = rest; the bar is divided by its length)
 	Pattern string `yaml:"pattern,omitempty"`
 
 	// Option 3: Explicit beat positions
 	Beats []int `yaml:"beats,omitempty"`
+
+	// Sound played instead of the voice's own: a name (open_hihat, ride, crash,
+	// cowbell, tom_low, ...) or a GM drum note number
+	Sound string `yaml:"sound,omitempty"`
 }
 
 // EuclideanRhythm defines an algorithmic rhythm pattern
 type EuclideanRhythm struct {
-	Hits     int `yaml:"hits"`      // Number of hits
-	Steps    int `yaml:"steps"`     // Total steps
-	Rotation int `yaml:"rotation"`  // Rotation offset
+	Hits     int `yaml:"hits"`     // Number of hits
+	Steps    int `yaml:"steps"`    // Total steps
+	Rotation int `yaml:"rotation"` // Rotation offset
 }
 
 // Melody configuration for auto-generated improvisation
@@ -308,9 +443,32 @@ type Melody struct {
 	Density    float64 `yaml:"density,omitempty"`    // 0.0-1.0, how many notes to play
 	Octave     int     `yaml:"octave,omitempty"`     // Base octave (default 4)
 	Instrument string  `yaml:"instrument,omitempty"` // GM instrument name (default: steel_guitar)
+	Seed       int64   `yaml:"seed,omitempty"`       // Random seed for a repeatable melody (0 = random each run)
+	RestProb   float64 `yaml:"rest_prob,omitempty"`  // 0.0-1.0, chance of a rest between phrases
+	Harmony    string  `yaml:"harmony,omitempty"`    // Second voice below the melody: third, sixth, octave
+}
+
+// Pad is a sustained chord layer that plays independently of the main progression
+type Pad struct {
+	Pattern      string `yaml:"pattern"`                  // Pad chord(s), e.g. "Am" (tonic pedal) or "Am F*2"
+	BarsPerChord int    `yaml:"bars_per_chord,omitempty"` // Bars per pad chord (default: whole track for a single chord, else 1)
+	Instrument   string `yaml:"instrument,omitempty"`     // GM instrument name (default: synth_pad)
+	Velocity     int    `yaml:"velocity,omitempty"`       // Note velocity (default: 60)
 }
 
 // ScaleConfig allows overriding auto-detected scale
 type ScaleConfig struct {
 	Type string `yaml:"type,omitempty"` // pentatonic_minor, blues, dorian, etc.
 }
+
+// ScaleName returns the explicit scale override for the track, or "" to infer it from the style
+// track.scale takes precedence over the scale.type section
+func (t *Track) ScaleName() string {
+	if t.Info.Scale != "" {
+		return t.Info.Scale
+	}
+	if t.Scale != nil {
+		return t.Scale.Type
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position is a 1-based line and column in a BTML file (column 0 = unknown)
+type Position struct {
+	Line   int
+	Column int
+}
+
+// String returns the position as "line:column" (or just "line")
+func (p Position) String() string {
+	if p.Column == 0 {
+		return fmt.Sprintf("%d", p.Line)
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Positions records where each value of a BTML file is, keyed by field path
+// such as "rhythm.style", "drums.kick.euclidean" or "sections[1].chord_progression.pattern"
+type Positions struct {
+	nodes map[string]*yaml.Node
+}
+
+// ParseFile reads a BTML file like LoadTrack, also returning the position of each value
+func ParseFile(filename string) (*Track, Positions, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, Positions{}, err
+	}
+	return ParseSource(data)
+}
+
+// ParseSource parses BTML source like ParseTrack, also returning the position of each value
+func ParseSource(data []byte) (*Track, Positions, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, Positions{}, err
+	}
+	positions := Positions{nodes: make(map[string]*yaml.Node)}
+	if len(root.Content) > 0 {
+		positions.collect("", root.Content[0])
+	}
+
+	var track Track
+	if err := root.Decode(&track); err != nil {
+		return nil, positions, err
+	}
+	if err := track.finishLoading(); err != nil {
+		return nil, positions, err
+	}
+	return &track, positions, nil
+}
+
+// collect walks the YAML tree, recording every value node under its path
+func (p Positions) collect(path string, node *yaml.Node) {
+	p.nodes[path] = node
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if path != "" {
+				key = path + "." + key
+			}
+			p.collect(key, node.Content[i+1])
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			p.collect(fmt.Sprintf("%s[%d]", path, i), item)
+		}
+	}
+}
+
+// At returns the position of the value at path (zero if the path isn't in the file)
+func (p Positions) At(path string) Position {
+	node, ok := p.nodes[path]
+	if !ok {
+		return Position{}
+	}
+	return Position{Line: node.Line, Column: node.Column}
+}
+
+// Token returns the position of the first whitespace-separated token in the
+// value at path (a string or a list of strings), falling back to the value's position
+func (p Positions) Token(path, token string) Position {
+	node, ok := p.nodes[path]
+	if !ok {
+		return Position{}
+	}
+	if node.Kind == yaml.SequenceNode {
+		for i := range node.Content {
+			if pos, found := scalarToken(node.Content[i], token); found {
+				return pos
+			}
+		}
+	} else if pos, found := scalarToken(node, token); found {
+		return pos
+	}
+	return Position{Line: node.Line, Column: node.Column}
+}
+
+// scalarToken finds a token in a scalar node's text
+func scalarToken(node *yaml.Node, token string) (Position, bool) {
+	idx := tokenIndex(node.Value, token)
+	if idx < 0 {
+		return Position{}, false
+	}
+
+	switch node.Style {
+	case yaml.LiteralStyle, yaml.FoldedStyle:
+		// Block scalars start on the line after the indicator; columns aren't tracked
+		return Position{Line: node.Line + 1 + strings.Count(node.Value[:idx], "\n")}, true
+	case yaml.DoubleQuotedStyle, yaml.SingleQuotedStyle:
+		if strings.Contains(node.Value, "\n") {
+			return Position{Line: node.Line}, true
+		}
+		return Position{Line: node.Line, Column: node.Column + 1 + idx}, true
+	default:
+		if strings.Contains(node.Value, "\n") {
+			return Position{Line: node.Line}, true
+		}
+		return Position{Line: node.Line, Column: node.Column + idx}, true
+	}
+}
+
+// tokenIndex returns the byte index of token as a whole whitespace-separated word in s, or -1
+func tokenIndex(s, token string) int {
+	for start := 0; start < len(s); {
+		idx := strings.Index(s[start:], token)
+		if idx < 0 {
+			return -1
+		}
+		idx += start
+		end := idx + len(token)
+		before := idx == 0 || strings.ContainsRune(" \t\n", rune(s[idx-1]))
+		after := end == len(s) || strings.ContainsRune(" \t\n", rune(s[end]))
+		if before && after {
+			return idx
+		}
+		start = idx + 1
+	}
+	return -1
+}
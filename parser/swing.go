@@ -0,0 +1,58 @@
+package parser
+
+// TripletSwing is the swing of a triplet feel (67/33), what a straight track gets
+// when swing is switched on in the player
+const TripletSwing = 0.67
+
+// IsSwung reports whether any part of the track swings: the rhythm, bass or drums,
+// of the track or of a section
+func (t *Track) IsSwung() bool {
+	swung := func(swing float64) bool { return swing > 0.5 }
+	if (t.Rhythm != nil && swung(t.Rhythm.Swing)) || (t.Bass != nil && swung(t.Bass.Swing)) ||
+		(t.Drums != nil && swung(t.Drums.Swing)) {
+		return true
+	}
+	for _, section := range t.Sections {
+		if (section.Rhythm != nil && swung(section.Rhythm.Swing)) || (section.Drums != nil && swung(section.Drums.Swing)) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithSwing returns a copy of the track with the swing of every rhythm, bass and
+// drums part set to swing (0.5 = straight). The track itself is unchanged.
+func (t *Track) WithSwing(swing float64) *Track {
+	swung := *t
+	if t.Rhythm != nil {
+		rhythm := *t.Rhythm
+		rhythm.Swing = swing
+		swung.Rhythm = &rhythm
+	}
+	if t.Bass != nil {
+		bass := *t.Bass
+		bass.Swing = swing
+		swung.Bass = &bass
+	}
+	if t.Drums != nil {
+		drums := *t.Drums
+		drums.Swing = swing
+		swung.Drums = &drums
+	}
+
+	swung.Sections = append([]Section(nil), t.Sections...)
+	for i := range swung.Sections {
+		section := &swung.Sections[i]
+		if section.Rhythm != nil {
+			rhythm := *section.Rhythm
+			rhythm.Swing = swing
+			section.Rhythm = &rhythm
+		}
+		if section.Drums != nil {
+			drums := *section.Drums
+			drums.Swing = swing
+			section.Drums = &drums
+		}
+	}
+	return &swung
+}
@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Per-bar lyrics (the top-level lyrics list) can be timed so displays follow the
+// singer syllable by syllable. Two notations, which can be mixed:
+//
+//	"@1 A- @2.5 ma- @3 zing"   @beat before a word sets the beat it's sung on (from 1)
+//	"Some- where | o- ver"     "|" starts a part on the bar's next chord
+//
+// A word ending in "-" is a syllable joined to the next word. Untimed words are
+// spread evenly between the timed ones.
+
+// LyricSyllable is one word or syllable of a bar's lyrics and when it is sung
+type LyricSyllable struct {
+	Text string  // As displayed, including the space after a word ("A", "ma", "zing ")
+	Beat float64 // Beats from the start of the bar (0 = downbeat)
+}
+
+// ParseBarLyrics parses one bar of lyrics. It returns the text as displayed (markers
+// removed, hyphenated syllables joined) and its syllables, or nil syllables when the
+// bar has no timing. chordBeats are the beats the bar's chords start on, for "|"
+// parts; parts beyond the last chord are spread over the rest of the bar.
+func ParseBarLyrics(lyrics string, beatsPerBar int, chordBeats []float64) (string, []LyricSyllable) {
+	if !strings.ContainsAny(lyrics, "@|") {
+		return strings.TrimSpace(lyrics), nil
+	}
+
+	type word struct {
+		text  string
+		beat  float64
+		timed bool
+	}
+	if len(chordBeats) == 0 {
+		chordBeats = []float64{0}
+	}
+	parts, last := strings.Count(lyrics, "|")+1, chordBeats[len(chordBeats)-1]
+
+	var words []word
+	part := 0
+	pending, hasPending := 0.0, false // A marker waiting for its word ("@2 word")
+	for _, token := range strings.Fields(strings.ReplaceAll(lyrics, "|", " | ")) {
+		if token == "|" {
+			part++
+			if part < len(chordBeats) {
+				pending = chordBeats[part]
+			} else {
+				// Parts beyond the last chord share what is left of the bar
+				extra := parts - len(chordBeats)
+				pending = last + (float64(beatsPerBar)-last)*float64(part-len(chordBeats)+1)/float64(extra+1)
+			}
+			hasPending = true
+			continue
+		}
+		if rest, ok := strings.CutPrefix(token, "@"); ok {
+			number := rest
+			if i := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' }); i >= 0 {
+				number = rest[:i]
+			}
+			if beat, err := strconv.ParseFloat(number, 64); err == nil && number != "" {
+				pending, hasPending = max(0, beat-1), true
+				token = rest[len(number):]
+				if token == "" {
+					continue
+				}
+			}
+		}
+		words = append(words, word{text: token, beat: pending, timed: hasPending})
+		hasPending = false
+	}
+	if len(words) == 0 {
+		return "", nil
+	}
+
+	// Untimed words share the time between their timed neighbours
+	for i := 0; i < len(words); {
+		if words[i].timed {
+			i++
+			continue
+		}
+		j := i
+		for j < len(words) && !words[j].timed {
+			j++
+		}
+		from, to := 0.0, float64(beatsPerBar)
+		if i > 0 {
+			from = words[i-1].beat
+		}
+		if j < len(words) {
+			to = words[j].beat
+		}
+		// Words after a timed one share its slot; leading words start on the downbeat
+		slots, offset := j-i, 0
+		if i > 0 {
+			slots, offset = j-i+1, 1
+		}
+		for k := i; k < j; k++ {
+			words[k].beat = from + (to-from)*float64(k-i+offset)/float64(slots)
+		}
+		i = j
+	}
+
+	syllables := make([]LyricSyllable, 0, len(words))
+	var text strings.Builder
+	for i, w := range words {
+		s := w.text
+		joined := strings.HasSuffix(s, "-") && len(s) > 1
+		if joined {
+			s = strings.TrimSuffix(s, "-")
+		} else if i < len(words)-1 {
+			s += " "
+		}
+		text.WriteString(s)
+		syllables = append(syllables, LyricSyllable{Text: s, Beat: w.beat})
+	}
+	return text.String(), syllables
+}
+
+// CurrentSyllable returns the index of the syllable being sung at beat (beats from
+// the start of the bar), or -1 before the first
+func CurrentSyllable(syllables []LyricSyllable, beat float64) int {
+	current := -1
+	for i, s := range syllables {
+		if s.Beat <= beat {
+			current = i
+		}
+	}
+	return current
+}
@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BarTempo is a tempo that applies from the start of a bar (0-based) on
+type BarTempo struct {
+	Bar int
+	BPM float64
+}
+
+// tempoChangePattern matches one tempo_change entry, e.g. "bar 16 = 90"
+var tempoChangePattern = regexp.MustCompile(`(?i)bar\s*(\d+)\s*=\s*(\d+(?:\.\d+)?)`)
+
+// ParseTempoChanges parses tempo_change entries ("bar 16 = 90, bar 24 = 120").
+// Bars are 1-based as displayed; the returned bars are 0-based.
+func ParseTempoChanges(value string) ([]BarTempo, error) {
+	var changes []BarTempo
+	for _, match := range tempoChangePattern.FindAllStringSubmatch(value, -1) {
+		bar, _ := strconv.Atoi(match[1])
+		bpm, _ := strconv.ParseFloat(match[2], 64)
+		if bar < 1 || bpm <= 0 {
+			return nil, fmt.Errorf("invalid tempo change %q (bars start at 1 and the tempo must be positive)", match[0])
+		}
+		changes = append(changes, BarTempo{Bar: bar - 1, BPM: bpm})
+	}
+	if rest := strings.Trim(tempoChangePattern.ReplaceAllString(value, ""), " ,;"); rest != "" {
+		return nil, fmt.Errorf("invalid tempo change %q (use e.g. \"bar 16 = 90\")", rest)
+	}
+	return changes, nil
+}
+
+// GetTempoChanges returns the song's tempo changes in bar order: each section with
+// a tempo switches to it at its start (and back to the track tempo after it), then
+// tempo_change entries apply until the next change. Invalid entries are ignored.
+func (t *Track) GetTempoChanges() []BarTempo {
+	sectionTempos := make(map[string]int)
+	for _, section := range t.Sections {
+		if section.Tempo > 0 {
+			sectionTempos[section.Name] = section.Tempo
+		}
+	}
+
+	var changes []BarTempo
+	if len(sectionTempos) > 0 {
+		previousHadTempo := false
+		for _, info := range t.Progression.GetSections() {
+			bpm, ok := sectionTempos[info.Name]
+			if ok {
+				changes = append(changes, BarTempo{Bar: info.StartBar, BPM: float64(bpm)})
+			} else if previousHadTempo {
+				changes = append(changes, BarTempo{Bar: info.StartBar, BPM: float64(t.Info.Tempo)})
+			}
+			previousHadTempo = ok
+		}
+	}
+
+	// tempo_change entries win over a section tempo on the same bar
+	explicit, _ := ParseTempoChanges(string(t.Info.TempoChange))
+	changes = append(changes, explicit...)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Bar < changes[j].Bar
+	})
+	return changes
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TicksPerWholeNote is the MIDI resolution of a whole note (480 ticks per quarter)
+const TicksPerWholeNote = 1920
+
+// TimeSignature is a parsed time signature such as 4/4, 3/4 or 6/8
+type TimeSignature struct {
+	Beats    int // Numerator: beats per bar
+	BeatUnit int // Denominator: note value of one beat (4 = quarter, 8 = eighth)
+}
+
+// CommonTime is the default 4/4 time signature
+var CommonTime = TimeSignature{Beats: 4, BeatUnit: 4}
+
+// ParseTimeSignature parses "N/D" (e.g. "3/4", "6/8"), defaulting to 4/4
+// for empty or invalid values
+func ParseTimeSignature(s string) TimeSignature {
+	parts := strings.Split(strings.TrimSpace(s), "/")
+	if len(parts) != 2 {
+		return CommonTime
+	}
+	beats, err1 := strconv.Atoi(strings.TrimSpace(parts[0]))
+	unit, err2 := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err1 != nil || err2 != nil || beats <= 0 {
+		return CommonTime
+	}
+	switch unit {
+	case 2, 4, 8, 16:
+		return TimeSignature{Beats: beats, BeatUnit: unit}
+	default:
+		return CommonTime
+	}
+}
+
+// TicksPerBeat returns the length of one beat in ticks
+func (ts TimeSignature) TicksPerBeat() uint32 {
+	return uint32(TicksPerWholeNote / ts.BeatUnit)
+}
+
+// TicksPerBar returns the length of one bar in ticks
+func (ts TimeSignature) TicksPerBar() uint32 {
+	return uint32(ts.Beats) * ts.TicksPerBeat()
+}
+
+// IsCommonTime reports whether the signature is 4/4
+func (ts TimeSignature) IsCommonTime() bool {
+	return ts == CommonTime
+}
+
+// IsCompound reports whether beats group in threes (6/8, 9/8, 12/8)
+func (ts TimeSignature) IsCompound() bool {
+	return ts.BeatUnit == 8 && ts.Beats%3 == 0 && ts.Beats > 3
+}
+
+// String returns the signature as "N/D"
+func (ts TimeSignature) String() string {
+	return fmt.Sprintf("%d/%d", ts.Beats, ts.BeatUnit)
+}
+
+// GetTimeSignature returns the track's parsed time signature (default 4/4)
+func (t *Track) GetTimeSignature() TimeSignature {
+	return ParseTimeSignature(t.Info.TimeSignature)
+}
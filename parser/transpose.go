@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"backing-tracks/theory"
+)
+
+// Transpose shifts the track's key and every chord by semitones (added to
+// track.transpose), so all generated parts and chord symbols follow
+func (t *Track) Transpose(semitones int) {
+	t.Info.Transpose += semitones
+	t.transposeChords(semitones)
+}
+
+// TransposeToKey transposes the track by the shortest shift to the given key
+// (e.g. "G", "Gb"), keeping its major or minor mode. Chords are spelled to suit
+// the key as written, so Gb gives flats where F# gives sharps.
+func (t *Track) TransposeToKey(key string) error {
+	name, ok := theory.ParseKeyName(key)
+	if !ok {
+		return fmt.Errorf("unknown key %q", key)
+	}
+	name = strings.TrimSuffix(name, "m")
+	if _, isMinor := theory.ParseKey(t.Info.Key); isMinor {
+		name += "m"
+	}
+	if name == t.Info.Key {
+		return nil
+	}
+
+	semitones := theory.SemitonesToKey(t.Info.Key, name)
+	t.Info.Transpose += semitones
+	t.transposeChordsToKey(semitones, name)
+	return nil
+}
+
+// transposeChords rewrites the key, progressions and pad pattern, spelling
+// chords with flats or sharps to suit the new key
+func (t *Track) transposeChords(semitones int) {
+	if semitones%12 == 0 {
+		return
+	}
+	t.transposeChordsToKey(semitones, theory.TransposeKey(t.Info.Key, semitones))
+}
+
+// transposeChordsToKey does the work of transposeChords with the new key's spelling given
+func (t *Track) transposeChordsToKey(semitones int, key string) {
+	t.Info.Key = key
+
+	t.Progression.Pattern = StringOrList(transposePattern(string(t.Progression.Pattern), semitones, key))
+	for i := range t.Sections {
+		pattern := string(t.Sections[i].Progression.Pattern)
+		t.Sections[i].Progression.Pattern = StringOrList(transposePattern(pattern, semitones, key))
+	}
+	if t.Pad != nil {
+		t.Pad.Pattern = transposePattern(t.Pad.Pattern, semitones, key)
+	}
+}
+
+// transposePattern transposes each chord in a pattern, keeping section markers,
+// bar lines and *duration suffixes
+func transposePattern(pattern string, semitones int, key string) string {
+	parts := strings.Fields(pattern)
+	for i, part := range parts {
+		if part == "|" || (strings.HasPrefix(part, "[") && strings.HasSuffix(part, "]")) {
+			continue
+		}
+		symbol, duration, hasDuration := strings.Cut(part, "*")
+		symbol = theory.TransposeChordInKey(symbol, semitones, key)
+		if hasDuration {
+			symbol += "*" + duration
+		}
+		parts[i] = symbol
+	}
+	return strings.Join(parts, " ")
+}
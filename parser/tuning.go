@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"backing-tracks/theory"
+)
+
+// CustomTuningName is the tuning name a track's custom_tuning is registered under
+const CustomTuningName = "custom"
+
+// lowestStringBase is the MIDI note of C2; the lowest string is placed in the C2-B2 octave
+const lowestStringBase = 36
+
+// ParseTuning converts a comma-separated list of note names, low string first
+// (e.g. "C,G,C,G,C,D"), into a tuning. Each string is pitched at the first
+// matching note above the previous string, starting from the C2-B2 octave.
+func ParseTuning(s string) (theory.Tuning, error) {
+	if strings.TrimSpace(s) == "" {
+		return theory.Tuning{}, fmt.Errorf("custom tuning is empty")
+	}
+	tokens := strings.Split(s, ",")
+
+	var tuning theory.Tuning
+	prev := -1
+	for i, token := range tokens {
+		name := strings.TrimSpace(token)
+		if !isNoteName(name) {
+			return theory.Tuning{}, fmt.Errorf("invalid note %q for string %d in custom tuning %q", name, i+1, s)
+		}
+		name = strings.ToUpper(name[:1]) + name[1:]
+
+		note := lowestStringBase + theory.NoteToMidi(name)
+		for note <= prev {
+			note += 12
+		}
+		prev = note
+
+		tuning.Notes = append(tuning.Notes, note)
+		tuning.Names = append(tuning.Names, name)
+	}
+
+	// Lowercase the high string like the built-in tunings
+	last := len(tuning.Names) - 1
+	tuning.Names[last] = strings.ToLower(tuning.Names[last])
+
+	return tuning, nil
+}
+
+// isNoteName reports whether s is a note letter A-G with an optional # or b
+func isNoteName(s string) bool {
+	if len(s) == 0 || len(s) > 2 {
+		return false
+	}
+	if !strings.ContainsRune("ABCDEFGabcdefg", rune(s[0])) {
+		return false
+	}
+	return len(s) == 1 || s[1] == '#' || s[1] == 'b'
+}
+
+// TuningName returns the tuning name to use for the track: the registered
+// custom tuning if custom_tuning is set, otherwise track.tuning (default standard)
+func (t *Track) TuningName() string {
+	if t.Info.CustomTuning != "" {
+		return CustomTuningName
+	}
+	if t.Info.Tuning == "" {
+		return "standard"
+	}
+	return t.Info.Tuning
+}
@@ -2,6 +2,7 @@ package player
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -15,11 +16,120 @@ import (
 	"golang.org/x/term"
 )
 
+// PlayOptions are command-line settings for real-time playback
+type PlayOptions struct {
+	FromBar  int  // First bar to play (0-based)
+	ToBar    int  // Bar to stop before (0 = end of the song)
+	Loop     bool // Loop until stopped instead of ending
+	LoopBars int  // Bars to loop from the first bar (0 = the whole song or range)
+
+	MetronomeOnly bool // Play clicks on every beat instead of the instruments
+	MaxVoices     int  // Polyphony limit; extra notes steal the oldest (0 = unlimited)
+
+	TrainerStep int // Speed trainer: BPM added after each loop pass (0 = off)
+	TrainerMax  int // Speed trainer: tempo to stop at (0 = no limit)
+
+	AudioDriver string // FluidSynth audio driver ("" = DefaultAudioDriver)
+	NoTUI       bool   // Print plain status lines instead of the full-screen TUI
+	Countdown   bool   // Show a one-bar visual countdown before the TUI starts
+
+	Log *PracticeLog // Practice stats to write when playback stops (nil = none)
+}
+
+// ErrFluidSynthMissing is returned when the fluidsynth binary is not on PATH
+var ErrFluidSynthMissing = errors.New("fluidsynth not found on PATH. Install it with:\n" +
+	"  sudo apt install fluidsynth     (Debian/Ubuntu)\n" +
+	"  sudo dnf install fluidsynth     (Fedora)\n" +
+	"  brew install fluid-synth        (macOS)")
+
+// ErrNoSoundFont is returned when no SoundFont was given and none was found
+var ErrNoSoundFont = errors.New("no SoundFont (.sf2) file found")
+
+// CheckFluidSynth reports ErrFluidSynthMissing if fluidsynth can't be run, so
+// callers can fail before starting the TUI
+func CheckFluidSynth() error {
+	if _, err := exec.LookPath("fluidsynth"); err != nil {
+		return ErrFluidSynthMissing
+	}
+	return nil
+}
+
+// DefaultAudioDriver is the FluidSynth audio driver used unless another is chosen
+const DefaultAudioDriver = "pulseaudio"
+
+// AudioDrivers lists the FluidSynth audio drivers that can be chosen
+var AudioDrivers = []string{
+	"pulseaudio", "pipewire", "alsa", "jack", "oss", "sdl2", "portaudio",
+	"coreaudio", "dsound", "wasapi", "waveout",
+}
+
+// IsAudioDriver reports whether name is one of AudioDrivers
+func IsAudioDriver(name string) bool {
+	for _, driver := range AudioDrivers {
+		if name == driver {
+			return true
+		}
+	}
+	return false
+}
+
+// audioDriver returns the chosen audio driver or the default
+func (o PlayOptions) audioDriver() string {
+	if o.AudioDriver == "" {
+		return DefaultAudioDriver
+	}
+	return o.AudioDriver
+}
+
+// start sets the bar range, begins playback and engages the preset loop
+func (o PlayOptions) start(player *RealtimePlayer) {
+	if o.MetronomeOnly {
+		player.SetMetronomeOnly(true)
+	}
+	if o.MaxVoices > 0 {
+		player.SetMaxVoices(o.MaxVoices)
+	}
+	if o.FromBar > 0 || o.ToBar > 0 {
+		player.SetRange(o.FromBar, o.ToBar)
+	}
+	if o.TrainerStep > 0 {
+		player.SetTrainer(o.TrainerStep, o.TrainerMax)
+	}
+	if o.Log != nil {
+		player.SetPracticeLog(o.Log)
+	}
+
+	loopBars := o.LoopBars
+	if loopBars <= 0 {
+		loopBars = player.playbackData.TotalBars - player.playbackData.StartBar
+	}
+
+	player.Start()
+	if o.Loop {
+		player.SetLoop(loopBars) // Playback is at the first bar (or in its count-in)
+	}
+}
+
+// startTUI starts playback for the TUI, after its visual countdown when one is
+// wanted. An audible count-in already delays the song, so the countdown runs over
+// the clicks instead of adding another bar.
+func (o PlayOptions) startTUI(player *RealtimePlayer, model *display.TUIModel, track *parser.Track) {
+	switch {
+	case !o.Countdown:
+		o.start(player)
+	case track.Info.CountIn > 0:
+		o.start(player)
+		model.SetCountdown(track.Info.CountIn, nil)
+	default:
+		model.SetCountdown(1, func() { o.start(player) })
+	}
+}
+
 // PlayMIDIWithDisplay plays a MIDI file using FluidSynth with live TUI display
-func PlayMIDIWithDisplay(midiFile string, track *parser.Track, customSoundFont string) error {
+func PlayMIDIWithDisplay(midiFile string, track *parser.Track, customSoundFont string, opts PlayOptions) error {
 	// Check if FluidSynth is installed
-	if _, err := exec.LookPath("fluidsynth"); err != nil {
-		return fmt.Errorf("fluidsynth not found: please install with 'sudo apt install fluidsynth'")
+	if err := CheckFluidSynth(); err != nil {
+		return err
 	}
 
 	// Find a SoundFont file
@@ -31,17 +141,31 @@ func PlayMIDIWithDisplay(midiFile string, track *parser.Track, customSoundFont s
 	fmt.Printf("Using SoundFont: %s\n", soundFont)
 	fmt.Println()
 
+	// Plain status lines when asked for or when the output isn't a terminal (logs, pipes)
+	if opts.NoTUI || !term.IsTerminal(int(os.Stdout.Fd())) {
+		player, err := NewRealtimePlayer(track, soundFont, opts.audioDriver())
+		if err != nil {
+			fmt.Printf("Real-time playback unavailable (%v), using file-based playback...\n", err)
+			return playWithLegacyDisplay(midiFile, track, soundFont, opts.audioDriver(), opts.Loop)
+		}
+		defer player.Stop()
+
+		opts.start(player)
+		playWithStatusLines(player, track)
+		return nil
+	}
+
 	// Check if we have a TTY - if not, use legacy display
 	if !term.IsTerminal(int(os.Stdin.Fd())) {
-		return playWithLegacyDisplay(midiFile, track, soundFont)
+		return playWithLegacyDisplay(midiFile, track, soundFont, opts.audioDriver(), opts.Loop)
 	}
 
 	// Create real-time player
-	player, err := NewRealtimePlayer(track, soundFont)
+	player, err := NewRealtimePlayer(track, soundFont, opts.audioDriver())
 	if err != nil {
 		// Fall back to file-based playback if real-time fails
-		fmt.Println("Real-time playback unavailable, using file-based playback...")
-		return playWithFileBasedTUI(midiFile, track, soundFont)
+		fmt.Printf("Real-time playback unavailable (%v), using file-based playback...\n", err)
+		return playWithFileBasedTUI(midiFile, track, soundFont, opts.audioDriver())
 	}
 	defer player.Stop()
 
@@ -49,8 +173,8 @@ func PlayMIDIWithDisplay(midiFile string, track *parser.Track, customSoundFont s
 	tuiModel := display.NewTUIModel(track)
 	tuiModel.SetPlayer(player)
 
-	// Start playback
-	player.Start()
+	// Start playback (after the countdown, if any)
+	opts.startTUI(player, tuiModel, track)
 
 	// Run the TUI
 	p := tea.NewProgram(tuiModel, tea.WithAltScreen())
@@ -62,7 +186,7 @@ func PlayMIDIWithDisplay(midiFile string, track *parser.Track, customSoundFont s
 }
 
 // playWithFileBasedTUI is the fallback when real-time playback isn't available
-func playWithFileBasedTUI(midiFile string, track *parser.Track, soundFont string) error {
+func playWithFileBasedTUI(midiFile string, track *parser.Track, soundFont, audioDriver string) error {
 	// Create TUI model
 	tuiModel := display.NewTUIModel(track)
 
@@ -75,10 +199,11 @@ func playWithFileBasedTUI(midiFile string, track *parser.Track, soundFont string
 
 	// Build FluidSynth command with context
 	cmd := exec.CommandContext(ctx, "fluidsynth",
-		"-ni",         // No interactive mode
+		"-ni", // No interactive mode
+		"-a", audioDriver,
 		"-q",          // Quiet mode
 		"-r", "48000", // Sample rate
-		"-g", "1.0",   // Gain
+		"-g", "1.0", // Gain
 		soundFont,
 		midiFile,
 	)
@@ -128,8 +253,21 @@ func playWithFileBasedTUI(midiFile string, track *parser.Track, soundFont string
 	return nil
 }
 
-// playWithLegacyDisplay uses the old ANSI-based display (for non-TTY environments)
-func playWithLegacyDisplay(midiFile string, track *parser.Track, soundFont string) error {
+// playWithLegacyDisplay uses the old ANSI-based display (for non-TTY environments).
+// With loop set the file is played again each time it ends, until interrupted.
+func playWithLegacyDisplay(midiFile string, track *parser.Track, soundFont, audioDriver string, loop bool) error {
+	for {
+		if err := playFileWithLegacyDisplay(midiFile, track, soundFont, audioDriver); err != nil {
+			return err
+		}
+		if !loop {
+			return nil
+		}
+	}
+}
+
+// playFileWithLegacyDisplay plays the MIDI file once with the legacy live display
+func playFileWithLegacyDisplay(midiFile string, track *parser.Track, soundFont, audioDriver string) error {
 	// Create and start legacy live display
 	liveDisplay := display.NewLiveDisplay(track)
 	liveDisplay.Start()
@@ -137,10 +275,11 @@ func playWithLegacyDisplay(midiFile string, track *parser.Track, soundFont strin
 
 	// Build FluidSynth command
 	cmd := exec.Command("fluidsynth",
-		"-ni",         // No interactive mode
+		"-ni", // No interactive mode
+		"-a", audioDriver,
 		"-q",          // Quiet mode
 		"-r", "48000", // Sample rate
-		"-g", "1.0",   // Gain
+		"-g", "1.0", // Gain
 		soundFont,
 		midiFile,
 	)
@@ -160,8 +299,8 @@ func playWithLegacyDisplay(midiFile string, track *parser.Track, soundFont strin
 // PlayMIDI plays a MIDI file using FluidSynth (legacy without display)
 func PlayMIDI(midiFile string) error {
 	// Check if FluidSynth is installed
-	if _, err := exec.LookPath("fluidsynth"); err != nil {
-		return fmt.Errorf("fluidsynth not found: please install with 'sudo apt install fluidsynth'")
+	if err := CheckFluidSynth(); err != nil {
+		return err
 	}
 
 	// Find a SoundFont file
@@ -177,9 +316,9 @@ func PlayMIDI(midiFile string) error {
 	// -r 48000: sample rate
 	// -g 1.0: gain
 	cmd := exec.Command("fluidsynth",
-		"-ni",           // No interactive mode
-		"-r", "48000",   // Sample rate
-		"-g", "1.0",     // Gain
+		"-ni",         // No interactive mode
+		"-r", "48000", // Sample rate
+		"-g", "1.0", // Gain
 		soundFont,
 		midiFile,
 	)
@@ -196,6 +335,46 @@ func PlayMIDI(midiFile string) error {
 	return nil
 }
 
+// RenderWAV renders a MIDI file to a WAV file with FluidSynth (no audio output)
+func RenderWAV(midiFile, outputPath, customSoundFont string) error {
+	// Check if FluidSynth is installed
+	if err := CheckFluidSynth(); err != nil {
+		return err
+	}
+
+	// Find a SoundFont file
+	soundFont, err := findSoundFont(customSoundFont)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Using SoundFont: %s\n", soundFont)
+
+	// Build FluidSynth command
+	cmd := exec.Command("fluidsynth",
+		"-ni",            // No interactive mode
+		"-F", outputPath, // Render to file instead of the audio driver
+		"-r", "48000", // Sample rate
+		"-g", "1.0", // Gain
+		soundFont,
+		midiFile,
+	)
+
+	// Keep FluidSynth errors visible
+	cmd.Stdout = io.Discard
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("fluidsynth exited with code %d", exitErr.ExitCode())
+		}
+		return fmt.Errorf("fluidsynth error: %w", err)
+	}
+
+	return nil
+}
+
 // ListSoundFonts returns all available soundfonts on the system
 func ListSoundFonts() []string {
 	var found []string
@@ -330,8 +509,5 @@ func findSoundFont(customPath string) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("no SoundFont (.sf2) file found. Please install fluid-soundfont-gm:\n" +
-		"  sudo apt install fluid-soundfont-gm\n\n" +
-		"Or place custom .sf2 files in ./soundfonts/ directory\n" +
-		"Or specify with --soundfont flag")
+	return "", ErrNoSoundFont
 }
@@ -0,0 +1,166 @@
+package player
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// A practice log (--log) records a playing session as JSON: when it started and
+// ended, the file, and how long each bar range was looped and each tempo played.
+// The player reports every loop, tempo and pause change; the log is written when
+// the player stops.
+
+// PracticeLog accumulates the stats of one session. Watch mode shares one log
+// between the players it starts, so the file covers the whole session.
+type PracticeLog struct {
+	path string
+
+	mu      sync.Mutex
+	session practiceSession
+	state   practiceState // What is being played
+	since   time.Time     // When state began (zero while not playing)
+}
+
+// practiceState is what the player is doing, for timing
+type practiceState struct {
+	looping          bool
+	startBar, endBar int // Loop bars, 0-based, end exclusive
+	tempo            int // Effective BPM
+}
+
+// practiceSession is the log file's content
+type practiceSession struct {
+	File    string          `json:"file,omitempty"`
+	Title   string          `json:"title"`
+	Started time.Time       `json:"started"`
+	Ended   time.Time       `json:"ended"`
+	Seconds float64         `json:"seconds"` // Time spent playing (not paused)
+	Loops   []loopPractice  `json:"loops"`
+	Tempos  []tempoPractice `json:"tempos"`
+}
+
+// loopPractice is the time one bar range was looped at one tempo
+type loopPractice struct {
+	StartBar int     `json:"start_bar"` // First bar, from 1
+	EndBar   int     `json:"end_bar"`   // Last bar, inclusive
+	Tempo    int     `json:"tempo"`
+	Times    int     `json:"times"` // Times the loop was started at this tempo
+	Seconds  float64 `json:"seconds"`
+}
+
+// tempoPractice is the time played at one tempo, looping or not
+type tempoPractice struct {
+	Tempo   int     `json:"tempo"`
+	Seconds float64 `json:"seconds"`
+}
+
+// NewPracticeLog starts a session log that will be written to path
+func NewPracticeLog(path, file, title string) *PracticeLog {
+	return &PracticeLog{
+		path:    path,
+		session: practiceSession{File: file, Title: title, Started: time.Now()},
+	}
+}
+
+// record adds the time since the last change to the previous state and starts
+// timing state (not while paused). A nil log records nothing.
+func (l *PracticeLog) record(state practiceState, playing bool) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if !l.since.IsZero() {
+		seconds := now.Sub(l.since).Seconds()
+		l.session.Seconds += seconds
+		l.tempo(l.state.tempo).Seconds += seconds
+		if l.state.looping {
+			l.loop(l.state).Seconds += seconds
+		}
+	}
+
+	if state.looping && (!l.state.looping || l.state.startBar != state.startBar || l.state.endBar != state.endBar) {
+		l.loop(state).Times++
+	}
+	l.state = state
+	l.since = time.Time{}
+	if playing {
+		l.since = now
+	}
+}
+
+// loop returns the stats of state's loop at its tempo, adding them if new
+func (l *PracticeLog) loop(state practiceState) *loopPractice {
+	for i, loop := range l.session.Loops {
+		if loop.StartBar == state.startBar+1 && loop.EndBar == state.endBar && loop.Tempo == state.tempo {
+			return &l.session.Loops[i]
+		}
+	}
+	l.session.Loops = append(l.session.Loops, loopPractice{StartBar: state.startBar + 1, EndBar: state.endBar, Tempo: state.tempo})
+	return &l.session.Loops[len(l.session.Loops)-1]
+}
+
+// tempo returns the stats of a tempo, adding them if new
+func (l *PracticeLog) tempo(bpm int) *tempoPractice {
+	for i, tempo := range l.session.Tempos {
+		if tempo.Tempo == bpm {
+			return &l.session.Tempos[i]
+		}
+	}
+	l.session.Tempos = append(l.session.Tempos, tempoPractice{Tempo: bpm})
+	return &l.session.Tempos[len(l.session.Tempos)-1]
+}
+
+// roundSeconds rounds to a tenth of a second
+func roundSeconds(seconds float64) float64 {
+	return math.Round(seconds*10) / 10
+}
+
+// write saves the session so far to the log file, loops in bar order and tempos
+// slowest first. A nil log writes nothing.
+func (l *PracticeLog) write() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	session := l.session
+	session.Ended = time.Now()
+	session.Loops = append([]loopPractice{}, l.session.Loops...)
+	sort.SliceStable(session.Loops, func(i, j int) bool {
+		a, b := session.Loops[i], session.Loops[j]
+		if a.StartBar != b.StartBar {
+			return a.StartBar < b.StartBar
+		}
+		if a.EndBar != b.EndBar {
+			return a.EndBar < b.EndBar
+		}
+		return a.Tempo < b.Tempo
+	})
+	session.Tempos = append([]tempoPractice{}, l.session.Tempos...)
+	sort.Slice(session.Tempos, func(i, j int) bool {
+		return session.Tempos[i].Tempo < session.Tempos[j].Tempo
+	})
+
+	// Tenths of a second are plenty
+	session.Seconds = roundSeconds(session.Seconds)
+	for i := range session.Loops {
+		session.Loops[i].Seconds = roundSeconds(session.Loops[i].Seconds)
+	}
+	for i := range session.Tempos {
+		session.Tempos[i].Seconds = roundSeconds(session.Tempos[i].Seconds)
+	}
+
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(l.path, append(data, '\n'), 0o644)
+}
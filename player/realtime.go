@@ -2,10 +2,14 @@ package player
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"os/exec"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,6 +20,7 @@ import (
 // RealtimePlayer handles real-time MIDI playback with FluidSynth
 type RealtimePlayer struct {
 	cmd          *exec.Cmd
+	exited       chan error // Receives FluidSynth's exit status
 	stdin        io.WriteCloser
 	playbackData *midi.PlaybackData
 	track        *parser.Track
@@ -29,123 +34,78 @@ type RealtimePlayer struct {
 	pausedTotal     time.Duration
 	seekOffset      time.Duration
 	lastEventIdx    int
+	instrumentIdx   int              // Next section instrument change to play
+	countInIdx      int              // Next count-in click to play
 	activeNotes     map[noteKey]bool // Track active notes for cleanup
+	noteAges        []noteKey        // Active notes, oldest first (for voice stealing)
+	maxVoices       int              // Polyphony limit (0 = unlimited)
 	transposeOffset int              // Semitones to transpose
 	capoPosition    int              // Capo fret position (0 = no capo)
-	mutedTracks     [5]bool          // 0=drums, 1=bass, 2=chords, 3=melody, 4=fingerstyle
+	strumsPerBar    int              // Strum steps per bar of the chord part
+	mutedTracks     [6]bool          // 0=drums, 1=bass, 2=chords, 3=melody, 4=fingerstyle, 5=pad
+	soloedTracks    [6]bool          // If any track is soloed, only soloed tracks sound
+	trackVolumes    [6]int           // CC7 volume per track (0-127), same indices as mutedTracks
 
 	// Loop state
 	loopEnabled  bool // Whether loop is active
 	loopStartBar int  // First bar of loop (inclusive)
 	loopEndBar   int  // Last bar of loop (exclusive)
 	loopLength   int  // Number of bars in loop (1-9)
+	loopPasses   int  // Passes left, counting the current one (0 = loop forever)
+
+	// Speed trainer: each pass of the loop raises the tempo by trainerStep up to trainerMax
+	trainerStep int // BPM added per loop pass (0 = off)
+	trainerMax  int // Target tempo the trainer stops at (0 = no limit)
 
 	// Speed state
 	tempoOffset int // BPM offset from original tempo (e.g., +10 or -20)
 
+	// Bar range (set via SetRange, endBar 0 = to the end of the song)
+	rangeStartBar int
+	rangeEndBar   int
+
+	// Play only metronome clicks instead of the instruments
+	metronomeOnly bool
+
 	// Fingerstyle pattern
 	fingerstylePattern midi.PatternType
 
+	// Swing of every part instead of the track's (0 = as written), see ToggleSwing
+	swing float64
+
+	// Practice stats for --log (nil = not logging)
+	log *PracticeLog
+
 	// Control channels
 	stopChan chan struct{}
 	stopOnce sync.Once
+	done     chan struct{} // Closed when the playback loop ends
 }
 
+// DefaultTrackVolume is the General MIDI default channel volume (CC7)
+const DefaultTrackVolume = 100
+
 type noteKey struct {
 	channel uint8
 	note    uint8
 }
 
-// GMInstruments maps friendly instrument names to General MIDI program numbers
-var GMInstruments = map[string]int{
-	// Pianos
-	"piano":          0,
-	"acoustic_piano": 0,
-	"bright_piano":   1,
-	"electric_piano": 4,
-	"honky_tonk":     3,
-	"harpsichord":    6,
-	"clavinet":       7,
-
-	// Guitars
-	"nylon_guitar":    24,
-	"steel_guitar":    25,
-	"jazz_guitar":     26,
-	"clean_guitar":    27,
-	"muted_guitar":    28,
-	"overdrive":       29,
-	"distortion":      30,
-	"harmonics":       31,
-
-	// Bass
-	"acoustic_bass":  32,
-	"fingered_bass":  33,
-	"picked_bass":    34,
-	"fretless_bass":  35,
-	"slap_bass":      36,
-	"synth_bass":     38,
-
-	// Strings
-	"violin":         40,
-	"viola":          41,
-	"cello":          42,
-	"contrabass":     43,
-	"strings":        48,
-	"slow_strings":   49,
-
-	// Brass
-	"trumpet":        56,
-	"trombone":       57,
-	"tuba":           58,
-	"french_horn":    60,
-	"brass":          61,
-	"synth_brass":    62,
-
-	// Woodwinds
-	"soprano_sax":    64,
-	"alto_sax":       65,
-	"tenor_sax":      66,
-	"baritone_sax":   67,
-	"oboe":           68,
-	"clarinet":       71,
-	"flute":          73,
-	"pan_flute":      75,
-
-	// Synth
-	"synth_lead":     80,
-	"synth_pad":      88,
-
-	// Organ
-	"organ":          16,
-	"church_organ":   19,
-	"reed_organ":     20,
-	"accordion":      21,
-	"harmonica":      22,
-	"bandoneon":      23,
-}
-
-// getGMProgram returns the GM program number for an instrument name
-func getGMProgram(name string, defaultProg int) int {
-	if name == "" {
-		return defaultProg
-	}
-	if prog, ok := GMInstruments[name]; ok {
-		return prog
-	}
-	return defaultProg
-}
-
-// NewRealtimePlayer creates a new real-time player
-func NewRealtimePlayer(track *parser.Track, soundFont string) (*RealtimePlayer, error) {
+// NewRealtimePlayer creates a new real-time player that plays through the given
+// FluidSynth audio driver (e.g. pulseaudio, alsa, jack)
+func NewRealtimePlayer(track *parser.Track, soundFont, audioDriver string) (*RealtimePlayer, error) {
+	if err := CheckFluidSynth(); err != nil {
+		return nil, err
+	}
+
 	// Generate playback data
 	playbackData := midi.GeneratePlaybackData(track)
 
 	// Start FluidSynth in interactive mode
 	cmd := exec.Command("fluidsynth",
-		"-a", "pulseaudio", // or "alsa"
-		"-q",               // Quiet mode
-		"-s",               // Start as server (interactive)
-		"-g", "1.0",        // Gain
+		"-a", audioDriver, // Audio driver
+		"-q",        // Quiet mode
+		"-s",        // Start as server (interactive)
+		"-g", "1.0", // Gain
 		soundFont,
 	)
 
@@ -154,16 +114,29 @@ func NewRealtimePlayer(track *parser.Track, soundFont string) (*RealtimePlayer,
 		return nil, fmt.Errorf("failed to get stdin pipe: %w", err)
 	}
 
-	// Discard stdout/stderr
+	// Discard stdout; keep stderr to explain an early exit (e.g. an unusable audio driver)
+	var stderr bytes.Buffer
 	cmd.Stdout = io.Discard
-	cmd.Stderr = io.Discard
+	cmd.Stderr = &stderr
 
 	if err := cmd.Start(); err != nil {
 		return nil, fmt.Errorf("failed to start fluidsynth: %w", err)
 	}
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
 
 	// Give FluidSynth a moment to initialize
-	time.Sleep(200 * time.Millisecond)
+	select {
+	case <-exited:
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = "no error output"
+		}
+		return nil, fmt.Errorf("fluidsynth exited with audio driver %q: %s", audioDriver, msg)
+	case <-time.After(200 * time.Millisecond):
+	}
 
 	// Set up instruments
 	player := &RealtimePlayer{
@@ -171,29 +144,24 @@ func NewRealtimePlayer(track *parser.Track, soundFont string) (*RealtimePlayer,
 		stdin:        stdin,
 		playbackData: playbackData,
 		track:        track,
+		exited:       exited,
 		activeNotes:  make(map[noteKey]bool),
-		capoPosition: track.Info.Capo, // Initialize from track
+		capoPosition: max(0, min(12, track.Info.Capo)), // Initialize from track (or --capo), clamped like SetCapo
+		strumsPerBar: len(midi.StrumSteps(track.Rhythm, track.GetTimeSignature())),
 		stopChan:     make(chan struct{}),
+		done:         make(chan struct{}),
 	}
-
-	// Set program changes for each channel based on track settings
-	chordsInstrument := ""
-	if track.Rhythm != nil {
-		chordsInstrument = track.Rhythm.Instrument
-	}
-	bassInstrument := ""
-	if track.Bass != nil {
-		bassInstrument = track.Bass.Instrument
-	}
-	melodyInstrument := ""
-	if track.Melody != nil {
-		melodyInstrument = track.Melody.Instrument
+	for i := range player.trackVolumes {
+		player.trackVolumes[i] = DefaultTrackVolume
 	}
 
-	player.sendCommand(fmt.Sprintf("prog 0 %d", getGMProgram(chordsInstrument, 0)))  // Chords (default: piano)
-	player.sendCommand(fmt.Sprintf("prog 1 %d", getGMProgram(bassInstrument, 33)))   // Bass (default: fingered bass)
-	player.sendCommand(fmt.Sprintf("prog 2 %d", getGMProgram(melodyInstrument, 25))) // Melody (default: steel guitar)
-	player.sendCommand(fmt.Sprintf("prog 3 %d", 24))                                  // Fingerstyle (nylon guitar)
+	// Set program changes for each channel based on track settings (the same as an export)
+	for _, channel := range []uint8{0, 1, 2, 3, midi.PadChannel, 9} {
+		player.sendCommand(fmt.Sprintf("prog %d %d", channel, midi.ChannelProgram(track, channel)))
+	}
+	player.sendTrackVolumes()
+	player.SetReverb(track.ReverbLevel())
+	player.SetChorus(track.ChorusLevel())
 
 	return player, nil
 }
@@ -204,6 +172,41 @@ func (p *RealtimePlayer) sendCommand(cmd string) error {
 	return err
 }
 
+// SetRange limits playback to bars [startBar, endBar) (0-based, endBar 0 = to the end).
+// Bar numbers stay those of the full song. Call before Start.
+func (p *RealtimePlayer) SetRange(startBar, endBar int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.rangeStartBar, p.rangeEndBar = startBar, endBar
+	p.playbackData = p.generatePlaybackData()
+}
+
+// SetMetronomeOnly replaces the instruments with a click on every beat. Call before Start.
+func (p *RealtimePlayer) SetMetronomeOnly(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.metronomeOnly = enabled
+	p.playbackData = p.generatePlaybackData()
+}
+
+// generatePlaybackData builds the playback data for the current pattern, swing,
+// range and metronome setting (must be called with lock held)
+func (p *RealtimePlayer) generatePlaybackData() *midi.PlaybackData {
+	track := p.track
+	if p.swing > 0 {
+		track = track.WithSwing(p.swing)
+	}
+	var data *midi.PlaybackData
+	if p.metronomeOnly {
+		data = midi.GenerateMetronomeData(track)
+	} else {
+		data = midi.GeneratePlaybackDataWithPattern(track, p.fingerstylePattern)
+	}
+	return data.Slice(p.rangeStartBar, p.rangeEndBar)
+}
+
 // Start begins playback
 func (p *RealtimePlayer) Start() {
 	p.mu.Lock()
@@ -211,8 +214,12 @@ func (p *RealtimePlayer) Start() {
 	p.paused = false
 	p.startTime = time.Now()
 	p.pausedTotal = 0
-	p.seekOffset = 0
+	// Start before the first bar so the count-in clicks play first
+	p.seekOffset = p.playbackData.StartTime() - p.playbackData.CountInTime()
 	p.lastEventIdx = 0
+	p.instrumentIdx = 0
+	p.countInIdx = 0
+	p.logPractice()
 	p.mu.Unlock()
 
 	go p.playbackLoop()
@@ -220,6 +227,7 @@ func (p *RealtimePlayer) Start() {
 
 // playbackLoop is the main playback goroutine
 func (p *RealtimePlayer) playbackLoop() {
+	defer close(p.done)
 	ticker := time.NewTicker(5 * time.Millisecond) // Check every 5ms for precise timing
 	defer ticker.Stop()
 
@@ -235,6 +243,13 @@ func (p *RealtimePlayer) playbackLoop() {
 				continue
 			}
 
+			// Play count-in clicks while still before the first bar
+			if raw := time.Since(p.startTime) - p.pausedTotal + p.seekOffset - p.playbackData.StartTime(); raw < 0 {
+				p.playCountIn(raw)
+				p.mu.Unlock()
+				continue
+			}
+
 			// Calculate current tick position (speed-adjusted)
 			elapsed := p.getSpeedAdjustedElapsed()
 			currentTick := p.playbackData.TimeToTick(elapsed)
@@ -243,21 +258,32 @@ func (p *RealtimePlayer) playbackLoop() {
 			if p.loopEnabled && p.loopEndBar > 0 {
 				loopEndTick := p.playbackData.BarToTick(p.loopEndBar)
 				if currentTick >= loopEndTick {
-					// Jump back to loop start
-					p.seekToBarInternal(p.loopStartBar)
-					p.mu.Unlock()
-					continue
+					if p.loopPasses == 1 {
+						// Last repeat done: drop the loop and carry on past its end
+						p.clearLoop()
+					} else {
+						if p.loopPasses > 1 {
+							p.loopPasses--
+						}
+						p.advanceTrainer()
+						// Jump back to loop start
+						p.seekToBarInternal(p.loopStartBar)
+						p.mu.Unlock()
+						continue
+					}
 				}
 			}
 
 			// Check if we've reached the end
 			if currentTick >= p.playbackData.TotalTicks {
+				p.log.record(p.practiceState(), false)
 				p.mu.Unlock()
 				p.allNotesOff()
 				return
 			}
 
-			// Play events up to current tick
+			// Play instrument changes and events up to current tick
+			p.playInstrumentChanges(currentTick)
 			for p.lastEventIdx < len(p.playbackData.Events) {
 				evt := p.playbackData.Events[p.lastEventIdx]
 				if evt.Tick > currentTick {
@@ -274,22 +300,8 @@ func (p *RealtimePlayer) playbackLoop() {
 
 // playEvent sends a single event to FluidSynth
 func (p *RealtimePlayer) playEvent(evt midi.PlaybackEvent) {
-	// Check if track is muted
-	// Channel mapping: 9=drums(0), 1=bass(1), 0=chords(2), 2=melody(3), 3=fingerstyle(4)
-	trackIdx := -1
-	switch evt.Channel {
-	case 9:
-		trackIdx = 0 // drums
-	case 1:
-		trackIdx = 1 // bass
-	case 0:
-		trackIdx = 2 // chords
-	case 2:
-		trackIdx = 3 // melody
-	case 3:
-		trackIdx = 4 // fingerstyle
-	}
-	if trackIdx >= 0 && p.mutedTracks[trackIdx] {
+	// Check if track is muted or silenced by another track's solo
+	if trackIdx := trackForChannel(evt.Channel); trackIdx >= 0 && p.isTrackSilenced(trackIdx) {
 		return // Skip muted track
 	}
 
@@ -311,11 +323,94 @@ func (p *RealtimePlayer) playEvent(evt midi.PlaybackEvent) {
 
 	key := noteKey{evt.Channel, note}
 	if evt.IsNoteOn {
-		p.sendCommand(fmt.Sprintf("noteon %d %d %d", evt.Channel, note, evt.Velocity))
-		p.activeNotes[key] = true
+		p.startNote(key, evt.Velocity)
 	} else {
-		p.sendCommand(fmt.Sprintf("noteoff %d %d", evt.Channel, note))
-		delete(p.activeNotes, key)
+		p.stopNote(key)
+	}
+}
+
+// trackForChannel maps a MIDI channel to a track index, or -1 if unmapped
+// Channel mapping: 9=drums(0), 1=bass(1), 0=chords(2), 2=melody(3), 3=fingerstyle(4), 4=pad(5)
+func trackForChannel(channel uint8) int {
+	switch channel {
+	case 9:
+		return 0 // drums
+	case 1:
+		return 1 // bass
+	case 0:
+		return 2 // chords
+	case 2:
+		return 3 // melody
+	case 3:
+		return 4 // fingerstyle
+	case midi.PadChannel:
+		return 5 // pad
+	}
+	return -1
+}
+
+// channelForTrack maps a track index to its MIDI channel
+func channelForTrack(track int) uint8 {
+	switch track {
+	case 0:
+		return 9 // drums
+	case 1:
+		return 1 // bass
+	case 2:
+		return 0 // chords
+	case 3:
+		return 2 // melody
+	case 4:
+		return 3 // fingerstyle
+	default:
+		return midi.PadChannel // pad
+	}
+}
+
+// isTrackSilenced reports whether a track should not sound (must be called with lock held).
+// An explicit mute always wins; otherwise a solo on any track silences the non-soloed ones.
+func (p *RealtimePlayer) isTrackSilenced(track int) bool {
+	if p.mutedTracks[track] {
+		return true
+	}
+	for _, soloed := range p.soloedTracks {
+		if soloed {
+			return !p.soloedTracks[track]
+		}
+	}
+	return false
+}
+
+// stopChannelNotes releases all sounding notes on a channel (must be called with lock held)
+func (p *RealtimePlayer) stopChannelNotes(channel uint8) {
+	for key := range p.activeNotes {
+		if key.channel == channel {
+			p.stopNote(key)
+		}
+	}
+}
+
+// playCountIn plays count-in clicks due at the given (negative) song time (must be called with lock held)
+func (p *RealtimePlayer) playCountIn(remaining time.Duration) {
+	remainingTicks := uint32(-remaining / p.playbackData.TickDuration)
+	if remainingTicks > p.playbackData.CountInTicks {
+		remainingTicks = p.playbackData.CountInTicks
+	}
+	countInTick := p.playbackData.CountInTicks - remainingTicks
+
+	// Count-in clicks ignore track mutes so the count-off is always heard
+	for p.countInIdx < len(p.playbackData.CountInEvents) {
+		evt := p.playbackData.CountInEvents[p.countInIdx]
+		if evt.Tick > countInTick {
+			break
+		}
+		key := noteKey{evt.Channel, evt.Note}
+		if evt.IsNoteOn {
+			p.startNote(key, evt.Velocity)
+		} else {
+			p.stopNote(key)
+		}
+		p.countInIdx++
 	}
 }
 
@@ -326,6 +421,7 @@ func (p *RealtimePlayer) Pause() {
 	if !p.paused {
 		p.paused = true
 		p.pausedAt = time.Now()
+		p.logPractice()
 		// Silence all notes
 		for key := range p.activeNotes {
 			p.sendCommand(fmt.Sprintf("noteoff %d %d", key.channel, key.note))
@@ -340,6 +436,7 @@ func (p *RealtimePlayer) Resume() {
 	if p.paused {
 		p.pausedTotal += time.Since(p.pausedAt)
 		p.paused = false
+		p.logPractice()
 	}
 }
 
@@ -361,34 +458,7 @@ func (p *RealtimePlayer) SeekToBar(bar int) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	if bar < 0 {
-		bar = 0
-	}
-	if bar >= p.playbackData.TotalBars {
-		bar = p.playbackData.TotalBars - 1
-	}
-
-	// Stop all current notes
-	for key := range p.activeNotes {
-		p.sendCommand(fmt.Sprintf("noteoff %d %d", key.channel, key.note))
-	}
-	p.activeNotes = make(map[noteKey]bool)
-
-	// Calculate target tick
-	targetTick := p.playbackData.BarToTick(bar)
-	targetTime := p.playbackData.TickToTime(targetTick)
-
-	// Adjust seek offset to jump to target
-	p.seekOffset = targetTime - (time.Since(p.startTime) - p.pausedTotal)
-
-	// Find the event index for the new position
-	p.lastEventIdx = 0
-	for i, evt := range p.playbackData.Events {
-		if evt.Tick >= targetTick {
-			p.lastEventIdx = i
-			break
-		}
-	}
+	p.seekToBarInternal(bar)
 }
 
 // SeekRelative seeks by a number of bars (positive = forward, negative = backward)
@@ -402,48 +472,104 @@ func (p *RealtimePlayer) SeekRelative(bars int) {
 
 // seekToBarInternal seeks to a bar (must be called with lock held)
 func (p *RealtimePlayer) seekToBarInternal(bar int) {
-	if bar < 0 {
-		bar = 0
+	if bar < p.playbackData.StartBar {
+		bar = p.playbackData.StartBar
 	}
 	if bar >= p.playbackData.TotalBars {
 		bar = p.playbackData.TotalBars - 1
 	}
 
 	// Stop all current notes
-	for key := range p.activeNotes {
-		p.sendCommand(fmt.Sprintf("noteoff %d %d", key.channel, key.note))
-	}
-	p.activeNotes = make(map[noteKey]bool)
+	p.stopAllNotes()
 
 	// Calculate target tick
 	targetTick := p.playbackData.BarToTick(bar)
 	targetTime := p.playbackData.TickToTime(targetTick)
 
-	// Adjust seek offset to jump to target
-	p.seekOffset = targetTime - (time.Since(p.startTime) - p.pausedTotal)
+	// Adjust seek offset to jump to target (song time runs at the tempo multiplier)
+	p.seekOffset = time.Duration(float64(targetTime)/p.speedMultiplier()) - (time.Since(p.startTime) - p.pausedTotal)
 
 	// Find the event index for the new position
-	p.lastEventIdx = 0
+	p.lastEventIdx = len(p.playbackData.Events)
 	for i, evt := range p.playbackData.Events {
 		if evt.Tick >= targetTick {
 			p.lastEventIdx = i
 			break
 		}
 	}
+
+	// Replay the instrument changes so the target bar's section sounds right
+	p.instrumentIdx = 0
+	p.playInstrumentChanges(targetTick)
+
+	if !p.paused {
+		p.retriggerHeldNotes(targetTick)
+	}
+}
+
+// playInstrumentChanges switches section instruments due by tick (must be called with lock held)
+func (p *RealtimePlayer) playInstrumentChanges(tick uint32) {
+	changes := p.playbackData.Instruments
+	for p.instrumentIdx < len(changes) && changes[p.instrumentIdx].Tick <= tick {
+		change := changes[p.instrumentIdx]
+		p.sendCommand(fmt.Sprintf("prog %d %d", change.Channel, midi.GMProgram(change.Instrument, midi.DefaultChordsProgram)))
+		p.instrumentIdx++
+	}
+}
+
+// retriggerHeldNotes restarts the notes that started before targetTick and are
+// still held there (e.g. a whole-bar chord when seeking into its second beat), so
+// they sound until their note-offs (must be called with lock held). Drum hits are
+// not restarted.
+func (p *RealtimePlayer) retriggerHeldNotes(targetTick uint32) {
+	events := p.playbackData.Events
+	held := make(map[noteKey]int) // Index of the sounding note-on by channel and note
+	for i, evt := range events[:p.lastEventIdx] {
+		key := noteKey{evt.Channel, evt.Note}
+		if evt.IsNoteOn {
+			held[key] = i
+		} else {
+			delete(held, key)
+		}
+	}
+
+	// Notes released exactly at the target are over
+	for _, evt := range events[p.lastEventIdx:] {
+		if evt.Tick > targetTick {
+			break
+		}
+		if !evt.IsNoteOn {
+			delete(held, noteKey{evt.Channel, evt.Note})
+		}
+	}
+
+	var starts []int
+	for key, idx := range held {
+		if key.channel != 9 {
+			starts = append(starts, idx)
+		}
+	}
+	sort.Ints(starts) // Restart in the original order
+	for _, idx := range starts {
+		p.playEvent(events[idx])
+	}
 }
 
 // SetLoop sets or clears the loop. length=0 disables looping.
 // If length > 0, sets a loop from current bar for 'length' bars.
 func (p *RealtimePlayer) SetLoop(length int) {
+	p.SetLoopWithRepeats(length, 0)
+}
+
+// SetLoopWithRepeats sets a loop from the current bar for 'length' bars that plays
+// 'repeats' times in all and then lets the song continue (repeats=0 loops forever)
+func (p *RealtimePlayer) SetLoopWithRepeats(length, repeats int) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	defer p.logPractice()
 
 	if length <= 0 {
-		// Disable loop
-		p.loopEnabled = false
-		p.loopStartBar = 0
-		p.loopEndBar = 0
-		p.loopLength = 0
+		p.clearLoop()
 		return
 	}
 
@@ -455,9 +581,59 @@ func (p *RealtimePlayer) SetLoop(length int) {
 		p.loopEndBar = p.playbackData.TotalBars
 	}
 	p.loopLength = length
+	p.loopPasses = repeats
 	p.loopEnabled = true
 }
 
+// SetLoopPoints loops bars [startBar, endBar) (0-based, endBar exclusive), like an
+// A/B loop with in and out points. The bars are clamped to the playable range; an
+// empty span clears the loop.
+func (p *RealtimePlayer) SetLoopPoints(startBar, endBar int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	defer p.logPractice()
+
+	startBar = max(startBar, p.playbackData.StartBar)
+	endBar = min(endBar, p.playbackData.TotalBars)
+	if endBar <= startBar {
+		p.clearLoop()
+		return
+	}
+	p.loopStartBar = startBar
+	p.loopEndBar = endBar
+	p.loopLength = endBar - startBar
+	p.loopPasses = 0
+	p.loopEnabled = true
+}
+
+// SetLoopRepeats changes how many more times the active loop plays, counting the
+// current pass (0 = forever)
+func (p *RealtimePlayer) SetLoopRepeats(repeats int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.loopEnabled {
+		p.loopPasses = repeats
+	}
+}
+
+// GetLoopRepeats returns the passes left in the active loop, counting the
+// current one (0 = loops forever)
+func (p *RealtimePlayer) GetLoopRepeats() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.loopPasses
+}
+
+// clearLoop disables the loop (must be called with lock held)
+func (p *RealtimePlayer) clearLoop() {
+	p.loopEnabled = false
+	p.loopStartBar = 0
+	p.loopEndBar = 0
+	p.loopLength = 0
+	p.loopPasses = 0
+	p.logPractice()
+}
+
 // ToggleLoop toggles loop of specified length. If already looping with same length, disables.
 func (p *RealtimePlayer) ToggleLoop(length int) {
 	p.mu.Lock()
@@ -493,7 +669,76 @@ func (p *RealtimePlayer) AdjustTempo(deltaBPM int) {
 	if effectiveTempo < 20 {
 		newOffset = 20 - p.playbackData.Tempo
 	}
-	p.tempoOffset = newOffset
+	p.setTempoOffset(newOffset)
+}
+
+// SetEffectiveTempo sets the tempo offset so playback runs at bpm (tap tempo),
+// clamped to the same 20 BPM minimum as AdjustTempo
+func (p *RealtimePlayer) SetEffectiveTempo(bpm int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if bpm < 20 {
+		bpm = 20
+	}
+	p.setTempoOffset(bpm - p.playbackData.Tempo)
+}
+
+// setTempoOffset changes the tempo offset without moving the playback position:
+// song time is real time times the tempo multiplier, so the seek offset is rebased
+// to keep the current song time (must be called with lock held)
+func (p *RealtimePlayer) setTempoOffset(offset int) {
+	defer p.logPractice()
+	now := time.Now()
+	if p.paused {
+		now = p.pausedAt
+	}
+	realElapsed := now.Sub(p.startTime) - p.pausedTotal + p.seekOffset
+	if p.playing && realElapsed >= p.playbackData.StartTime() {
+		songTime := time.Duration(float64(realElapsed) * p.speedMultiplier())
+		p.tempoOffset = offset
+		p.seekOffset += time.Duration(float64(songTime)/p.speedMultiplier()) - realElapsed
+		return
+	}
+	p.tempoOffset = offset
+}
+
+// speedMultiplier returns the effective tempo over the track tempo (must be called with lock held)
+func (p *RealtimePlayer) speedMultiplier() float64 {
+	return float64(p.playbackData.Tempo+p.tempoOffset) / float64(p.playbackData.Tempo)
+}
+
+// SetTrainer turns on the speed trainer: every pass of the loop raises the tempo
+// by step BPM until it reaches maxBPM (0 = no limit). step=0 turns it off.
+func (p *RealtimePlayer) SetTrainer(step, maxBPM int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.trainerStep = step
+	p.trainerMax = maxBPM
+}
+
+// GetTrainer returns the trainer's step and target tempo (step 0 = off)
+func (p *RealtimePlayer) GetTrainer() (step, maxBPM int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.trainerStep, p.trainerMax
+}
+
+// advanceTrainer raises the tempo by one trainer step at the end of a loop pass
+// (must be called with lock held)
+func (p *RealtimePlayer) advanceTrainer() {
+	if p.trainerStep <= 0 {
+		return
+	}
+	tempo := p.playbackData.Tempo + p.tempoOffset
+	if p.trainerMax > 0 && tempo >= p.trainerMax {
+		return
+	}
+	next := tempo + p.trainerStep
+	if p.trainerMax > 0 && next > p.trainerMax {
+		next = p.trainerMax
+	}
+	p.setTempoOffset(next - p.playbackData.Tempo)
 }
 
 // GetTempo returns the current effective tempo and the offset from original
@@ -503,6 +748,16 @@ func (p *RealtimePlayer) GetTempo() (effectiveBPM int, offset int) {
 	return p.playbackData.Tempo + p.tempoOffset, p.tempoOffset
 }
 
+// GetTempoAt returns the effective tempo at a bar and beat, following the song's
+// tempo changes (section tempos, tempo_change, a ritard ending)
+func (p *RealtimePlayer) GetTempoAt(bar, beat int) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ticksPerBeat := p.playbackData.TicksPerBar / uint32(p.playbackData.BeatsPerBar)
+	tick := p.playbackData.BarToTick(bar) + uint32(beat)*ticksPerBeat
+	return int(math.Round(p.playbackData.TempoAt(tick) * p.speedMultiplier()))
+}
+
 // GetCurrentSection returns the section at the current playback position
 func (p *RealtimePlayer) GetCurrentSection() (name string, startBar, endBar int) {
 	p.mu.Lock()
@@ -520,24 +775,19 @@ func (p *RealtimePlayer) GetCurrentSection() (name string, startBar, endBar int)
 func (p *RealtimePlayer) LoopCurrentSection() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	defer p.logPractice()
 
 	currentBar := p.getCurrentBar()
 	section := p.playbackData.GetSectionAtBar(currentBar)
 	if section == nil {
 		// No section at current position - disable loop
-		p.loopEnabled = false
-		p.loopStartBar = 0
-		p.loopEndBar = 0
-		p.loopLength = 0
+		p.clearLoop()
 		return
 	}
 
 	// If already looping this section, toggle off
 	if p.loopEnabled && p.loopStartBar == section.StartBar && p.loopEndBar == section.EndBar {
-		p.loopEnabled = false
-		p.loopStartBar = 0
-		p.loopEndBar = 0
-		p.loopLength = 0
+		p.clearLoop()
 		return
 	}
 
@@ -546,16 +796,26 @@ func (p *RealtimePlayer) LoopCurrentSection() {
 	p.loopStartBar = section.StartBar
 	p.loopEndBar = section.EndBar
 	p.loopLength = section.EndBar - section.StartBar
+	p.loopPasses = 0
 }
 
 // GetSections returns all sections in the track
-func (p *RealtimePlayer) GetSections() []struct{ Name string; StartBar, EndBar int } {
+func (p *RealtimePlayer) GetSections() []struct {
+	Name             string
+	StartBar, EndBar int
+} {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	result := make([]struct{ Name string; StartBar, EndBar int }, len(p.playbackData.Sections))
+	result := make([]struct {
+		Name             string
+		StartBar, EndBar int
+	}, len(p.playbackData.Sections))
 	for i, s := range p.playbackData.Sections {
-		result[i] = struct{ Name string; StartBar, EndBar int }{s.Name, s.StartBar, s.EndBar}
+		result[i] = struct {
+			Name             string
+			StartBar, EndBar int
+		}{s.Name, s.StartBar, s.EndBar}
 	}
 	return result
 }
@@ -581,7 +841,11 @@ func (p *RealtimePlayer) GetLyricsForBar(bar int) (text string, chords []string)
 func (p *RealtimePlayer) getLyricsForBarLocked(bar int) (text string, chords []string) {
 	lyricLine := p.playbackData.GetLyricsAtBar(bar)
 	if lyricLine == nil {
-		return "", nil
+		// Per-bar lyrics, with any beat markers removed
+		if bar >= 0 && bar < len(p.track.Lyrics) {
+			text, _ = parser.ParseBarLyrics(p.track.Lyrics[bar], p.playbackData.BeatsPerBar, nil)
+		}
+		return text, nil
 	}
 
 	chords = make([]string, len(lyricLine.ChordMarks))
@@ -595,14 +859,14 @@ func (p *RealtimePlayer) getLyricsForBarLocked(bar int) (text string, chords []s
 func (p *RealtimePlayer) HasLyrics() bool {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	return len(p.playbackData.Lyrics) > 0
+	return len(p.playbackData.Lyrics) > 0 || len(p.track.Lyrics) > 0
 }
 
 // getSpeedAdjustedElapsed returns the elapsed playback time adjusted for tempo changes (must be called with lock held)
 func (p *RealtimePlayer) getSpeedAdjustedElapsed() time.Duration {
 	realElapsed := time.Since(p.startTime) - p.pausedTotal + p.seekOffset
-	if realElapsed < 0 {
-		realElapsed = 0
+	if start := p.playbackData.StartTime(); realElapsed < start {
+		realElapsed = start // Count-in
 	}
 	// Calculate speed multiplier from tempo offset
 	// e.g., original 120 BPM + 10 offset = 130 BPM effective = 130/120 = 1.083x speed
@@ -639,10 +903,7 @@ func (p *RealtimePlayer) Transpose(semitones int) {
 	defer p.mu.Unlock()
 
 	// Stop all current notes before changing transpose
-	for key := range p.activeNotes {
-		p.sendCommand(fmt.Sprintf("noteoff %d %d", key.channel, key.note))
-	}
-	p.activeNotes = make(map[noteKey]bool)
+	p.stopAllNotes()
 
 	p.transposeOffset += semitones
 }
@@ -666,10 +927,7 @@ func (p *RealtimePlayer) SetCapo(fret int) {
 	}
 
 	// Stop all current notes before changing capo
-	for key := range p.activeNotes {
-		p.sendCommand(fmt.Sprintf("noteoff %d %d", key.channel, key.note))
-	}
-	p.activeNotes = make(map[noteKey]bool)
+	p.stopAllNotes()
 
 	p.capoPosition = fret
 }
@@ -681,9 +939,9 @@ func (p *RealtimePlayer) GetCapo() int {
 	return p.capoPosition
 }
 
-// ToggleTrackMute toggles mute state for a track (0=drums, 1=bass, 2=chords, 3=melody, 4=fingerstyle)
+// ToggleTrackMute toggles mute state for a track (0=drums, 1=bass, 2=chords, 3=melody, 4=fingerstyle, 5=pad)
 func (p *RealtimePlayer) ToggleTrackMute(track int) {
-	if track < 0 || track > 4 {
+	if track < 0 || track > 5 {
 		return
 	}
 	p.mu.Lock()
@@ -693,33 +951,42 @@ func (p *RealtimePlayer) ToggleTrackMute(track int) {
 
 	// If muting, stop all notes on that channel
 	if p.mutedTracks[track] {
-		// Map track to channel
-		var channel uint8
-		switch track {
-		case 0:
-			channel = 9 // drums
-		case 1:
-			channel = 1 // bass
-		case 2:
-			channel = 0 // chords
-		case 3:
-			channel = 2 // melody
-		case 4:
-			channel = 3 // fingerstyle
-		}
-		// Stop notes on this channel
-		for key := range p.activeNotes {
-			if key.channel == channel {
-				p.sendCommand(fmt.Sprintf("noteoff %d %d", key.channel, key.note))
-				delete(p.activeNotes, key)
-			}
+		p.stopChannelNotes(channelForTrack(track))
+	}
+}
+
+// ToggleTrackSolo toggles solo state for a track (0=drums, 1=bass, 2=chords, 3=melody, 4=fingerstyle, 5=pad)
+// While any track is soloed, only soloed tracks that aren't muted are heard
+func (p *RealtimePlayer) ToggleTrackSolo(track int) {
+	if track < 0 || track > 5 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.soloedTracks[track] = !p.soloedTracks[track]
+
+	// Stop notes on tracks that the new solo state silences
+	for i := range p.soloedTracks {
+		if p.isTrackSilenced(i) {
+			p.stopChannelNotes(channelForTrack(i))
 		}
 	}
 }
 
-// IsTrackMuted returns whether a track is muted (0=drums, 1=bass, 2=chords, 3=melody, 4=fingerstyle)
+// IsTrackSoloed returns whether a track is soloed (0=drums, 1=bass, 2=chords, 3=melody, 4=fingerstyle, 5=pad)
+func (p *RealtimePlayer) IsTrackSoloed(track int) bool {
+	if track < 0 || track > 5 {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.soloedTracks[track]
+}
+
+// IsTrackMuted returns whether a track is muted (0=drums, 1=bass, 2=chords, 3=melody, 4=fingerstyle, 5=pad)
 func (p *RealtimePlayer) IsTrackMuted(track int) bool {
-	if track < 0 || track > 4 {
+	if track < 0 || track > 5 {
 		return false
 	}
 	p.mu.Lock()
@@ -727,6 +994,62 @@ func (p *RealtimePlayer) IsTrackMuted(track int) bool {
 	return p.mutedTracks[track]
 }
 
+// SetTrackVolume sets a track's channel volume (CC7, clamped to 0-127)
+// Track indices match ToggleTrackMute
+func (p *RealtimePlayer) SetTrackVolume(track int, vol int) {
+	if track < 0 || track > 5 {
+		return
+	}
+	if vol < 0 {
+		vol = 0
+	} else if vol > 127 {
+		vol = 127
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.trackVolumes[track] = vol
+	p.sendCommand(fmt.Sprintf("cc %d 7 %d", channelForTrack(track), vol))
+}
+
+// GetTrackVolume returns a track's channel volume (0-127)
+func (p *RealtimePlayer) GetTrackVolume(track int) int {
+	if track < 0 || track > 5 {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.trackVolumes[track]
+}
+
+// sendTrackVolumes re-sends the stored volume of every track
+func (p *RealtimePlayer) sendTrackVolumes() {
+	for track, vol := range p.trackVolumes {
+		p.sendCommand(fmt.Sprintf("cc %d 7 %d", channelForTrack(track), vol))
+	}
+}
+
+// SetReverb sets the reverb send (0.0-1.0) on every channel
+func (p *RealtimePlayer) SetReverb(level float64) {
+	p.setEffectSend(midi.ReverbCC, level)
+}
+
+// SetChorus sets the chorus send (0.0-1.0) on every channel
+func (p *RealtimePlayer) SetChorus(level float64) {
+	p.setEffectSend(midi.ChorusCC, level)
+}
+
+// setEffectSend sends an effect controller to all track channels
+func (p *RealtimePlayer) setEffectSend(controller uint8, level float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	value := midi.EffectLevelCC(level)
+	for track := range p.trackVolumes {
+		p.sendCommand(fmt.Sprintf("cc %d %d %d", channelForTrack(track), controller, value))
+	}
+}
+
 // SetFingerstylePattern changes the fingerstyle pattern and regenerates events
 func (p *RealtimePlayer) SetFingerstylePattern(pattern midi.PatternType) {
 	p.mu.Lock()
@@ -734,16 +1057,8 @@ func (p *RealtimePlayer) SetFingerstylePattern(pattern midi.PatternType) {
 
 	p.fingerstylePattern = pattern
 
-	// Stop any active fingerstyle notes
-	for key := range p.activeNotes {
-		if key.channel == 3 {
-			p.sendCommand(fmt.Sprintf("noteoff %d %d", key.channel, key.note))
-			delete(p.activeNotes, key)
-		}
-	}
-
 	// Regenerate playback data with new pattern
-	p.playbackData = midi.GeneratePlaybackDataWithPattern(p.track, pattern)
+	p.swapPlaybackData(p.generatePlaybackData())
 }
 
 // GetFingerstylePattern returns the current fingerstyle pattern type
@@ -753,18 +1068,114 @@ func (p *RealtimePlayer) GetFingerstylePattern() midi.PatternType {
 	return p.fingerstylePattern
 }
 
+// ToggleSwing switches every part between straight and swung and regenerates the
+// playback data: a swung track plays straight, a straight one gets a triplet feel,
+// and toggling again goes back to the track as written
+func (p *RealtimePlayer) ToggleSwing() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case p.swing > 0:
+		p.swing = 0
+	case p.track.IsSwung():
+		p.swing = 0.5
+	default:
+		p.swing = parser.TripletSwing
+	}
+	p.swapPlaybackData(p.generatePlaybackData())
+}
+
+// GetSwing returns whether the parts are swung, and whether that is toggled from
+// the track as written
+func (p *RealtimePlayer) GetSwing() (swung, toggled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.swing > 0 {
+		return p.swing > 0.5, true
+	}
+	return p.track.IsSwung(), false
+}
+
+// SwapPlaybackData replaces the events being played with pd, regenerated from the
+// same song (same bars and tempo), without moving the playback position: playback
+// carries on from the current tick in the new events, and the notes held across it
+// are restarted. Arrangement changes such as swing take effect live this way.
+func (p *RealtimePlayer) SwapPlaybackData(pd *midi.PlaybackData) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.swapPlaybackData(pd)
+}
+
+// swapPlaybackData does SwapPlaybackData (must be called with lock held)
+func (p *RealtimePlayer) swapPlaybackData(pd *midi.PlaybackData) {
+	if !p.playing {
+		p.playbackData = pd // Start sets the position
+		return
+	}
+
+	now := time.Now()
+	if p.paused {
+		now = p.pausedAt
+	}
+	realElapsed := now.Sub(p.startTime) - p.pausedTotal + p.seekOffset
+	countingIn := realElapsed < p.playbackData.StartTime()
+	elapsed := max(realElapsed, p.playbackData.StartTime())
+	currentTick := p.playbackData.TimeToTick(time.Duration(float64(elapsed) * p.speedMultiplier()))
+
+	p.stopAllNotes()
+	p.playbackData = pd
+
+	// Events up to the current tick have been played (none yet during the count-in)
+	p.lastEventIdx = sort.Search(len(pd.Events), func(i int) bool {
+		if countingIn {
+			return pd.Events[i].Tick >= currentTick
+		}
+		return pd.Events[i].Tick > currentTick
+	})
+	p.instrumentIdx = 0
+	p.playInstrumentChanges(currentTick)
+
+	if !p.paused && !countingIn {
+		p.retriggerHeldNotes(currentTick)
+	}
+}
+
 // allNotesOff sends note-off for all channels
 func (p *RealtimePlayer) allNotesOff() {
 	// Turn off any active notes
-	for key := range p.activeNotes {
-		p.sendCommand(fmt.Sprintf("noteoff %d %d", key.channel, key.note))
-	}
-	p.activeNotes = make(map[noteKey]bool)
+	p.stopAllNotes()
 
 	// Also send all-notes-off for safety
 	for ch := 0; ch < 16; ch++ {
 		p.sendCommand(fmt.Sprintf("cc %d 123 0", ch)) // All notes off
 	}
+
+	// Restore the mix in case the synth reset controllers
+	p.sendTrackVolumes()
+}
+
+// SetPracticeLog has the player report its loops, tempo changes and pauses to log,
+// which is written when the player stops. Call before Start.
+func (p *RealtimePlayer) SetPracticeLog(log *PracticeLog) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.log = log
+}
+
+// practiceState returns the loop and tempo being played (must be called with lock held)
+func (p *RealtimePlayer) practiceState() practiceState {
+	return practiceState{
+		looping:  p.loopEnabled,
+		startBar: p.loopStartBar,
+		endBar:   p.loopEndBar,
+		tempo:    p.playbackData.Tempo + p.tempoOffset,
+	}
+}
+
+// logPractice reports the current state to the practice log (must be called with lock held)
+func (p *RealtimePlayer) logPractice() {
+	p.log.record(p.practiceState(), p.playing && !p.paused)
 }
 
 // Stop stops playback and cleans up
@@ -773,23 +1184,25 @@ func (p *RealtimePlayer) Stop() {
 		close(p.stopChan)
 	})
 
+	p.mu.Lock()
+	p.log.record(p.practiceState(), false)
+	p.mu.Unlock()
+	if err := p.log.write(); err != nil {
+		fmt.Printf("Warning: writing the practice log: %v\n", err)
+	}
+
 	p.allNotesOff()
 	p.sendCommand("quit")
 	p.stdin.Close()
 
 	// Wait for FluidSynth with timeout
-	done := make(chan error, 1)
-	go func() {
-		done <- p.cmd.Wait()
-	}()
-
 	select {
-	case <-done:
+	case <-p.exited:
 		// FluidSynth exited normally
 	case <-time.After(2 * time.Second):
 		// Timeout - force kill
 		p.cmd.Process.Kill()
-		<-done
+		<-p.exited
 	}
 }
 
@@ -803,8 +1216,8 @@ func (p *RealtimePlayer) GetPlaybackState() (bar int, beat int, strum int, pause
 	if p.paused {
 		// When paused, use time up to when pause happened
 		realElapsed := p.pausedAt.Sub(p.startTime) - p.pausedTotal + p.seekOffset
-		if realElapsed < 0 {
-			realElapsed = 0
+		if start := p.playbackData.StartTime(); realElapsed < start {
+			realElapsed = start
 		}
 		effectiveTempo := float64(p.playbackData.Tempo + p.tempoOffset)
 		originalTempo := float64(p.playbackData.Tempo)
@@ -815,20 +1228,13 @@ func (p *RealtimePlayer) GetPlaybackState() (bar int, beat int, strum int, pause
 	}
 
 	currentTick := p.playbackData.TimeToTick(elapsed)
-	ticksPerBeat := p.playbackData.TicksPerBar / 4
+	ticksPerBeat := p.playbackData.TicksPerBar / uint32(p.playbackData.BeatsPerBar)
 
 	bar = int(currentTick / p.playbackData.TicksPerBar)
 	beat = int((currentTick % p.playbackData.TicksPerBar) / ticksPerBeat)
 
-	// Calculate strum position based on rhythm style
-	strumsPerBar := 8 // Default for 8th notes
-	if p.track != nil && p.track.Rhythm != nil {
-		switch p.track.Rhythm.Style {
-		case "sixteenth", "funk_16th", "funk_muted", "dust_in_wind", "landslide", "pima", "pima_reverse":
-			strumsPerBar = 16
-		}
-	}
-	ticksPerStrum := p.playbackData.TicksPerBar / uint32(strumsPerBar)
+	// Strum position on the same grid the display draws (see midi.StrumSteps)
+	ticksPerStrum := p.playbackData.TicksPerBar / uint32(p.strumsPerBar)
 	strum = int((currentTick % p.playbackData.TicksPerBar) / ticksPerStrum)
 
 	paused = p.paused
@@ -836,8 +1242,14 @@ func (p *RealtimePlayer) GetPlaybackState() (bar int, beat int, strum int, pause
 	return
 }
 
-// WaitForInput waits for user input to control playback (for non-TUI mode)
-func (p *RealtimePlayer) WaitForInput() {
+// Done returns a channel that is closed when playback ends (end of the song or Stop)
+func (p *RealtimePlayer) Done() <-chan struct{} {
+	return p.done
+}
+
+// WaitForInput waits for user input to control playback (for non-TUI mode).
+// It returns true when the user quits and false when stdin ends.
+func (p *RealtimePlayer) WaitForInput() bool {
 	scanner := bufio.NewScanner(os.Stdin)
 	fmt.Println("Controls: [space] pause/resume, [n] next bar, [p] prev bar, [q] quit")
 	for scanner.Scan() {
@@ -850,7 +1262,8 @@ func (p *RealtimePlayer) WaitForInput() {
 		case "p":
 			p.SeekRelative(-1)
 		case "q":
-			return
+			return true
 		}
 	}
+	return false
 }
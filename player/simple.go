@@ -0,0 +1,70 @@
+package player
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"backing-tracks/display"
+	"backing-tracks/parser"
+)
+
+// statusPollInterval is how often the plain status output checks the position
+const statusPollInterval = 100 * time.Millisecond
+
+// playWithStatusLines plays through the real-time player without the TUI, printing
+// a plain "Bar 5/12 — C → G [Verse]" line at each new bar so the output reads well
+// in logs and pipes. Typed commands (WaitForInput) control playback when stdin is
+// readable; it returns when the song ends or the user quits.
+func playWithStatusLines(player *RealtimePlayer, track *parser.Track) {
+	bars := display.ProcessChordsIntoBars(track)
+	totalBars := player.playbackData.TotalBars
+
+	quit := make(chan struct{})
+	go func() {
+		if player.WaitForInput() {
+			close(quit)
+		}
+	}()
+
+	ticker := time.NewTicker(statusPollInterval)
+	defer ticker.Stop()
+
+	lastBar, wasPaused := -1, false
+	for {
+		select {
+		case <-player.Done():
+			return
+		case <-quit:
+			return
+		case <-ticker.C:
+		}
+
+		bar, _, _, paused := player.GetPlaybackState()
+		if paused != wasPaused {
+			wasPaused = paused
+			if paused {
+				fmt.Println("Paused")
+			} else {
+				fmt.Println("Resumed")
+			}
+		}
+		if bar == lastBar || paused {
+			continue
+		}
+		lastBar = bar
+
+		line := fmt.Sprintf("Bar %d/%d", bar+1, totalBars)
+		if bar < len(bars) && len(bars[bar].Chords) > 0 {
+			var names []string
+			for _, c := range bars[bar].Chords {
+				names = append(names, c.Symbol)
+			}
+			line += " — " + strings.Join(names, " → ")
+		}
+		if name, _, _ := player.GetCurrentSection(); name != "" {
+			line += fmt.Sprintf(" [%s]", name)
+		}
+		fmt.Println(line)
+	}
+}
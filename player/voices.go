@@ -0,0 +1,75 @@
+package player
+
+import "fmt"
+
+// Voice tracking: every note sent to FluidSynth goes through startNote/stopNote so
+// activeNotes (and noteAges, oldest first) always match what is sounding. With a
+// polyphony limit set, a new note steals the oldest one on its channel instead of
+// piling up voices until the synth chokes.
+
+// SetMaxVoices limits how many notes may sound at once (0 = unlimited)
+func (p *RealtimePlayer) SetMaxVoices(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if n < 0 {
+		n = 0
+	}
+	p.maxVoices = n
+}
+
+// startNote sends a note-on, first stealing a voice if the limit is reached
+// (must be called with lock held)
+func (p *RealtimePlayer) startNote(key noteKey, velocity uint8) {
+	if p.activeNotes[key] {
+		p.forgetNote(key) // Retriggered; it becomes the newest note
+	} else if p.maxVoices > 0 && len(p.activeNotes) >= p.maxVoices {
+		p.stealVoice(key.channel)
+	}
+
+	p.sendCommand(fmt.Sprintf("noteon %d %d %d", key.channel, key.note, velocity))
+	p.activeNotes[key] = true
+	p.noteAges = append(p.noteAges, key)
+}
+
+// stopNote sends a note-off and forgets the note (must be called with lock held)
+func (p *RealtimePlayer) stopNote(key noteKey) {
+	p.sendCommand(fmt.Sprintf("noteoff %d %d", key.channel, key.note))
+	p.forgetNote(key)
+}
+
+// stopAllNotes releases every sounding note (must be called with lock held)
+func (p *RealtimePlayer) stopAllNotes() {
+	for key := range p.activeNotes {
+		p.sendCommand(fmt.Sprintf("noteoff %d %d", key.channel, key.note))
+	}
+	p.activeNotes = make(map[noteKey]bool)
+	p.noteAges = nil
+}
+
+// stealVoice releases the oldest note on the channel, or the oldest note overall
+// if the channel has none (must be called with lock held)
+func (p *RealtimePlayer) stealVoice(channel uint8) {
+	if len(p.noteAges) == 0 {
+		return
+	}
+	victim := p.noteAges[0]
+	for _, key := range p.noteAges {
+		if key.channel == channel {
+			victim = key
+			break
+		}
+	}
+	p.stopNote(victim)
+}
+
+// forgetNote removes a note from the active set without sending anything
+func (p *RealtimePlayer) forgetNote(key noteKey) {
+	delete(p.activeNotes, key)
+	for i, k := range p.noteAges {
+		if k == key {
+			p.noteAges = append(p.noteAges[:i], p.noteAges[i+1:]...)
+			break
+		}
+	}
+}
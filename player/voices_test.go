@@ -0,0 +1,127 @@
+package player
+
+import (
+	"strings"
+	"testing"
+)
+
+// commandLog records the commands sent to the synth
+type commandLog struct {
+	strings.Builder
+}
+
+func (l *commandLog) Close() error { return nil }
+
+// commands returns the commands sent since the last call
+func (l *commandLog) commands() []string {
+	sent := strings.TrimSpace(l.String())
+	l.Reset()
+	if sent == "" {
+		return nil
+	}
+	return strings.Split(sent, "\n")
+}
+
+// newVoiceTestPlayer returns a player that logs synth commands, limited to maxVoices
+func newVoiceTestPlayer(maxVoices int) (*RealtimePlayer, *commandLog) {
+	log := &commandLog{}
+	p := &RealtimePlayer{stdin: log, activeNotes: make(map[noteKey]bool)}
+	p.SetMaxVoices(maxVoices)
+	return p, log
+}
+
+func TestStealVoice(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		playing []noteKey
+		channel uint8
+		want    []string // Commands sent
+		left    []noteKey
+	}{
+		{"nothing playing", nil, 0, nil, nil},
+		{"oldest on the channel",
+			[]noteKey{{1, 40}, {0, 60}, {0, 64}}, 0,
+			[]string{"noteoff 0 60"}, []noteKey{{1, 40}, {0, 64}}},
+		{"oldest overall when the channel is silent",
+			[]noteKey{{1, 40}, {0, 60}}, 2,
+			[]string{"noteoff 1 40"}, []noteKey{{0, 60}}},
+	} {
+		p, log := newVoiceTestPlayer(0)
+		for _, key := range tc.playing {
+			p.startNote(key, 100)
+		}
+		log.commands()
+
+		p.stealVoice(tc.channel)
+		if got := log.commands(); strings.Join(got, ", ") != strings.Join(tc.want, ", ") {
+			t.Errorf("%s: sent %v, want %v", tc.name, got, tc.want)
+		}
+		if len(p.noteAges) != len(tc.left) || len(p.activeNotes) != len(tc.left) {
+			t.Errorf("%s: %v still active, want %v", tc.name, p.noteAges, tc.left)
+			continue
+		}
+		for i, key := range tc.left {
+			if p.noteAges[i] != key || !p.activeNotes[key] {
+				t.Errorf("%s: %v still active, want %v", tc.name, p.noteAges, tc.left)
+				break
+			}
+		}
+	}
+}
+
+func TestForgetNote(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		forget noteKey
+		left   []noteKey
+	}{
+		{"oldest", noteKey{0, 60}, []noteKey{{0, 64}, {1, 40}}},
+		{"middle", noteKey{0, 64}, []noteKey{{0, 60}, {1, 40}}},
+		{"newest", noteKey{1, 40}, []noteKey{{0, 60}, {0, 64}}},
+		{"not playing", noteKey{9, 36}, []noteKey{{0, 60}, {0, 64}, {1, 40}}},
+	} {
+		p, log := newVoiceTestPlayer(0)
+		for _, key := range []noteKey{{0, 60}, {0, 64}, {1, 40}} {
+			p.startNote(key, 100)
+		}
+		log.commands()
+
+		p.forgetNote(tc.forget)
+		if sent := log.commands(); len(sent) != 0 {
+			t.Errorf("%s: forgetting sent %v, want nothing", tc.name, sent)
+		}
+		if p.activeNotes[tc.forget] {
+			t.Errorf("%s: %v is still active", tc.name, tc.forget)
+		}
+		if len(p.noteAges) != len(tc.left) {
+			t.Errorf("%s: note ages %v, want %v", tc.name, p.noteAges, tc.left)
+			continue
+		}
+		for i, key := range tc.left {
+			if p.noteAges[i] != key {
+				t.Errorf("%s: note ages %v, want %v", tc.name, p.noteAges, tc.left)
+				break
+			}
+		}
+	}
+}
+
+func TestMaxVoicesStealsOnStart(t *testing.T) {
+	p, log := newVoiceTestPlayer(2)
+	p.startNote(noteKey{0, 60}, 100)
+	p.startNote(noteKey{0, 64}, 100)
+	p.startNote(noteKey{0, 67}, 100)
+	want := []string{"noteon 0 60 100", "noteon 0 64 100", "noteoff 0 60", "noteon 0 67 100"}
+	if got := log.commands(); strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("sent %v, want %v", got, want)
+	}
+
+	// Retriggering a sounding note doesn't steal another voice
+	p.startNote(noteKey{0, 64}, 90)
+	if got := log.commands(); len(got) != 1 || got[0] != "noteon 0 64 90" {
+		t.Errorf("retrigger sent %v, want only the note-on", got)
+	}
+	if len(p.noteAges) != 2 || p.noteAges[1] != (noteKey{0, 64}) {
+		t.Errorf("note ages %v, want the retriggered note newest", p.noteAges)
+	}
+}
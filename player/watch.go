@@ -0,0 +1,134 @@
+package player
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"backing-tracks/display"
+	"backing-tracks/parser"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/term"
+)
+
+// watchPollInterval is how often the BTML file's modification time is checked
+const watchPollInterval = 500 * time.Millisecond
+
+// PlayWithWatch plays a track with the live TUI and restarts playback whenever
+// the BTML file changes, continuing from the bar that was playing. load re-reads
+// the file; if it fails, the error is shown in the TUI and the old track keeps playing.
+func PlayWithWatch(filename string, load func() (*parser.Track, error), customSoundFont string, opts PlayOptions) error {
+	// Check if FluidSynth is installed
+	if err := CheckFluidSynth(); err != nil {
+		return err
+	}
+
+	// Find a SoundFont file
+	soundFont, err := findSoundFont(customSoundFont)
+	if err != nil {
+		return err
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("--watch requires a terminal")
+	}
+
+	track, err := load()
+	if err != nil {
+		return err
+	}
+
+	startBar := -1 // No seek on the first run so the count-in plays
+	for {
+		reloaded, bar, err := playUntilChange(filename, track, soundFont, opts, startBar, load)
+		if err != nil || reloaded == nil {
+			return err
+		}
+		track, startBar = reloaded, bar
+	}
+}
+
+// playUntilChange plays the track until the user quits (returns a nil track) or
+// the file changes and reloads cleanly (returns the new track and the current bar)
+func playUntilChange(filename string, track *parser.Track, soundFont string, opts PlayOptions, startBar int, load func() (*parser.Track, error)) (*parser.Track, int, error) {
+	player, err := NewRealtimePlayer(track, soundFont, opts.audioDriver())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer player.Stop()
+
+	tuiModel := display.NewTUIModel(track)
+	tuiModel.SetPlayer(player)
+
+	if startBar >= 0 {
+		// Reloaded after a save: pick up where playback was, without a countdown
+		opts.start(player)
+		player.SeekToBar(startBar)
+	} else {
+		opts.startTUI(player, tuiModel, track)
+	}
+
+	p := tea.NewProgram(tuiModel, tea.WithAltScreen())
+
+	stop := make(chan struct{})
+	changed := make(chan *parser.Track, 1)
+	go watchFile(filename, load, p, stop, changed)
+
+	_, err = p.Run()
+	close(stop)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if tuiModel.IsQuitting() {
+		return nil, 0, nil
+	}
+	select {
+	case reloaded := <-changed:
+		return reloaded, player.GetCurrentBar(), nil
+	default:
+		return nil, 0, nil
+	}
+}
+
+// watchFile polls the file's modification time. On a change it reloads the
+// track: a parse error is sent to the TUI, a good track is handed back on
+// changed and the TUI is told to quit so playback can restart.
+func watchFile(filename string, load func() (*parser.Track, error), p *tea.Program, stop <-chan struct{}, changed chan<- *parser.Track) {
+	lastMod := modTime(filename)
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			mod := modTime(filename)
+			if mod.Equal(lastMod) {
+				continue
+			}
+			lastMod = mod
+
+			track, err := load()
+			if err != nil {
+				p.Send(display.WatchErrorMsg{Err: err})
+				continue
+			}
+			changed <- track
+			p.Quit()
+			return
+		}
+	}
+}
+
+// modTime returns the file's modification time (zero if it can't be read,
+// e.g. while an editor is replacing it)
+func modTime(filename string) time.Time {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -16,30 +16,59 @@ func GenerateStrudel(track *parser.Track) string {
 	sb.WriteString(fmt.Sprintf("// Key: %s | Tempo: %d BPM | Style: %s\n", track.Info.Key, track.Info.Tempo, track.Info.Style))
 	sb.WriteString("// Generated from BTML\n\n")
 
-	// Build layers
+	chords := track.Progression.GetChords()
+	melodyBars := generateMelodyBars(track)
+
+	// Songs with sections follow the form with arrange(); otherwise loop one stack
+	sections := splitSections(chords)
+	if len(sections) > 1 || (len(sections) == 1 && sections[0].name != "") {
+		sb.WriteString(arrangeSections(track, sections, melodyBars))
+	} else {
+		sb.WriteString(stackLayers(generateLayers(track, chords, melodyBars)))
+	}
+
+	// Add tempo
+	sb.WriteString(fmt.Sprintf("\n  .cpm(%d/4)", track.Info.Tempo))
+
+	return sb.String()
+}
+
+// generateLayers builds the chord, bass, melody and drum layers for a run of chords.
+// melodyBars holds one melody pattern per bar of the chords (nil = no melody).
+func generateLayers(track *parser.Track, chords []parser.Chord, melodyBars []string) []string {
 	layers := []string{}
 
 	// Chord progression
-	chordPattern := generateChordPattern(track)
+	chordPattern := generateChordPattern(track, chords)
 	if chordPattern != "" {
 		layers = append(layers, chordPattern)
 	}
 
 	// Bass line
 	if track.Bass != nil {
-		bassPattern := generateBassPattern(track)
+		bassPattern := generateBassPattern(track, chords)
 		if bassPattern != "" {
 			layers = append(layers, bassPattern)
 		}
 	}
 
+	// Melody
+	if len(melodyBars) > 0 {
+		layers = append(layers, melodyPattern(track, melodyBars))
+	}
+
 	// Drums
 	if track.Drums != nil {
 		drumPatterns := generateDrumPatterns(track)
 		layers = append(layers, drumPatterns...)
 	}
 
-	// Combine all layers with stack()
+	return layers
+}
+
+// stackLayers combines layers with stack()
+func stackLayers(layers []string) string {
+	var sb strings.Builder
 	if len(layers) == 1 {
 		sb.WriteString(layers[0])
 	} else if len(layers) > 1 {
@@ -53,16 +82,29 @@ func GenerateStrudel(track *parser.Track) string {
 		}
 		sb.WriteString(")")
 	}
+	return sb.String()
+}
 
-	// Add tempo
-	sb.WriteString(fmt.Sprintf("\n  .cpm(%d/4)", track.Info.Tempo))
+// chordsBars returns the total length of chords in bars
+func chordsBars(chords []parser.Chord) float64 {
+	bars := 0.0
+	for _, chord := range chords {
+		bars += chord.Bars
+	}
+	return bars
+}
 
-	return sb.String()
+// slowToBars returns the .slow() that stretches a sequence over its bars (one cycle per bar)
+func slowToBars(chords []parser.Chord) string {
+	bars := chordsBars(chords)
+	if bars == 1.0 || bars == 0 {
+		return ""
+	}
+	return fmt.Sprintf(".slow(%g)", bars)
 }
 
 // generateChordPattern creates Strudel note patterns for chords
-func generateChordPattern(track *parser.Track) string {
-	chords := track.Progression.GetChords()
+func generateChordPattern(track *parser.Track, chords []parser.Chord) string {
 	if len(chords) == 0 {
 		return ""
 	}
@@ -93,10 +135,51 @@ func generateChordPattern(track *parser.Track) string {
 
 	// Apply rhythm subdivision if not just whole notes
 	if rhythm != "1" {
-		return fmt.Sprintf("note(\"%s\").s(\"piano\").struct(\"%s\")", pattern, rhythm)
+		return withSwing(fmt.Sprintf("note(\"%s\")%s.s(\"piano\").struct(\"%s\")", pattern, slowToBars(chords), rhythm), swingAmount(track))
 	}
 
-	return fmt.Sprintf("note(\"%s\").s(\"piano\")", pattern)
+	return fmt.Sprintf("note(\"%s\")%s.s(\"piano\")", pattern, slowToBars(chords))
+}
+
+// tripletSwing is the swing used for shuffle styles that don't set rhythm.swing
+const tripletSwing = 2.0 / 3.0
+
+// shuffleStyles are the rhythm and drum styles with a swung eighth-note feel
+var shuffleStyles = map[string]bool{
+	"shuffle_strum":     true,
+	"shuffle":           true,
+	"blues_shuffle":     true,
+	"jazz_swing":        true,
+	"half_time_shuffle": true,
+}
+
+// swingAmount returns the Strudel swingBy amount for the track (0 = straight).
+// rhythm.swing is where the off-beat eighth falls (0.5 = straight, 0.67 = triplet);
+// like the MIDI generator, the off-beat is delayed by (swing - 0.5) of a beat.
+func swingAmount(track *parser.Track) float64 {
+	swing := 0.5
+	if track.Rhythm != nil && track.Rhythm.Swing > 0 {
+		swing = track.Rhythm.Swing
+	}
+	if swing <= 0.5 {
+		shuffle := track.Rhythm != nil && shuffleStyles[track.Rhythm.Style]
+		shuffle = shuffle || (track.Drums != nil && shuffleStyles[track.Drums.Style])
+		if !shuffle {
+			return 0
+		}
+		swing = tripletSwing
+	}
+
+	// swingBy(x, 4) delays the second half of each beat by x/2 of a beat
+	return (swing - 0.5) * 2
+}
+
+// withSwing appends a swingBy to a pattern; a cycle is one 4/4 bar, so 4 slices are beats
+func withSwing(pattern string, amount float64) string {
+	if amount <= 0 {
+		return pattern
+	}
+	return fmt.Sprintf("%s.swingBy(%.2f, 4)", pattern, amount)
 }
 
 // chordToNotes converts a chord symbol to Strudel note names
@@ -227,7 +310,7 @@ func rhythmToStrudel(rhythm *parser.Rhythm) string {
 	case "folk":
 		return "1 ~ 1 ~ 1 ~ 1 ~"
 	case "shuffle_strum":
-		return "1 ~ ~ 1 ~ 1"
+		return "1 1 1 1 1 1 1 1" // Swung by swingAmount
 	case "travis", "fingerpick":
 		return "1 ~ 1 ~ 1 ~ 1 ~"
 	case "fingerpick_slow":
@@ -256,8 +339,7 @@ func customPatternToStrudel(pattern string) string {
 }
 
 // generateBassPattern creates Strudel pattern for bass
-func generateBassPattern(track *parser.Track) string {
-	chords := track.Progression.GetChords()
+func generateBassPattern(track *parser.Track, chords []parser.Chord) string {
 	if len(chords) == 0 {
 		return ""
 	}
@@ -271,7 +353,7 @@ func generateBassPattern(track *parser.Track) string {
 
 		// Create bass pattern based on style
 		var bassNotes []string
-		octave := 2 // Bass octave
+		octave := max(0, min(8, 2+track.Bass.Octave)) // Bass octave, moved by bass.octave
 
 		switch track.Bass.Style {
 		case "root":
@@ -309,7 +391,7 @@ func generateBassPattern(track *parser.Track) string {
 		patterns = append(patterns, noteStr)
 	}
 
-	return fmt.Sprintf("note(\"%s\").s(\"bass\")", strings.Join(patterns, " "))
+	return fmt.Sprintf("note(\"%s\")%s.s(\"bass\")", strings.Join(patterns, " "), slowToBars(chords))
 }
 
 // generateDrumPatterns creates Strudel patterns for drums
@@ -317,27 +399,49 @@ func generateDrumPatterns(track *parser.Track) []string {
 	drums := track.Drums
 	var patterns []string
 
+	swing := swingAmount(track)
+	if drums.Swing > 0.5 {
+		swing = (drums.Swing - 0.5) * 2 // drums.swing wins for the drums
+	}
+
 	// Handle preset styles
 	if drums.Style != "" {
 		switch drums.Style {
 		case "rock_beat":
-			patterns = append(patterns, "s(\"bd ~ ~ ~ bd ~ ~ ~\")") // Kick on 1, 3
-			patterns = append(patterns, "s(\"~ ~ sd ~ ~ ~ sd ~\")") // Snare on 2, 4
+			patterns = append(patterns, "s(\"bd ~ ~ ~ bd ~ ~ ~\")")       // Kick on 1, 3
+			patterns = append(patterns, "s(\"~ ~ sd ~ ~ ~ sd ~\")")       // Snare on 2, 4
 			patterns = append(patterns, "s(\"hh hh hh hh hh hh hh hh\")") // 8th note hats
-		case "shuffle", "blues_shuffle":
-			patterns = append(patterns, "s(\"bd ~ ~ bd ~ ~ bd ~ ~ bd ~ ~\").slow(1.5)") // Shuffle kick
-			patterns = append(patterns, "s(\"~ ~ ~ sd ~ ~ ~ ~ ~ sd ~ ~\").slow(1.5)") // Shuffle snare
-			patterns = append(patterns, "s(\"hh ~ hh hh ~ hh hh ~ hh hh ~ hh\").slow(1.5)") // Shuffle hats
+		case "shuffle":
+			patterns = append(patterns, "s(\"bd ~ ~ ~ bd ~ ~ ~\")")       // Kick on 1, 3
+			patterns = append(patterns, "s(\"~ ~ sd ~ ~ ~ sd ~\")")       // Snare on 2, 4
+			patterns = append(patterns, "s(\"hh*8\").gain(\"0.6 0.45\")") // Swung 8th hats
+		case "blues_shuffle":
+			patterns = append(patterns, "s(\"bd ~ ~ bd bd ~ ~ bd\")")                       // Kick 1, 3 with pickups
+			patterns = append(patterns, "s(\"~ sd:1 sd ~ ~ sd:1 sd ~\")")                   // Backbeat with ghosts
+			patterns = append(patterns, "s(\"hh oh hh oh hh oh hh oh\").gain(\"0.6 0.5\")") // Open hats on upbeats
 		case "jazz_swing":
-			patterns = append(patterns, "s(\"~ ~ bd ~ ~ ~ ~ ~ bd ~ ~ ~\").slow(1.5)") // Sparse kick
-			patterns = append(patterns, "s(\"~ ~ ~ ~ ~ sd ~ ~ ~ ~ ~ ~\").slow(1.5)") // Sparse snare
-			patterns = append(patterns, "s(\"ride ~ ride ride ~ ride ride ~ ride ride ~ ride\").slow(1.5)") // Ride pattern
+			patterns = append(patterns, "s(\"bd ~ ~ ~ ~ ~ ~ ~\")")            // Sparse kick
+			patterns = append(patterns, "s(\"~ ~ sd ~ ~ ~ sd ~\").gain(0.7)") // Light backbeat
+			patterns = append(patterns, "s(\"ride*8\").gain(\"0.8 0.55\")")   // Swung ride
+		case "bossa", "bossa_nova":
+			patterns = append(patterns, "s(\"bd ~ ~ bd bd ~ ~ bd\")")                       // Steady dotted kick
+			patterns = append(patterns, "s(\"rim ~ ~ rim ~ ~ rim ~ ~ ~ rim ~ rim ~ ~ ~\")") // Side-stick cross-rhythm
+			patterns = append(patterns, "s(\"hh*8\").gain(0.4)")                            // Soft 8th hats
+		case "samba":
+			patterns = append(patterns, "s(\"bd ~ ~ bd lt ~ ~ bd bd ~ ~ bd lt ~ ~ bd\")") // Surdo on 2, 4
+			patterns = append(patterns, "s(\"hh*16\").gain(\"0.4 0.4 0.4 0.8\")")         // Busy 16ths
+			patterns = append(patterns, "s(\"cb ~ ~ cb ~ ~ cb ~ cb ~ cb ~ ~ cb ~ ~\")")   // Agogo
+		case "half_time_shuffle":
+			patterns = append(patterns, "s(\"bd ~ ~ bd ~ ~ ~ bd\")")           // Kick 1 and pickups
+			patterns = append(patterns, "s(\"~ sd:1 ~ sd:1 sd sd:1 ~ sd:1\")") // Backbeat on 3 with ghosts
+			patterns = append(patterns, "s(\"hh*8\").gain(\"0.6 0.45\")")      // Swung 8th hats
 		default:
 			// Minimal default
 			patterns = append(patterns, "s(\"bd ~ ~ ~ bd ~ ~ ~\")")
 			patterns = append(patterns, "s(\"~ ~ sd ~ ~ ~ sd ~\")")
 		}
-		return patterns
+		patterns = append(patterns, percussionPatterns(drums)...)
+		return swingPatterns(patterns, swing)
 	}
 
 	// Handle custom patterns
@@ -369,11 +473,75 @@ func generateDrumPatterns(track *parser.Track) []string {
 		}
 	}
 
+	patterns = append(patterns, percussionPatterns(drums)...)
+	return swingPatterns(patterns, swing)
+}
+
+// percussionPatterns converts the tom, clap, tambourine and cowbell voices, which
+// also play over a preset
+func percussionPatterns(drums *parser.Drums) []string {
+	var patterns []string
+	voices := []struct {
+		pattern *parser.DrumPattern
+		sound   string
+	}{
+		{drums.Tom, "mt"}, {drums.Clap, "cp"}, {drums.Tambourine, "tb"}, {drums.Cowbell, "cb"},
+	}
+	for _, voice := range voices {
+		if voice.pattern == nil {
+			continue
+		}
+		if pattern := drumPatternToStrudel(voice.pattern, voice.sound); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
 	return patterns
 }
 
+// swingPatterns applies withSwing to each pattern
+func swingPatterns(patterns []string, amount float64) []string {
+	for i := range patterns {
+		patterns[i] = withSwing(patterns[i], amount)
+	}
+	return patterns
+}
+
+// strudelDrumSounds maps drum sound names (see midi.DrumSounds) to Strudel samples;
+// GM note numbers keep the voice's own sample
+var strudelDrumSounds = map[string]string{
+	"kick": "bd", "snare": "sd", "electric_snare": "sd", "side_stick": "rim", "clap": "cp",
+	"closed_hihat": "hh", "pedal_hihat": "hh", "open_hihat": "oh", "ride": "ride",
+	"ride_bell": "ride", "crash": "cr", "splash": "cr", "china": "cr", "tambourine": "tb",
+	"cowbell": "cb", "tom_floor_low": "lt", "tom_floor_high": "lt", "tom_low": "lt",
+	"tom_low_mid": "mt", "tom_hi_mid": "mt", "tom_high": "ht",
+	"wood_block_high": "perc", "wood_block_low": "perc",
+}
+
 // drumPatternToStrudel converts a BTML drum pattern to Strudel
 func drumPatternToStrudel(pattern *parser.DrumPattern, sound string) string {
+	if name, ok := strudelDrumSounds[pattern.Sound]; ok {
+		sound = name
+	}
+
+	// Handle step pattern (X = accent, x = hit, . = rest)
+	if pattern.Pattern != "" {
+		var steps, gains []string
+		for _, char := range pattern.Pattern {
+			switch char {
+			case 'X':
+				steps = append(steps, sound)
+				gains = append(gains, "1")
+			case 'x':
+				steps = append(steps, sound)
+				gains = append(gains, "0.8")
+			default:
+				steps = append(steps, "~")
+				gains = append(gains, "0")
+			}
+		}
+		return fmt.Sprintf("s(\"%s\").gain(\"%s\")", strings.Join(steps, " "), strings.Join(gains, " "))
+	}
+
 	// Handle Euclidean rhythm
 	if pattern.Euclidean != nil {
 		return fmt.Sprintf("s(\"%s\").euclid(%d,%d,%d)",
@@ -0,0 +1,142 @@
+package strudel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"backing-tracks/midi"
+	"backing-tracks/parser"
+)
+
+// melodyStepTicks is the grid the melody is written on (sixteenth notes)
+const melodyStepTicks = 120
+
+// melodySounds maps BTML instrument names to Strudel General MIDI sounds
+var melodySounds = map[string]string{
+	"piano":          "piano",
+	"acoustic_piano": "piano",
+	"electric_piano": "gm_epiano1",
+	"nylon_guitar":   "gm_acoustic_guitar_nylon",
+	"steel_guitar":   "gm_acoustic_guitar_steel",
+	"jazz_guitar":    "gm_electric_guitar_jazz",
+	"clean_guitar":   "gm_electric_guitar_clean",
+	"muted_guitar":   "gm_electric_guitar_muted",
+	"overdrive":      "gm_overdriven_guitar",
+	"distortion":     "gm_distortion_guitar",
+	"violin":         "gm_violin",
+	"viola":          "gm_viola",
+	"cello":          "gm_cello",
+	"strings":        "gm_string_ensemble_1",
+	"trumpet":        "gm_trumpet",
+	"trombone":       "gm_trombone",
+	"french_horn":    "gm_french_horn",
+	"soprano_sax":    "gm_soprano_sax",
+	"alto_sax":       "gm_alto_sax",
+	"tenor_sax":      "gm_tenor_sax",
+	"baritone_sax":   "gm_baritone_sax",
+	"oboe":           "gm_oboe",
+	"clarinet":       "gm_clarinet",
+	"flute":          "gm_flute",
+	"pan_flute":      "gm_pan_flute",
+	"synth_lead":     "gm_lead_1_square",
+	"organ":          "gm_drawbar_organ",
+	"accordion":      "gm_accordion",
+	"harmonica":      "gm_harmonica",
+}
+
+// melodySound returns the Strudel sound for a melody instrument (default: steel guitar)
+func melodySound(instrument string) string {
+	if sound, ok := melodySounds[instrument]; ok {
+		return sound
+	}
+	return melodySounds["steel_guitar"]
+}
+
+// generateMelodyBars generates the melody and writes it as one Strudel
+// sequence per bar on a sixteenth-note grid (nil if the track has no melody)
+func generateMelodyBars(track *parser.Track) []string {
+	chords := track.Progression.GetChords()
+	if track.Melody == nil || len(chords) == 0 {
+		return nil
+	}
+
+	ticksPerBar := int(track.GetTimeSignature().TicksPerBar())
+	stepsPerBar := ticksPerBar / melodyStepTicks
+	numBars := 0
+	for _, chord := range chords {
+		numBars += int(chord.Bars*float64(ticksPerBar)+0.5) / ticksPerBar
+	}
+	if numBars == 0 || stepsPerBar == 0 {
+		return nil
+	}
+
+	config := midi.MelodyConfigFromTrack(track)
+	notes := midi.GenerateMelody(chords, track.Info.Key, track.Info.Style, config, uint32(ticksPerBar))
+	sort.Slice(notes, func(i, j int) bool { return notes[i].Tick < notes[j].Tick })
+
+	// Place each note on the grid; a note lasts until its end or the next note
+	grid := make([][]int, numBars) // MIDI note per step, -1 = rest, -2 = held
+	for bar := range grid {
+		grid[bar] = make([]int, stepsPerBar)
+		for step := range grid[bar] {
+			grid[bar][step] = -1
+		}
+	}
+	for _, n := range notes {
+		start := (int(n.Tick) + melodyStepTicks/2) / melodyStepTicks
+		bar, step := start/stepsPerBar, start%stepsPerBar
+		if bar >= numBars || grid[bar][step] >= 0 {
+			continue
+		}
+		length := max((int(n.Duration)+melodyStepTicks/2)/melodyStepTicks, 1)
+		grid[bar][step] = int(n.Note)
+		for s := step + 1; s < step+length && s < stepsPerBar && grid[bar][s] == -1; s++ {
+			grid[bar][s] = -2
+		}
+	}
+
+	bars := make([]string, numBars)
+	for bar, steps := range grid {
+		bars[bar] = "[" + melodyBarToStrudel(steps) + "]"
+	}
+
+	return bars
+}
+
+// melodyPattern plays melody bars one per cycle
+func melodyPattern(track *parser.Track, bars []string) string {
+	sound := "steel_guitar"
+	if track.Melody.Instrument != "" {
+		sound = track.Melody.Instrument
+	}
+	return fmt.Sprintf("note(\"<%s>\").s(\"%s\")", strings.Join(bars, " "), melodySound(sound))
+}
+
+// melodyBarToStrudel writes one bar of grid steps as "a4@2 ~@3 c5 ..."
+func melodyBarToStrudel(steps []int) string {
+	var tokens []string
+	for i := 0; i < len(steps); {
+		length := 1
+		if steps[i] >= 0 {
+			for i+length < len(steps) && steps[i+length] == -2 {
+				length++
+			}
+		} else {
+			for i+length < len(steps) && steps[i+length] < 0 {
+				length++
+			}
+		}
+
+		token := "~"
+		if steps[i] >= 0 {
+			token = midiToNote(steps[i]%12, steps[i]/12-1)
+		}
+		if length > 1 {
+			token = fmt.Sprintf("%s@%d", token, length)
+		}
+		tokens = append(tokens, token)
+		i += length
+	}
+	return strings.Join(tokens, " ")
+}
@@ -0,0 +1,113 @@
+package strudel
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"backing-tracks/parser"
+)
+
+// songSection is a run of consecutive chords belonging to one section of the form
+type songSection struct {
+	name     string
+	chords   []parser.Chord
+	startBar int
+}
+
+// splitSections groups the progression into sections by each chord's Section
+func splitSections(chords []parser.Chord) []songSection {
+	var sections []songSection
+	bar := 0
+	for _, chord := range chords {
+		if len(sections) == 0 || sections[len(sections)-1].name != chord.Section {
+			sections = append(sections, songSection{name: chord.Section, startBar: bar})
+		}
+		last := &sections[len(sections)-1]
+		last.chords = append(last.chords, chord)
+		bar += int(math.Ceil(chord.Bars))
+	}
+	return sections
+}
+
+// arrangeSections writes one stack per section and plays them in form order with arrange().
+// Sections with identical patterns (e.g. a repeated chorus without melody) share a variable.
+func arrangeSections(track *parser.Track, sections []songSection, melodyBars []string) string {
+	var sb strings.Builder
+
+	// Leading comment with the song form
+	form := make([]string, len(sections))
+	for i, section := range sections {
+		bars := chordsBars(section.chords)
+		form[i] = fmt.Sprintf("%s (bars %d-%d)", sectionTitle(section.name), section.startBar+1, section.startBar+int(math.Ceil(bars)))
+	}
+	sb.WriteString("// Form: " + strings.Join(form, " | ") + "\n\n")
+
+	varsByPattern := make(map[string]string)
+	usedNames := make(map[string]bool)
+	var parts []string
+
+	for _, section := range sections {
+		bars := chordsBars(section.chords)
+
+		// Slice the melody to the section's bars
+		var sectionMelody []string
+		if len(melodyBars) > 0 {
+			end := section.startBar + int(math.Ceil(bars))
+			if section.startBar < len(melodyBars) {
+				sectionMelody = melodyBars[section.startBar:min(end, len(melodyBars))]
+			}
+		}
+
+		pattern := stackLayers(generateLayers(track, section.chords, sectionMelody))
+		name, ok := varsByPattern[pattern]
+		if !ok {
+			name = uniqueVarName(sectionVarName(section.name), usedNames)
+			varsByPattern[pattern] = name
+			sb.WriteString(fmt.Sprintf("// %s\n", sectionTitle(section.name)))
+			sb.WriteString(fmt.Sprintf("const %s = %s\n\n", name, pattern))
+		}
+		parts = append(parts, fmt.Sprintf("  [%g, %s]", bars, name))
+	}
+
+	sb.WriteString("arrange(\n")
+	sb.WriteString(strings.Join(parts, ",\n"))
+	sb.WriteString("\n)")
+	return sb.String()
+}
+
+// sectionTitle returns the display name of a section ("Intro" for chords before the first section)
+func sectionTitle(name string) string {
+	if name == "" {
+		return "Intro"
+	}
+	return name
+}
+
+// sectionVarName converts a section name to a JavaScript identifier ("Chorus 2" -> "chorus_2")
+func sectionVarName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(sectionTitle(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune('_')
+		}
+	}
+	ident := sb.String()
+	if ident == "" || unicode.IsDigit(rune(ident[0])) {
+		ident = "section_" + ident
+	}
+	return ident
+}
+
+// uniqueVarName returns name, or name2, name3... if it is already taken
+func uniqueVarName(name string, used map[string]bool) string {
+	candidate := name
+	for n := 2; used[candidate]; n++ {
+		candidate = fmt.Sprintf("%s%d", name, n)
+	}
+	used[candidate] = true
+	return candidate
+}
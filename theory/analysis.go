@@ -0,0 +1,205 @@
+package theory
+
+import (
+	"sort"
+	"strings"
+)
+
+// Analysis names what each chord of a progression does in a key: diatonic chords
+// are built from the key's scale, and the common ways outside it are labeled
+// (secondary dominants, chords borrowed from the parallel key, the blues I7/IV7,
+// the Neapolitan). A chord nothing explains is often a typo.
+
+// ChordFunction is a chord's place in a key
+type ChordFunction struct {
+	Numeral  string // Roman numeral in the key, e.g. "bVII"
+	Diatonic bool   // Every tone is in the key's scale
+	Label    string // How the chord is explained, e.g. "borrowed from C minor" ("diatonic" when it is)
+}
+
+// KeyFit is how well a progression fits a key
+type KeyFit struct {
+	Key      string
+	Diatonic float64 // Share of the bars (0-1) on chords diatonic to the key
+	Tonic    bool    // The progression starts or ends on the key's tonic chord
+}
+
+// AnalyzeChord describes a chord in key. A symbol that isn't a chord gets no
+// numeral and the label "not a chord".
+func AnalyzeChord(symbol, key string) ChordFunction {
+	if _, _, ok := ChordDegree(symbol, key); !ok {
+		return ChordFunction{Label: notAChordLabel}
+	}
+	function := ChordFunction{Numeral: RomanNumeral(symbol, key)}
+
+	keyRoot, isMinor := ParseKey(key)
+	tones := symbolTones(symbol)
+	chordRoot := parseChordRoot(symbol)
+	interval := (chordRoot - keyRoot + 12) % 12
+	dominant := sameTones(tones, chordRoot, 0, 4, 7) || sameTones(tones, chordRoot, 0, 4, 7, 10)
+
+	switch {
+	case inScale(tones, keyRoot, scaleDegrees(isMinor)):
+		function.Diatonic = true
+		function.Label = "diatonic"
+	case isMinor && inScale(tones, keyRoot, harmonicMinorDegrees):
+		function.Label = "from harmonic minor (raised 7th)"
+	case !isMinor && (interval == 0 || interval == 5) && sameTones(tones, chordRoot, 0, 4, 7, 10):
+		function.Label = "blues dominant seventh"
+	case dominant && secondaryTarget(chordRoot, key) != "":
+		function.Label = "secondary dominant (V/" + secondaryTarget(chordRoot, key) + ")"
+	case inScale(tones, keyRoot, scaleDegrees(!isMinor)):
+		function.Label = "borrowed from " + parallelKeyName(key, isMinor)
+	case interval == 1 && sameTones(tones, chordRoot, 0, 4, 7):
+		function.Label = "Neapolitan"
+	default:
+		function.Label = outsideKeyLabel
+	}
+	return function
+}
+
+// Labels of chords the key doesn't explain
+const (
+	notAChordLabel  = "not a chord"
+	outsideKeyLabel = "outside the key"
+)
+
+// Explained reports whether the chord is diatonic or one of the labeled ways out of
+// the key
+func (f ChordFunction) Explained() bool {
+	return f.Label != notAChordLabel && f.Label != outsideKeyLabel
+}
+
+// harmonicMinorDegrees is the natural minor scale with a raised 7th
+var harmonicMinorDegrees = []int{0, 2, 3, 5, 7, 8, 11}
+
+// scaleDegrees returns the degrees of the major or natural minor scale
+func scaleDegrees(minor bool) []int {
+	if minor {
+		return minorDegrees
+	}
+	return majorDegrees
+}
+
+// symbolTones returns a chord's pitch classes, with the bass note of a slash chord
+func symbolTones(symbol string) []int {
+	tones := GetChordTones(symbol)
+	if idx := slashBassIndex(symbol); idx > 0 {
+		tones = append(tones, NoteToMidi(symbol[idx+1:]))
+	}
+	return tones
+}
+
+// inScale reports whether every tone is on one of the degrees above root
+func inScale(tones []int, root int, degrees []int) bool {
+	for _, tone := range tones {
+		found := false
+		for _, degree := range degrees {
+			if (root+degree)%12 == tone {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// sameTones reports whether tones are exactly the intervals above root
+func sameTones(tones []int, root int, intervals ...int) bool {
+	want := make(map[int]bool, len(intervals))
+	for _, interval := range intervals {
+		want[(root+interval)%12] = true
+	}
+	have := make(map[int]bool, len(tones))
+	for _, tone := range tones {
+		if !want[tone] {
+			return false
+		}
+		have[tone] = true
+	}
+	return len(have) == len(want)
+}
+
+// secondaryTarget returns the numeral of the diatonic chord a fifth below root that
+// a dominant on root would resolve to (e.g. "V" for D7 in C), or "" when there is
+// none: the tonic (that dominant is V itself) and diminished chords don't count
+func secondaryTarget(root int, key string) string {
+	target := (root + 5) % 12
+	keyRoot, _ := ParseKey(key)
+	if target == keyRoot {
+		return ""
+	}
+	for _, chord := range DiatonicChords(key) {
+		if parseChordRoot(chord.Triad) == target && !strings.HasSuffix(chord.Triad, "dim") {
+			return chord.Numeral
+		}
+	}
+	return ""
+}
+
+// parallelKeyName names the key with the same tonic and the other mode ("C minor")
+func parallelKeyName(key string, isMinor bool) string {
+	tonic := strings.TrimSuffix(key, "m")
+	if isMinor {
+		return tonic + " major"
+	}
+	return tonic + " minor"
+}
+
+// FitKeys scores a progression (its chord symbols in order and their lengths in
+// bars) against the 24 major and minor keys, best first. Keys that fit equally well
+// are ordered by whether the progression starts or ends on their tonic chord.
+func FitKeys(chords []string, bars []float64) []KeyFit {
+	var total float64
+	for _, length := range bars {
+		total += length
+	}
+
+	var fits []KeyFit
+	for root := 0; root < 12; root++ {
+		for _, minor := range []bool{false, true} {
+			key := NoteNamesFlat[root]
+			if minor {
+				key += "m"
+			}
+			if !KeyUsesFlats(key) {
+				key = NoteNames[root] + strings.TrimPrefix(key, NoteNamesFlat[root])
+			}
+
+			var diatonic float64
+			for i, chord := range chords {
+				if AnalyzeChord(chord, key).Diatonic {
+					diatonic += bars[i]
+				}
+			}
+			fit := KeyFit{Key: key}
+			if total > 0 {
+				fit.Diatonic = diatonic / total
+			}
+			fit.Tonic = len(chords) > 0 && (isTonicChord(chords[0], root, minor) || isTonicChord(chords[len(chords)-1], root, minor))
+			fits = append(fits, fit)
+		}
+	}
+
+	sort.SliceStable(fits, func(i, j int) bool {
+		if fits[i].Diatonic != fits[j].Diatonic {
+			return fits[i].Diatonic > fits[j].Diatonic
+		}
+		return fits[i].Tonic && !fits[j].Tonic
+	})
+	return fits
+}
+
+// isTonicChord reports whether a chord is the tonic triad (or seventh) of the key
+// on root
+func isTonicChord(symbol string, root int, minor bool) bool {
+	if _, _, ok := ChordDegree(symbol, NoteNames[root]); !ok || parseChordRoot(symbol) != root {
+		return false
+	}
+	quality := chordQuality(symbol)
+	isMinorChord := strings.HasPrefix(quality, "m") && !strings.HasPrefix(quality, "maj")
+	return isMinorChord == minor
+}
@@ -0,0 +1,169 @@
+package theory
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// Diatonic chords are built by stacking thirds on each degree of the key's scale
+// (major, or natural minor for minor keys).
+
+// DiatonicChord is the chord on one scale degree of a key
+type DiatonicChord struct {
+	Numeral string // Roman numeral of the triad, e.g. "ii" or "vii°"
+	Triad   string // Triad symbol, e.g. "Dm"
+	Seventh string // Seventh chord symbol, e.g. "Dm7"
+}
+
+// Progression is a common chord progression, as 0-based scale degrees
+type Progression struct {
+	Name     string
+	Degrees  []int
+	Sevenths bool // Played with seventh chords (ii-V-I)
+}
+
+// Common progressions for major and minor keys
+var (
+	MajorProgressions = []Progression{
+		{Name: "I-IV-V", Degrees: []int{0, 3, 4}},
+		{Name: "ii-V-I", Degrees: []int{1, 4, 0}, Sevenths: true},
+		{Name: "I-V-vi-IV", Degrees: []int{0, 4, 5, 3}},
+		{Name: "vi-IV-I-V", Degrees: []int{5, 3, 0, 4}},
+		{Name: "I-vi-IV-V", Degrees: []int{0, 5, 3, 4}},
+	}
+	MinorProgressions = []Progression{
+		{Name: "i-iv-v", Degrees: []int{0, 3, 4}},
+		{Name: "ii°-v-i", Degrees: []int{1, 4, 0}, Sevenths: true},
+		{Name: "i-VI-III-VII", Degrees: []int{0, 5, 2, 6}},
+		{Name: "i-VII-VI-VII", Degrees: []int{0, 6, 5, 6}},
+		{Name: "i-iv-VII-III", Degrees: []int{0, 3, 6, 2}},
+	}
+)
+
+// DiatonicChords returns the seven triads and seventh chords of key, from the tonic
+// up, spelled with flats in flat keys
+func DiatonicChords(key string) []DiatonicChord {
+	root, isMinor := ParseKey(key)
+	degrees := majorDegrees
+	if isMinor {
+		degrees = minorDegrees
+	}
+	names := NoteNames
+	if KeyUsesFlats(key) || strings.Contains(strings.TrimSuffix(key, "m"), "b") {
+		names = NoteNamesFlat
+	}
+
+	// interval returns the semitones from degree i up to the degree steps above it
+	interval := func(i, steps int) int {
+		return (degrees[(i+steps)%7] - degrees[i] + 12) % 12
+	}
+
+	chords := make([]DiatonicChord, 7)
+	for i := range degrees {
+		var triad, seventh string
+		third, fifth, sev := interval(i, 2), interval(i, 4), interval(i, 6)
+		switch {
+		case third == 4 && sev == 11:
+			triad, seventh = "", "maj7"
+		case third == 4:
+			triad, seventh = "", "7"
+		case fifth == 6:
+			triad, seventh = "dim", "m7b5"
+		default:
+			triad, seventh = "m", "m7"
+		}
+
+		name := names[(root+degrees[i])%12]
+		chords[i] = DiatonicChord{
+			Numeral: RomanNumeral(name+triad, key),
+			Triad:   name + triad,
+			Seventh: name + seventh,
+		}
+	}
+	return chords
+}
+
+// KeyProgressions returns the common progressions for key (major or minor)
+func KeyProgressions(key string) []Progression {
+	if _, isMinor := ParseKey(key); isMinor {
+		return MinorProgressions
+	}
+	return MajorProgressions
+}
+
+// Chords returns the progression's chord symbols in key
+func (p Progression) Chords(key string) []string {
+	diatonic := DiatonicChords(key)
+	var chords []string
+	for _, degree := range p.Degrees {
+		if p.Sevenths {
+			chords = append(chords, diatonic[degree].Seventh)
+		} else {
+			chords = append(chords, diatonic[degree].Triad)
+		}
+	}
+	return chords
+}
+
+// progressionMoves weights the next scale degree after each degree (0-based), for
+// generated progressions: strong moves (IV-V, V-I, vi-IV) weigh most, and the
+// diminished chord is only left, never chosen
+var progressionMoves = map[bool][7][7]int{
+	false: { // Major: I ii iii IV V vi vii°
+		{0, 2, 1, 3, 3, 3, 0},
+		{0, 0, 0, 1, 5, 0, 0},
+		{0, 0, 0, 2, 0, 3, 0},
+		{3, 1, 0, 0, 3, 1, 0},
+		{4, 0, 0, 1, 0, 2, 0},
+		{0, 2, 1, 3, 2, 0, 0},
+		{3, 0, 1, 0, 0, 0, 0},
+	},
+	true: { // Minor: i ii° III iv v VI VII
+		{0, 0, 1, 3, 2, 3, 3},
+		{0, 0, 0, 0, 3, 0, 0},
+		{0, 0, 0, 2, 0, 2, 2},
+		{2, 0, 0, 0, 2, 0, 2},
+		{4, 0, 0, 0, 0, 2, 0},
+		{0, 0, 2, 2, 0, 0, 3},
+		{3, 0, 3, 0, 0, 1, 0},
+	},
+}
+
+// GenerateProgression returns a progression of the given number of chords in key,
+// starting on the tonic and ending on the fifth degree so it loops back. Each next
+// chord is drawn from progressionMoves with rng; sevenths picks seventh chords.
+func GenerateProgression(key string, length int, sevenths bool, rng *rand.Rand) []string {
+	_, isMinor := ParseKey(key)
+	moves := progressionMoves[isMinor]
+	diatonic := DiatonicChords(key)
+
+	degrees := []int{0}
+	for len(degrees) < length-1 {
+		weights := moves[degrees[len(degrees)-1]]
+		total := 0
+		for _, w := range weights {
+			total += w
+		}
+		pick := rng.Intn(total)
+		for next, w := range weights {
+			if pick < w {
+				degrees = append(degrees, next)
+				break
+			}
+			pick -= w
+		}
+	}
+	if length > 1 {
+		degrees = append(degrees, 4) // Turnaround on V (v in minor)
+	}
+
+	var chords []string
+	for _, degree := range degrees {
+		if sevenths {
+			chords = append(chords, diatonic[degree].Seventh)
+		} else {
+			chords = append(chords, diatonic[degree].Triad)
+		}
+	}
+	return chords
+}
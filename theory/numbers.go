@@ -0,0 +1,133 @@
+package theory
+
+import (
+	"strings"
+)
+
+// Chord numbers name chords by their scale degree in a key, so a chart reads the
+// same in any key: Nashville numbers (1, 4, 5, 6m) and Roman numerals (I, IV, V, vi).
+
+// Degrees of the major and natural minor scales, in semitones from the tonic
+var (
+	majorDegrees = []int{0, 2, 4, 5, 7, 9, 11}
+	minorDegrees = []int{0, 2, 3, 5, 7, 8, 10}
+)
+
+var romanNumerals = []string{"I", "II", "III", "IV", "V", "VI", "VII"}
+
+// superscripts turns the extension digits of a Nashville number into superscripts (5⁷)
+var superscripts = strings.NewReplacer(
+	"0", "⁰", "1", "¹", "2", "²", "3", "³", "4", "⁴",
+	"5", "⁵", "6", "⁶", "7", "⁷", "8", "⁸", "9", "⁹",
+)
+
+// ChordDegree returns the scale degree (1-7) of a chord's root in key, with the
+// accidental needed when the root is outside the key's scale ("b" for b7, "#"
+// when the flat spelling would be b1 or b4). ok is false for an unparsable chord.
+func ChordDegree(symbol, key string) (accidental string, degree int, ok bool) {
+	if symbol == "" || !isNoteLetter(strings.ToUpper(symbol)[0]) {
+		return "", 0, false
+	}
+	return pitchDegree(parseChordRoot(strings.ToUpper(symbol[:1])+symbol[1:]), key)
+}
+
+// pitchDegree returns the scale degree of a pitch class (0-11) in key
+func pitchDegree(pitch int, key string) (string, int, bool) {
+	keyRoot, isMinor := ParseKey(key)
+	degrees := majorDegrees
+	if isMinor {
+		degrees = minorDegrees
+	}
+
+	interval := (pitch - keyRoot + 12) % 12
+	for i, d := range degrees {
+		if d == interval {
+			return "", i + 1, true
+		}
+	}
+	for i, d := range degrees {
+		if d == (interval+1)%12 && i != 0 && i != 3 {
+			return "b", i + 1, true
+		}
+	}
+	for i, d := range degrees {
+		if d == (interval+11)%12 {
+			return "#", i + 1, true
+		}
+	}
+	return "", 0, false
+}
+
+// NashvilleNumber returns a chord as a Nashville number in key: the degree plus the
+// chord's quality ("Am" in C = "6m", "G7" = "5⁷", "C/E" = "1/3"). Chords that
+// can't be parsed are returned unchanged.
+func NashvilleNumber(symbol, key string) string {
+	chord, bass, hasBass := strings.Cut(symbol, "/")
+	accidental, degree, ok := ChordDegree(chord, key)
+	if !ok {
+		return symbol
+	}
+
+	quality := chordSuffix(chord)
+	if len(quality) > 0 && quality[0] >= '0' && quality[0] <= '9' {
+		quality = superscripts.Replace(quality)
+	}
+	number := accidental + string(rune('0'+degree)) + quality
+	return number + bassDegree(bass, key, hasBass)
+}
+
+// RomanNumeral returns a chord as a Roman numeral in key: uppercase for major,
+// lowercase for minor and diminished chords ("Am" in C = "vi", "G7" = "V7",
+// "Bdim" = "vii°", "Bm7b5" = "viiø7"). Chords that can't be parsed are returned unchanged.
+func RomanNumeral(symbol, key string) string {
+	chord, bass, hasBass := strings.Cut(symbol, "/")
+	accidental, degree, ok := ChordDegree(chord, key)
+	if !ok {
+		return symbol
+	}
+
+	numeral := romanNumerals[degree-1]
+	quality := chordSuffix(chord)
+	lower := strings.ToLower(quality)
+	switch {
+	case isHalfDiminished(lower):
+		numeral = strings.ToLower(numeral)
+		quality = "ø7"
+	case strings.HasPrefix(lower, "dim") || strings.HasPrefix(quality, "°") || strings.HasPrefix(quality, "o"):
+		numeral = strings.ToLower(numeral)
+		quality = "°" + strings.TrimLeft(strings.TrimPrefix(strings.TrimPrefix(lower, "dim"), "o"), "°")
+	case strings.HasPrefix(lower, "min"):
+		numeral = strings.ToLower(numeral)
+		quality = quality[3:]
+	case strings.HasPrefix(quality, "m") && !strings.HasPrefix(lower, "maj"):
+		numeral = strings.ToLower(numeral)
+		quality = quality[1:]
+	case strings.HasPrefix(lower, "aug"):
+		quality = "+" + quality[3:]
+	}
+	return accidental + numeral + quality + bassDegree(bass, key, hasBass)
+}
+
+// chordSuffix returns the chord symbol without its root ("Bbm7" -> "m7")
+func chordSuffix(chord string) string {
+	if len(chord) > 1 && (chord[1] == '#' || chord[1] == 'b') {
+		return chord[2:]
+	}
+	if len(chord) > 0 {
+		return chord[1:]
+	}
+	return ""
+}
+
+// bassDegree returns "/3"-style slash notation for a slash chord's bass note
+// (the note's degree in key, in both notations), or "" without one
+func bassDegree(bass, key string, hasBass bool) string {
+	if !hasBass || bass == "" {
+		return ""
+	}
+	accidental, degree, ok := ChordDegree(bass, key)
+	if !ok {
+		return "/" + bass
+	}
+	return "/" + accidental + string(rune('0'+degree))
+}
@@ -0,0 +1,86 @@
+package theory
+
+import (
+	"strings"
+)
+
+// Skill levels select how chords are voiced and which diagrams are shown
+const (
+	SkillBeginner     = "beginner"
+	SkillIntermediate = "intermediate"
+	SkillAdvanced     = "advanced"
+)
+
+// beginnerSubstitutes maps hard (barre) chords to easier equivalents
+var beginnerSubstitutes = map[string]string{
+	"F":  "Fmaj7", // xx3210 instead of the full barre
+	"Bm": "Bm7",   // x20202 instead of the barre
+	"B":  "B7",    // x21202 open shape
+}
+
+// ParseSkill normalizes a skill level name, defaulting to intermediate
+func ParseSkill(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "beginner", "easy":
+		return SkillBeginner
+	case "advanced", "pro":
+		return SkillAdvanced
+	default:
+		return SkillIntermediate
+	}
+}
+
+// SimplifyChordForSkill returns the chord a player of the given skill should play.
+// Beginners get the slash bass and extensions removed (9, 11, 13, 6, add9 -> triad or 7th,
+// m(maj7) -> minor triad) and a few barre chords replaced with open substitutes.
+// Other levels are unchanged.
+func SimplifyChordForSkill(symbol, skill string) string {
+	if ParseSkill(skill) != SkillBeginner || symbol == "" {
+		return symbol
+	}
+
+	// Drop slash bass note
+	if idx := strings.Index(symbol, "/"); idx > 0 {
+		symbol = symbol[:idx]
+	}
+
+	// Split root from quality
+	rootLen := 1
+	if len(symbol) > 1 && (symbol[1] == '#' || symbol[1] == 'b') {
+		rootLen = 2
+	}
+	root, quality := symbol[:rootLen], symbol[rootLen:]
+
+	isMinor := strings.HasPrefix(quality, "m") && !strings.HasPrefix(quality, "maj")
+	switch {
+	case strings.HasPrefix(quality, "maj"):
+		quality = "maj7"
+	case strings.Contains(quality, "sus"), strings.Contains(quality, "dim"), strings.Contains(quality, "aug"),
+		strings.HasPrefix(quality, "+"), strings.HasPrefix(quality, "o"), strings.HasPrefix(quality, "°"),
+		isHalfDiminished(strings.ToLower(quality)):
+		// Keep sus/dim/aug/half-dim - simplifying would change the harmony
+	case isMinor && (strings.Contains(quality, "maj") || strings.Contains(quality, "M")):
+		// Minor-major 7th (m(maj7), mM7): the minor triad, not a minor 7th
+		quality = "m"
+	case strings.Contains(quality, "7") || strings.Contains(quality, "9") ||
+		strings.Contains(quality, "11") || strings.Contains(quality, "13"):
+		if strings.Contains(quality, "add") {
+			quality = ""
+		} else {
+			quality = "7"
+		}
+		if isMinor {
+			quality = "m" + quality
+		}
+	case isMinor:
+		quality = "m"
+	default:
+		quality = ""
+	}
+
+	simplified := root + quality
+	if sub, ok := beginnerSubstitutes[simplified]; ok {
+		return sub
+	}
+	return simplified
+}
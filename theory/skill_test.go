@@ -0,0 +1,53 @@
+package theory
+
+import "testing"
+
+func TestSimplifyChordForSkill(t *testing.T) {
+	for _, tc := range []struct {
+		symbol, want string
+	}{
+		{"C", "C"},
+		{"Am", "Am"},
+		{"G7", "G7"},
+		{"D/F#", "D"},
+		{"Cmaj9", "Cmaj7"},
+		{"Am9", "Am7"},
+		{"G13", "G7"},
+		{"Cadd9", "C"},
+		{"Em11", "Em7"},
+		{"Bbmaj7", "Bbmaj7"},
+		{"Dsus4", "Dsus4"},
+		{"Bdim", "Bdim"},
+		{"C+", "C+"},
+		{"Caug", "Caug"},
+		{"G+7", "G+7"},
+		{"Cm(maj7)", "Cm"},
+		{"AmM7", "Am"},
+		{"Ebm(maj7)/Bb", "Ebm"},
+		{"F", "Fmaj7"},
+		{"Bm", "Bm7"},
+		{"B", "B7"},
+		{"F/C", "Fmaj7"},
+	} {
+		if got := SimplifyChordForSkill(tc.symbol, SkillBeginner); got != tc.want {
+			t.Errorf("SimplifyChordForSkill(%q, beginner) = %q, want %q", tc.symbol, got, tc.want)
+		}
+		for _, skill := range []string{SkillIntermediate, SkillAdvanced, ""} {
+			if got := SimplifyChordForSkill(tc.symbol, skill); got != tc.symbol {
+				t.Errorf("SimplifyChordForSkill(%q, %q) = %q, want it unchanged", tc.symbol, skill, got)
+			}
+		}
+	}
+}
+
+func TestParseSkill(t *testing.T) {
+	for input, want := range map[string]string{
+		"beginner": SkillBeginner, " Easy ": SkillBeginner,
+		"advanced": SkillAdvanced, "PRO": SkillAdvanced,
+		"intermediate": SkillIntermediate, "": SkillIntermediate, "expert": SkillIntermediate,
+	} {
+		if got := ParseSkill(input); got != want {
+			t.Errorf("ParseSkill(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -16,18 +16,26 @@ const (
 	ScaleDorian          ScaleType = "dorian"
 	ScaleMixolydian      ScaleType = "mixolydian"
 	ScaleHarmonicMinor   ScaleType = "harmonic_minor"
+	ScaleLocrian         ScaleType = "locrian"
 )
 
+// ScaleTypes lists the scale types in display order
+var ScaleTypes = []ScaleType{
+	ScalePentatonicMinor, ScalePentatonicMajor, ScaleBlues, ScaleNaturalMinor, ScaleNaturalMajor,
+	ScaleDorian, ScaleMixolydian, ScaleHarmonicMinor, ScaleLocrian,
+}
+
 // ScaleIntervals maps scale types to their interval patterns (semitones from root)
 var ScaleIntervals = map[ScaleType][]int{
-	ScalePentatonicMinor: {0, 3, 5, 7, 10},           // R, b3, 4, 5, b7
-	ScalePentatonicMajor: {0, 2, 4, 7, 9},            // R, 2, 3, 5, 6
-	ScaleBlues:           {0, 3, 5, 6, 7, 10},        // R, b3, 4, b5, 5, b7
-	ScaleNaturalMinor:    {0, 2, 3, 5, 7, 8, 10},     // R, 2, b3, 4, 5, b6, b7
-	ScaleNaturalMajor:    {0, 2, 4, 5, 7, 9, 11},     // R, 2, 3, 4, 5, 6, 7
-	ScaleDorian:          {0, 2, 3, 5, 7, 9, 10},     // R, 2, b3, 4, 5, 6, b7
-	ScaleMixolydian:      {0, 2, 4, 5, 7, 9, 10},     // R, 2, 3, 4, 5, 6, b7
-	ScaleHarmonicMinor:   {0, 2, 3, 5, 7, 8, 11},     // R, 2, b3, 4, 5, b6, 7
+	ScalePentatonicMinor: {0, 3, 5, 7, 10},       // R, b3, 4, 5, b7
+	ScalePentatonicMajor: {0, 2, 4, 7, 9},        // R, 2, 3, 5, 6
+	ScaleBlues:           {0, 3, 5, 6, 7, 10},    // R, b3, 4, b5, 5, b7
+	ScaleNaturalMinor:    {0, 2, 3, 5, 7, 8, 10}, // R, 2, b3, 4, 5, b6, b7
+	ScaleNaturalMajor:    {0, 2, 4, 5, 7, 9, 11}, // R, 2, 3, 4, 5, 6, 7
+	ScaleDorian:          {0, 2, 3, 5, 7, 9, 10}, // R, 2, b3, 4, 5, 6, b7
+	ScaleMixolydian:      {0, 2, 4, 5, 7, 9, 10}, // R, 2, 3, 4, 5, 6, b7
+	ScaleHarmonicMinor:   {0, 2, 3, 5, 7, 8, 11}, // R, 2, b3, 4, 5, b6, 7
+	ScaleLocrian:         {0, 1, 3, 5, 6, 8, 10}, // R, b2, b3, 4, b5, b6, b7
 }
 
 // ScaleNames maps scale types to display names
@@ -40,6 +48,7 @@ var ScaleNames = map[ScaleType]string{
 	ScaleDorian:          "Dorian",
 	ScaleMixolydian:      "Mixolydian",
 	ScaleHarmonicMinor:   "Harmonic Minor",
+	ScaleLocrian:         "Locrian",
 }
 
 // NoteNames for display (sharps)
@@ -63,25 +72,34 @@ type Tuning struct {
 // Tunings maps tuning names to their configurations
 var Tunings = map[string]Tuning{
 	// Standard and drop tunings
-	"standard": {[]int{40, 45, 50, 55, 59, 64}, []string{"E", "A", "D", "G", "B", "e"}},
-	"drop_d":   {[]int{38, 45, 50, 55, 59, 64}, []string{"D", "A", "D", "G", "B", "e"}},
-	"drop_c":   {[]int{36, 43, 48, 53, 57, 62}, []string{"C", "G", "C", "F", "A", "d"}},
-	"d_standard": {[]int{38, 43, 48, 53, 57, 62}, []string{"D", "G", "C", "F", "A", "d"}},  // All strings down 1 whole step
+	"standard":    {[]int{40, 45, 50, 55, 59, 64}, []string{"E", "A", "D", "G", "B", "e"}},
+	"drop_d":      {[]int{38, 45, 50, 55, 59, 64}, []string{"D", "A", "D", "G", "B", "e"}},
+	"drop_c":      {[]int{36, 43, 48, 53, 57, 62}, []string{"C", "G", "C", "F", "A", "d"}},
+	"d_standard":  {[]int{38, 43, 48, 53, 57, 62}, []string{"D", "G", "C", "F", "A", "d"}},       // All strings down 1 whole step
 	"eb_standard": {[]int{39, 44, 49, 54, 58, 63}, []string{"Eb", "Ab", "Db", "Gb", "Bb", "eb"}}, // All strings down 1/2 step
 
 	// Open tunings
-	"open_e": {[]int{40, 47, 52, 56, 59, 64}, []string{"E", "B", "E", "G#", "B", "e"}},  // Open E major
-	"open_d": {[]int{38, 45, 50, 54, 57, 62}, []string{"D", "A", "D", "F#", "A", "d"}},  // Open D major
-	"open_g": {[]int{38, 43, 50, 55, 59, 62}, []string{"D", "G", "D", "G", "B", "d"}},   // Open G major (Keith Richards)
-	"open_a": {[]int{40, 45, 52, 57, 61, 64}, []string{"E", "A", "E", "A", "C#", "e"}},  // Open A major
+	"open_e": {[]int{40, 47, 52, 56, 59, 64}, []string{"E", "B", "E", "G#", "B", "e"}}, // Open E major
+	"open_d": {[]int{38, 45, 50, 54, 57, 62}, []string{"D", "A", "D", "F#", "A", "d"}}, // Open D major
+	"open_g": {[]int{38, 43, 50, 55, 59, 62}, []string{"D", "G", "D", "G", "B", "d"}},  // Open G major (Keith Richards)
+	"open_a": {[]int{40, 45, 52, 57, 61, 64}, []string{"E", "A", "E", "A", "C#", "e"}}, // Open A major
 
 	// Modal/Celtic tunings
-	"dadgad": {[]int{38, 45, 50, 55, 57, 62}, []string{"D", "A", "D", "G", "A", "d"}},   // DADGAD (Celtic)
-	"dadgbd": {[]int{38, 45, 50, 55, 59, 62}, []string{"D", "A", "D", "G", "B", "d"}},   // Double drop D
+	"dadgad": {[]int{38, 45, 50, 55, 57, 62}, []string{"D", "A", "D", "G", "A", "d"}}, // DADGAD (Celtic)
+	"dadgbd": {[]int{38, 45, 50, 55, 59, 62}, []string{"D", "A", "D", "G", "B", "d"}}, // Double drop D
 
 	// Other tunings
-	"open_c": {[]int{36, 43, 48, 55, 60, 64}, []string{"C", "G", "C", "G", "C", "e"}},   // Open C
+	"open_c":    {[]int{36, 43, 48, 55, 60, 64}, []string{"C", "G", "C", "G", "C", "e"}}, // Open C
 	"nashville": {[]int{52, 57, 62, 67, 71, 76}, []string{"e", "a", "d", "g", "b", "e"}}, // Nashville (high strung)
+	// A 12-string's courses are tuned like standard (octave/unison pairs), so it uses "standard"
+
+	// Extended range (chord shapes use the top six strings, see ChordStrings)
+	"seven_string": {[]int{35, 40, 45, 50, 55, 59, 64}, []string{"B", "E", "A", "D", "G", "B", "e"}}, // 7-string, low B added
+
+	// Other instruments (fewer than 6 strings)
+	"ukulele":  {[]int{67, 60, 64, 69}, []string{"G", "C", "E", "a"}}, // GCEA, re-entrant high G
+	"bass_4":   {[]int{28, 33, 38, 43}, []string{"E", "A", "D", "G"}}, // 4-string bass
+	"mandolin": {[]int{55, 62, 69, 76}, []string{"G", "D", "A", "e"}}, // GDAE in fifths (each course a unison pair)
 }
 
 // TuningNames is an ordered list of tuning names for cycling through
@@ -99,6 +117,21 @@ var TuningNames = []string{
 	"dadgbd",
 	"open_c",
 	"nashville",
+	"seven_string",
+	"ukulele",
+	"bass_4",
+	"mandolin",
+}
+
+// ChordStrings returns the strings chord shapes are played on: the six highest
+// strings of tunings with more (a 7-string's low B is left to riffs and scales),
+// else all of them. Voicings and chord diagrams are six strings wide ([6]int), so
+// string 0 of a voicing is string 0 of ChordStrings, not of the full tuning.
+func ChordStrings(tuning Tuning) Tuning {
+	if n := len(tuning.Notes); n > 6 {
+		return Tuning{Notes: tuning.Notes[n-6:], Names: tuning.Names[n-6:]}
+	}
+	return tuning
 }
 
 // GetTuning returns a tuning by name, defaulting to standard if not found
@@ -112,6 +145,14 @@ func GetTuning(name string) Tuning {
 	return Tunings["standard"]
 }
 
+// RegisterTuning adds or replaces a named tuning and makes it available for cycling
+func RegisterTuning(name string, tuning Tuning) {
+	if _, exists := Tunings[name]; !exists {
+		TuningNames = append(TuningNames, name)
+	}
+	Tunings[name] = tuning
+}
+
 // GetTuningIndex returns the index of a tuning name in TuningNames, or 0 if not found
 func GetTuningIndex(name string) int {
 	if name == "" {
@@ -284,6 +325,16 @@ func GetScaleForStyle(key string, style string, currentChord string) *Scale {
 	}
 }
 
+// GetScaleWithOverride returns the named scale on the key's root if scaleName is set,
+// otherwise the style-based scale from GetScaleForStyle
+func GetScaleWithOverride(key, style, currentChord, scaleName string) *Scale {
+	if strings.TrimSpace(scaleName) != "" {
+		root, _ := ParseKey(key)
+		return NewScale(root, ScaleTypeFromString(scaleName))
+	}
+	return GetScaleForStyle(key, style, currentChord)
+}
+
 // getJazzScaleForChord returns appropriate jazz scale for a chord
 func getJazzScaleForChord(chordSymbol string, keyRoot int, keyIsMinor bool) *Scale {
 	chordSymbol = strings.TrimSpace(chordSymbol)
@@ -300,6 +351,10 @@ func getJazzScaleForChord(chordSymbol string, keyRoot int, keyIsMinor bool) *Sca
 
 	// Determine scale based on chord quality
 	switch {
+	case isHalfDiminished(chordQuality(chordSymbol)):
+		// Half-diminished (m7b5): Locrian
+		return NewScale(chordRoot, ScaleLocrian)
+
 	case strings.Contains(quality, "maj7") || strings.Contains(quality, "maj9"):
 		// Major 7th: Lydian or Major
 		return NewScale(chordRoot, ScaleNaturalMajor)
@@ -326,6 +381,40 @@ func getJazzScaleForChord(chordSymbol string, keyRoot int, keyIsMinor bool) *Sca
 	}
 }
 
+// chordQuality returns the lowercase chord quality with the root and slash bass removed
+// ("Cmaj7/E" -> "maj7", "Bbm9" -> "m9")
+func chordQuality(chordSymbol string) string {
+	quality := chordSymbol
+	if idx := slashBassIndex(quality); idx > 0 {
+		quality = quality[:idx]
+	}
+	if len(quality) > 1 && (quality[1] == '#' || quality[1] == 'b') {
+		quality = quality[2:]
+	} else if len(quality) > 0 {
+		quality = quality[1:]
+	}
+	return strings.ToLower(quality)
+}
+
+// slashBassIndex returns the index of the slash before a bass note (C/E, D/F#),
+// or -1; the slash in a 6/9 chord is part of the quality
+func slashBassIndex(chordSymbol string) int {
+	idx := strings.Index(chordSymbol, "/")
+	if idx <= 0 || idx == len(chordSymbol)-1 {
+		return -1
+	}
+	if bass := chordSymbol[idx+1]; bass < 'A' || bass > 'G' {
+		return -1
+	}
+	return idx
+}
+
+// isHalfDiminished reports whether a chord quality is half-diminished (m7b5, ø)
+func isHalfDiminished(quality string) bool {
+	return strings.Contains(quality, "m7b5") || strings.Contains(quality, "min7b5") ||
+		strings.Contains(quality, "ø")
+}
+
 // parseChordRoot extracts the root note from a chord symbol
 func parseChordRoot(chordSymbol string) int {
 	if len(chordSymbol) == 0 {
@@ -362,6 +451,18 @@ func (s *Scale) IsRoot(midiNote int) bool {
 	return midiNote%12 == s.Root
 }
 
+// degreeNames labels semitone offsets from the root as scale degrees
+var degreeNames = []string{"R", "b2", "2", "b3", "3", "4", "b5", "5", "b6", "6", "b7", "7"}
+
+// DegreeName returns the scale degree label (R, 2, b3, ...) for a MIDI note,
+// or "" if the note is not in the scale
+func (s *Scale) DegreeName(midiNote int) string {
+	if !s.ContainsNote(midiNote) {
+		return ""
+	}
+	return degreeNames[(midiNote%12-s.Root+12)%12]
+}
+
 // GetFretboardPositions returns a 2D array [string][fret] indicating scale notes
 // Returns: positions[stringIndex][fretIndex] = true if note is in scale
 // Also returns: roots[stringIndex][fretIndex] = true if note is root
@@ -401,32 +502,92 @@ func (s *Scale) GetScaleNotes(lowNote, highNote int) []int {
 	return notes
 }
 
-// GetChordTones returns the chord tones (R, 3, 5, 7) for a chord symbol
+// GetChordTones returns the chord tones (R, 3, 5, 7, then 9/11/13) for a chord symbol
 func GetChordTones(chordSymbol string) []int {
 	root := parseChordRoot(chordSymbol)
-	quality := strings.ToLower(chordSymbol)
+	quality := chordQuality(chordSymbol)
 
 	// Base triad intervals
 	var intervals []int
 
+	isMinor := strings.HasPrefix(quality, "m") && !strings.HasPrefix(quality, "maj")
+
 	switch {
-	case strings.Contains(quality, "dim"):
+	case quality == "5":
+		intervals = []int{0, 7} // Power chord: R, 5
+	case isHalfDiminished(quality):
+		intervals = []int{0, 3, 6, 10} // R, b3, b5, b7
+	case strings.Contains(quality, "dim7") || strings.HasPrefix(quality, "o7") || strings.HasPrefix(quality, "°7"):
+		intervals = []int{0, 3, 6, 9} // R, b3, b5, bb7
+	case strings.Contains(quality, "dim") || strings.HasPrefix(quality, "o") || strings.HasPrefix(quality, "°"):
 		intervals = []int{0, 3, 6} // R, b3, b5
-	case strings.Contains(quality, "aug"):
+	case strings.Contains(quality, "aug") || strings.HasPrefix(quality, "+"):
 		intervals = []int{0, 4, 8} // R, 3, #5
-	case strings.Contains(quality, "m") || strings.Contains(quality, "min"):
+	case isMinor:
 		intervals = []int{0, 3, 7} // R, b3, 5
 	default:
 		intervals = []int{0, 4, 7} // R, 3, 5 (major)
 	}
 
-	// Add 7th if present
-	if strings.Contains(quality, "maj7") {
+	// Suspended chords replace the 3rd; altered 5ths replace the 5th
+	if len(intervals) == 3 && intervals[2] == 7 {
+		switch {
+		case strings.Contains(quality, "sus2"):
+			intervals[1] = 2
+		case strings.Contains(quality, "sus"):
+			intervals[1] = 5
+		}
+		switch {
+		case strings.Contains(quality, "b5"):
+			intervals[2] = 6
+		case strings.Contains(quality, "#5"):
+			intervals[2] = 8
+		}
+	}
+
+	// Extensions: 9, 11 and 13 imply the 7th unless written as "add" or with a 6th (6/9)
+	isAdd := strings.Contains(quality, "add")
+	has6 := strings.Contains(quality, "6")
+	has13 := strings.Contains(quality, "13")
+	has11 := strings.Contains(quality, "11")
+	has9 := strings.Contains(quality, "9")
+	hasExtension := !isAdd && !has6 && (has9 || has11 || has13)
+
+	// Add 7th (or 6th) if present
+	switch {
+	case len(intervals) != 3:
+		// Power chords, half-diminished and diminished 7th are complete
+	case has6:
+		intervals = append(intervals, 9) // Major 6th
+	case strings.Contains(quality, "maj") && (strings.Contains(quality, "7") || hasExtension):
 		intervals = append(intervals, 11) // Major 7th
-	} else if strings.Contains(quality, "7") {
+	case strings.Contains(quality, "7") || hasExtension:
 		intervals = append(intervals, 10) // Minor 7th (dominant)
 	}
 
+	// Add extensions above the 7th (folded into one octave); b9/#9, #11 and b13
+	// are the altered versions
+	switch {
+	case strings.Contains(quality, "b9"):
+		intervals = append(intervals, 1)
+	case strings.Contains(quality, "#9"):
+		intervals = append(intervals, 3)
+	case has9 || (hasExtension && (has11 || has13)):
+		intervals = append(intervals, 2) // 9th (14 % 12)
+	}
+	switch {
+	case strings.Contains(quality, "#11"):
+		intervals = append(intervals, 6)
+	case has11:
+		intervals = append(intervals, 5) // 11th (17 % 12)
+	}
+	switch {
+	case strings.Contains(quality, "b13"):
+		intervals = append(intervals, 8)
+	case has13:
+		intervals = append(intervals, 9) // 13th (21 % 12)
+	}
+
 	// Convert to absolute MIDI offsets
 	tones := make([]int, len(intervals))
 	for i, interval := range intervals {
@@ -450,10 +611,8 @@ func GenerateChordVoicing(chordSymbol string, tuning Tuning) ChordVoicing {
 	}
 
 	root := chordTones[0]
+	tuning = ChordStrings(tuning)
 	numStrings := len(tuning.Notes)
-	if numStrings > 6 {
-		numStrings = 6
-	}
 
 	// Find all possible fret positions for each string
 	// stringFrets[string][chordToneIndex] = fret position (-1 if not available in range)
@@ -683,27 +842,36 @@ func GenerateMultipleVoicings(chordSymbol string, tuning Tuning, maxVoicings int
 	return voicings
 }
 
-// ScaleTypeFromString converts a string to ScaleType
+// ScaleTypeFromString converts a string to ScaleType (minor pentatonic if unknown)
 func ScaleTypeFromString(s string) ScaleType {
+	scaleType, _ := ParseScaleType(s)
+	return scaleType
+}
+
+// ParseScaleType converts a scale name ("dorian", "minor_pentatonic", ...) to its
+// ScaleType and reports whether the name is known
+func ParseScaleType(s string) (ScaleType, bool) {
 	s = strings.ToLower(strings.TrimSpace(s))
 	switch s {
 	case "pentatonic_minor", "minor_pentatonic", "pentatonic minor":
-		return ScalePentatonicMinor
+		return ScalePentatonicMinor, true
 	case "pentatonic_major", "major_pentatonic", "pentatonic major":
-		return ScalePentatonicMajor
+		return ScalePentatonicMajor, true
 	case "blues":
-		return ScaleBlues
+		return ScaleBlues, true
 	case "natural_minor", "minor", "aeolian":
-		return ScaleNaturalMinor
+		return ScaleNaturalMinor, true
 	case "natural_major", "major", "ionian":
-		return ScaleNaturalMajor
+		return ScaleNaturalMajor, true
 	case "dorian":
-		return ScaleDorian
+		return ScaleDorian, true
 	case "mixolydian":
-		return ScaleMixolydian
+		return ScaleMixolydian, true
 	case "harmonic_minor":
-		return ScaleHarmonicMinor
+		return ScaleHarmonicMinor, true
+	case "locrian":
+		return ScaleLocrian, true
 	default:
-		return ScalePentatonicMinor
+		return ScalePentatonicMinor, false
 	}
 }
@@ -0,0 +1,129 @@
+package theory
+
+import (
+	"strings"
+)
+
+// Conventional key spellings by root (Db major but C# minor, F# for both)
+var (
+	majorKeyNames = []string{"C", "Db", "D", "Eb", "E", "F", "F#", "G", "Ab", "A", "Bb", "B"}
+	minorKeyNames = []string{"C", "C#", "D", "Eb", "E", "F", "F#", "G", "G#", "A", "Bb", "B"}
+)
+
+// flatKeys are the keys whose signatures have flats
+var flatKeys = map[string]bool{
+	"F": true, "Bb": true, "Eb": true, "Ab": true, "Db": true, "Gb": true,
+	"Dm": true, "Gm": true, "Cm": true, "Fm": true, "Bbm": true, "Ebm": true,
+}
+
+// TransposeChord transposes a chord symbol's root and slash bass by the given
+// number of semitones, keeping the symbol's sharp or flat spelling
+func TransposeChord(symbol string, semitones int) string {
+	useFlats := len(symbol) >= 2 && symbol[1] == 'b'
+	return transposeChordSpelled(symbol, semitones, useFlats)
+}
+
+// TransposeChordInKey transposes a chord symbol, spelling it with flats or sharps to suit key
+func TransposeChordInKey(symbol string, semitones int, key string) string {
+	return transposeChordSpelled(symbol, semitones, KeyUsesFlats(key))
+}
+
+// TransposeKey transposes a key ("A", "F#m") and spells it the conventional way (A + 1 = Bb)
+func TransposeKey(key string, semitones int) string {
+	key = strings.TrimSpace(key)
+	if key == "" || !isNoteLetter(key[0]) {
+		return key
+	}
+	root, isMinor := ParseKey(key)
+	idx := (root + semitones%12 + 12) % 12
+	if isMinor {
+		return minorKeyNames[idx] + "m"
+	}
+	return majorKeyNames[idx]
+}
+
+// ParseKeyName checks a key name typed by a user ("g", "Gb", "f#m") and returns it
+// spelled the usual way ("G", "Gb", "F#m"), keeping the requested sharp or flat
+func ParseKeyName(key string) (string, bool) {
+	key = strings.TrimSpace(key)
+	if key == "" || !isNoteLetter(key[0]) {
+		return "", false
+	}
+	name := strings.ToUpper(key[:1])
+	rest := key[1:]
+	if len(rest) > 0 && (rest[0] == '#' || rest[0] == 'b') {
+		name += rest[:1]
+		rest = rest[1:]
+	}
+	switch rest {
+	case "":
+		return name, true
+	case "m", "min":
+		return name + "m", true
+	}
+	return "", false
+}
+
+// SemitonesToKey returns the shortest shift (-5 to +6 semitones) that moves the
+// root of key from to the root of key to
+func SemitonesToKey(from, to string) int {
+	fromRoot, _ := ParseKey(from)
+	toRoot, _ := ParseKey(to)
+	shift := (toRoot - fromRoot + 12) % 12
+	if shift > 6 {
+		shift -= 12
+	}
+	return shift
+}
+
+// KeyUsesFlats reports whether a key's signature has flats (F, Bb, Dm, Gm, ...)
+func KeyUsesFlats(key string) bool {
+	return flatKeys[strings.TrimSpace(key)]
+}
+
+// transposeChordSpelled transposes the root and any slash bass of a chord symbol
+func transposeChordSpelled(symbol string, semitones int, useFlats bool) string {
+	if symbol == "" {
+		return ""
+	}
+
+	chord, bass, hasBass := strings.Cut(symbol, "/")
+	root, quality, ok := transposeNoteName(chord, semitones, useFlats)
+	if !ok {
+		return symbol // Can't transpose, return as-is
+	}
+	result := root + quality
+
+	if hasBass {
+		if newBass, rest, ok := transposeNoteName(bass, semitones, useFlats); ok {
+			bass = newBass + rest
+		}
+		result += "/" + bass
+	}
+	return result
+}
+
+// transposeNoteName transposes the note name at the start of s, returning the new
+// name and the rest of s (false if s doesn't start with a note name)
+func transposeNoteName(s string, semitones int, useFlats bool) (string, string, bool) {
+	if s == "" || !isNoteLetter(s[0]) {
+		return "", s, false
+	}
+
+	nameLen := 1
+	if len(s) >= 2 && (s[1] == '#' || s[1] == 'b') {
+		nameLen = 2
+	}
+	name := strings.ToUpper(s[:1]) + s[1:nameLen]
+
+	idx := (NoteToMidi(name) + semitones%12 + 12) % 12
+	if useFlats {
+		return NoteNamesFlat[idx], s[nameLen:], true
+	}
+	return NoteNames[idx], s[nameLen:], true
+}
+
+// isNoteLetter reports whether c is a note letter A-G (either case)
+func isNoteLetter(c byte) bool {
+	return strings.IndexByte("ABCDEFGabcdefg", c) >= 0
+}
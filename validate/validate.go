@@ -0,0 +1,368 @@
+package validate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"backing-tracks/midi"
+	"backing-tracks/parser"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity of a diagnostic: errors make the file fail validation, warnings don't
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is one problem found in a BTML file
+type Diagnostic struct {
+	Pos      parser.Position
+	Severity Severity
+	Message  string
+}
+
+// String formats the diagnostic as "line:col: severity: message"
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.Pos, d.Severity, d.Message)
+}
+
+// HasErrors reports whether any diagnostic is an error
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// chordPattern matches a chord symbol: root, quality made of known parts, optional slash bass
+	chordPattern = regexp.MustCompile(`^[A-G][#b]?(maj|min|m|M|dim|aug|sus|add|alt|o|°|ø|\+|-|\^|\d|b|#|\(|\)|,)*(/[A-G][#b]?)?$`)
+
+	// keyPattern matches the keys theory.ParseKey understands ("A", "F#m", "Bb")
+	keyPattern = regexp.MustCompile(`^[A-G][#b]?m?$`)
+
+	// yamlLinePattern finds "line N: message" in YAML errors
+	yamlLinePattern = regexp.MustCompile(`line (\d+): (.*)`)
+)
+
+// File validates a BTML file (standard input for parser.Stdin), collecting every
+// problem found. The error is only for a file that can't be read.
+func File(filename string) ([]Diagnostic, error) {
+	var data []byte
+	var err error
+	if filename == parser.Stdin {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	track, positions, err := parser.ParseSource(data)
+	if err != nil {
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			return nil, err
+		}
+		return loadErrorDiagnostics(err, positions), nil
+	}
+
+	diags := unknownFieldDiagnostics(data)
+	diags = append(diags, Track(track, positions)...)
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].Pos.Line != diags[j].Pos.Line {
+			return diags[i].Pos.Line < diags[j].Pos.Line
+		}
+		return diags[i].Pos.Column < diags[j].Pos.Column
+	})
+	return diags, nil
+}
+
+// loadErrorDiagnostics converts a YAML or load error into diagnostics, one per reported line
+func loadErrorDiagnostics(err error, positions parser.Positions) []Diagnostic {
+	var diags []Diagnostic
+	for _, match := range yamlLinePattern.FindAllStringSubmatch(err.Error(), -1) {
+		line, _ := strconv.Atoi(match[1])
+		diags = append(diags, Diagnostic{parser.Position{Line: line}, SeverityError, match[2]})
+	}
+	if len(diags) > 0 {
+		return diags
+	}
+
+	// Not a YAML error: the custom tuning is the only other thing that fails loading
+	return []Diagnostic{{positions.At("track.custom_tuning"), SeverityError, err.Error()}}
+}
+
+// unknownFieldDiagnostics decodes strictly to find misspelled or unsupported fields
+func unknownFieldDiagnostics(data []byte) []Diagnostic {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var track parser.Track
+	err := decoder.Decode(&track)
+	if err == nil {
+		return nil
+	}
+
+	var diags []Diagnostic
+	for _, match := range yamlLinePattern.FindAllStringSubmatch(err.Error(), -1) {
+		if !strings.Contains(match[2], "not found in type") {
+			continue // Type errors were already reported by ParseFile
+		}
+		line, _ := strconv.Atoi(match[1])
+		field := strings.TrimPrefix(strings.Fields(match[2])[1], "field ")
+		diags = append(diags, Diagnostic{parser.Position{Line: line}, SeverityWarning, fmt.Sprintf("unknown field %q is ignored", field)})
+	}
+	return diags
+}
+
+// Track cross-checks a parsed track: chord symbols, key, time signature, form,
+// style names against the generators' known styles, and rhythm/drum patterns.
+// Unknown styles are warnings since the generators fall back to a default.
+func Track(track *parser.Track, positions parser.Positions) []Diagnostic {
+	v := &validator{positions: positions}
+
+	// Track info
+	if key := track.Info.Key; key == "" {
+		v.warn("track", "no key set; scales and melody default to C")
+	} else if !keyPattern.MatchString(key) {
+		v.error("track.key", "invalid key %q (use a note name with an optional m, e.g. A, F#m, Bb)", key)
+	}
+	if track.Info.Tempo <= 0 {
+		v.error("track.tempo", "tempo must be a positive number of BPM")
+	}
+	if ts := track.Info.TimeSignature; ts != "" && parser.ParseTimeSignature(ts).String() != strings.ReplaceAll(ts, " ", "") {
+		v.error("track.time_signature", "invalid time signature %q (e.g. 4/4, 3/4, 6/8); using 4/4", ts)
+	}
+
+	if changes, err := parser.ParseTempoChanges(string(track.Info.TempoChange)); err != nil {
+		v.error("track.tempo_change", "%v", err)
+	} else {
+		for _, change := range changes {
+			if change.Bar >= track.Progression.TotalBars() {
+				v.warn("track.tempo_change", "tempo change at bar %d is past the end of the song", change.Bar+1)
+			}
+		}
+	}
+	if ending := track.Info.Ending; ending != "" && !contains(midi.Endings, ending) {
+		v.warn("track.ending", "unknown ending %q (use crash, ritard or none)", ending)
+	}
+	if arrangement := track.Info.Arrangement; arrangement != "" && !contains(midi.Arrangements, arrangement) {
+		v.warn("track.arrangement", "unknown arrangement %q (use full or minimal)", arrangement)
+	}
+
+	// Chord progressions
+	if len(track.Sections) > 0 {
+		sectionNames := make(map[string]bool)
+		for i, section := range track.Sections {
+			sectionNames[section.Name] = true
+			path := fmt.Sprintf("sections[%d]", i)
+			v.checkProgression(path+".chord_progression.pattern", string(section.Progression.Pattern))
+			v.checkRhythm(path+".rhythm", section.Rhythm)
+			v.checkDrums(path+".drums", section.Drums, track.GetTimeSignature().Beats)
+			v.checkInstrument(path+".instrument", section.Instrument)
+			if section.Drums != nil && section.Drums.Kit != "" {
+				v.warn(path+".drums.kit", "a section can't change the drum kit; set kit in the track's drums")
+			}
+			if section.Tempo < 0 {
+				v.error(path+".tempo", "tempo must be a positive number of BPM")
+			}
+			if section.Dynamics != "" {
+				if _, ok := parser.ParseDynamics(section.Dynamics); !ok {
+					v.error(path+".dynamics", "invalid dynamics %q (use pp, p, mp, mf, f, ff or 0.0-1.0)", section.Dynamics)
+				}
+			}
+		}
+		if len(track.Form) == 0 {
+			v.error("sections", "sections are defined but there is no form to play them in")
+		}
+		for i, name := range track.Form {
+			if !sectionNames[name] {
+				v.error(fmt.Sprintf("form[%d]", i), "form refers to unknown section %q", name)
+			}
+		}
+	} else if strings.TrimSpace(string(track.Progression.Pattern)) == "" {
+		v.error("chord_progression", "no chord progression (set chord_progression.pattern, or sections and form)")
+	} else {
+		v.checkProgression("chord_progression.pattern", string(track.Progression.Pattern))
+	}
+
+	// Styles
+	v.checkRhythm("rhythm", track.Rhythm)
+	if track.Bass != nil && track.Bass.Style != "" && !contains(midi.BassStyles, track.Bass.Style) {
+		v.warn("bass.style", "unknown bass style %q", track.Bass.Style)
+	}
+	if track.Bass != nil && (track.Bass.Octave < -3 || track.Bass.Octave > 3) {
+		v.warn("bass.octave", "bass octave %d is outside the bass range (notes are clamped to MIDI 0-127)", track.Bass.Octave)
+	}
+	v.checkDrums("drums", track.Drums, track.GetTimeSignature().Beats)
+	if track.Bass != nil {
+		v.checkInstrument("bass.instrument", track.Bass.Instrument)
+	}
+	if track.Melody != nil {
+		v.checkInstrument("melody.instrument", track.Melody.Instrument)
+	}
+	if track.Pad != nil {
+		v.checkInstrument("pad.instrument", track.Pad.Instrument)
+	}
+	if track.Melody != nil && track.Melody.Style != "" {
+		// Unknown styles fall back to simple
+		style := strings.ToLower(strings.TrimSpace(track.Melody.Style))
+		if midi.MelodyStyleFromString(style) == midi.MelodySimple && style != string(midi.MelodySimple) {
+			v.warn("melody.style", "unknown melody style %q (plays the simple style)", track.Melody.Style)
+		}
+	}
+	if track.Melody != nil && track.Melody.Harmony != "" {
+		if harmony := strings.ToLower(strings.TrimSpace(track.Melody.Harmony)); !contains(midi.HarmonyVoices, harmony) {
+			v.warn("melody.harmony", "unknown harmony %q (use third, sixth or octave)", track.Melody.Harmony)
+		}
+	}
+
+	return v.diags
+}
+
+// validator collects diagnostics, looking up positions by field path
+type validator struct {
+	positions parser.Positions
+	diags     []Diagnostic
+}
+
+func (v *validator) error(path, format string, args ...interface{}) {
+	v.diags = append(v.diags, Diagnostic{v.positions.At(path), SeverityError, fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) warn(path, format string, args ...interface{}) {
+	v.diags = append(v.diags, Diagnostic{v.positions.At(path), SeverityWarning, fmt.Sprintf(format, args...)})
+}
+
+// checkProgression checks every chord and duration in a progression pattern
+func (v *validator) checkProgression(path, pattern string) {
+	reported := make(map[string]bool)
+	for _, part := range strings.Fields(pattern) {
+		if part == "|" || (strings.HasPrefix(part, "[") && strings.HasSuffix(part, "]")) || reported[part] {
+			continue
+		}
+
+		symbol, duration, hasDuration := strings.Cut(part, "*")
+		var problem string
+		if hasDuration {
+			if bars, err := strconv.ParseFloat(duration, 64); err != nil || bars <= 0 {
+				problem = fmt.Sprintf("invalid duration %q in %q (use a number of bars, e.g. Am*2)", duration, part)
+			}
+		}
+		if !chordPattern.MatchString(symbol) {
+			problem = fmt.Sprintf("unknown chord symbol %q", symbol)
+		}
+
+		if problem != "" {
+			reported[part] = true
+			v.diags = append(v.diags, Diagnostic{v.positions.Token(path, part), SeverityError, problem})
+		}
+	}
+}
+
+// checkRhythm checks the style names and custom pattern of a rhythm block
+func (v *validator) checkRhythm(path string, rhythm *parser.Rhythm) {
+	if rhythm == nil {
+		return
+	}
+	if rhythm.Style != "" && rhythm.Pattern == "" && !contains(midi.RhythmStyles, rhythm.Style) {
+		v.warn(path+".style", "unknown rhythm style %q (plays whole notes)", rhythm.Style)
+	}
+	if voicing := rhythm.VoicingPreference(); voicing != "" && !contains(midi.VoicingPreferences, voicing) {
+		v.warn(path+".voicing", "unknown voicing %q (use open, barre or auto)", rhythm.Voicing)
+	}
+	if mute := rhythm.MuteLevel(); mute != "" && !contains(midi.MuteLevels, mute) {
+		v.warn(path+".mute", "unknown mute level %q (use light or heavy)", rhythm.Mute)
+	}
+	v.checkInstrument(path+".instrument", rhythm.Instrument)
+	if bad := strings.Trim(rhythm.Pattern, "DUdux.- "); bad != "" {
+		v.error(path+".pattern", "invalid rhythm pattern %q (use D, U, x and .)", rhythm.Pattern)
+	}
+}
+
+// checkInstrument checks a GM instrument name or "prog:N" (unknown ones play the
+// part's default instrument)
+func (v *validator) checkInstrument(path, instrument string) {
+	if _, ok := midi.ParseProgram(instrument, midi.GMInstruments); instrument != "" && !ok {
+		v.warn(path, "unknown instrument %q (see backing-tracks instruments, or use prog:0-127); plays the default", instrument)
+	}
+}
+
+// checkDrums checks the style name and patterns of a drums block
+func (v *validator) checkDrums(path string, drums *parser.Drums, beats int) {
+	if drums == nil {
+		return
+	}
+	// The style is only used when no kick/snare/hihat patterns are given
+	explicit := drums.Kick != nil || drums.Snare != nil || drums.Hihat != nil
+	if drums.Style != "" && !explicit && !contains(midi.DrumStyles, drums.Style) {
+		v.warn(path+".style", "unknown drum style %q (plays a basic rock beat)", drums.Style)
+	}
+	if _, ok := midi.ParseProgram(drums.Kit, midi.GMDrumKits); drums.Kit != "" && !ok {
+		v.warn(path+".kit", "unknown drum kit %q (see backing-tracks instruments, or use prog:0-127); plays the standard kit", drums.Kit)
+	}
+	v.checkDrumPattern(path+".kick", drums.Kick, beats)
+	v.checkDrumPattern(path+".snare", drums.Snare, beats)
+	v.checkDrumPattern(path+".hihat", drums.Hihat, beats)
+	v.checkDrumPattern(path+".ride", drums.Ride, beats)
+	v.checkDrumPattern(path+".tom", drums.Tom, beats)
+	v.checkDrumPattern(path+".clap", drums.Clap, beats)
+	v.checkDrumPattern(path+".tambourine", drums.Tambourine, beats)
+	v.checkDrumPattern(path+".cowbell", drums.Cowbell, beats)
+	if drums.FillEvery < 0 {
+		v.error(path+".fill_every", "fill_every must be a positive number of bars (0 = no fills)")
+	}
+}
+
+// checkDrumPattern checks a custom drum voice: euclidean spec, step pattern and beats
+func (v *validator) checkDrumPattern(path string, pattern *parser.DrumPattern, beatsPerBar int) {
+	if pattern == nil {
+		return
+	}
+	if e := pattern.Euclidean; e != nil {
+		switch {
+		case e.Steps <= 0:
+			v.error(path+".euclidean", "euclidean steps must be positive")
+		case e.Hits < 0 || e.Hits > e.Steps:
+			v.error(path+".euclidean", "euclidean hits must be between 0 and steps (%d)", e.Steps)
+		case e.Rotation < 0 || e.Rotation >= e.Steps:
+			v.warn(path+".euclidean", "euclidean rotation %d is outside 0-%d", e.Rotation, e.Steps-1)
+		}
+	}
+	if bad := strings.Trim(pattern.Pattern, "Xx."); bad != "" {
+		v.error(path+".pattern", "invalid step pattern %q (use X, x and .)", pattern.Pattern)
+	}
+	for i, beat := range pattern.Beats {
+		if beat < 1 || beat > beatsPerBar {
+			v.error(fmt.Sprintf("%s.beats[%d]", path, i), "beat %d is outside the bar (1-%d)", beat, beatsPerBar)
+		}
+	}
+	if _, ok := midi.DrumSoundNote(pattern.Sound, 0); !ok {
+		v.warn(path+".sound", "unknown drum sound %q (plays the voice's own sound; use a name or GM note 27-87)", pattern.Sound)
+	}
+}
+
+// contains reports whether list holds s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}